@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_tags_guild_creator;
+DROP INDEX IF EXISTS idx_tags_guild_name;
+
+ALTER TABLE tags DROP COLUMN IF EXISTS guild_id;
@@ -0,0 +1,38 @@
+-- Scope tags to a guild. Existing tags belong to the main server, same as modlogs in 000006
+ALTER TABLE tags ADD COLUMN IF NOT EXISTS guild_id BIGINT NOT NULL DEFAULT 690950056202731521;
+ALTER TABLE tags ALTER COLUMN guild_id DROP DEFAULT;
+
+-- Tag names are case insensitive and stored lowercase
+UPDATE tags SET name = LOWER(name);
+
+-- Rename duplicate names within a guild so the unique index can be created.
+-- Suffixes already taken, like an existing foo-2, are skipped.
+DO $$
+DECLARE
+    dup RECORD;
+    n INTEGER;
+    candidate TEXT;
+BEGIN
+    FOR dup IN
+        SELECT ctid, guild_id, name
+        FROM (
+            SELECT ctid, guild_id, name, ROW_NUMBER() OVER (PARTITION BY guild_id, name ORDER BY created_at) AS rn
+            FROM tags
+        ) ranked
+        WHERE ranked.rn > 1
+    LOOP
+        n := 2;
+        LOOP
+            -- Names are at most 200 characters, so make room for the suffix
+            candidate := LEFT(dup.name, 199 - LENGTH(n::TEXT)) || '-' || n;
+            EXIT WHEN NOT EXISTS (SELECT 1 FROM tags WHERE guild_id = dup.guild_id AND name = candidate);
+            n := n + 1;
+        END LOOP;
+        UPDATE tags SET name = candidate WHERE ctid = dup.ctid;
+    END LOOP;
+END $$;
+
+UPDATE tags SET uses = 0 WHERE uses IS NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tags_guild_name ON tags(guild_id, name);
+CREATE INDEX IF NOT EXISTS idx_tags_guild_creator ON tags(guild_id, creator_id);
@@ -0,0 +1,16 @@
+-- Every /rob attempt and its outcome, also used for the per-user cooldown
+CREATE TABLE IF NOT EXISTS rob_logs (
+    id BIGSERIAL PRIMARY KEY,
+    guild_id BIGINT NOT NULL,
+    robber_id BIGINT NOT NULL,
+    victim_id BIGINT NOT NULL,
+    -- 'success', 'failed' or 'protected'
+    outcome VARCHAR(20) NOT NULL,
+    -- Coins taken from the victim on success, or paid to the victim on failure
+    amount BIGINT NOT NULL DEFAULT 0,
+    success_chance DOUBLE PRECISION NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_rob_logs_robber ON rob_logs(guild_id, robber_id, created_at DESC);
+CREATE INDEX idx_rob_logs_victim ON rob_logs(guild_id, victim_id);
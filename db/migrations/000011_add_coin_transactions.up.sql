@@ -0,0 +1,17 @@
+-- Ledger of every change to a user's coin balances
+CREATE TABLE IF NOT EXISTS coin_transactions (
+    id BIGSERIAL PRIMARY KEY,
+    guild_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    -- What caused the change, e.g. 'quiz_reward', 'give', 'deposit'
+    kind VARCHAR(30) NOT NULL,
+    -- Signed change to the in hand and safe (garrix_coins) balances
+    in_hand_change BIGINT NOT NULL DEFAULT 0,
+    safe_change BIGINT NOT NULL DEFAULT 0,
+    -- The other member involved in transfers, robberies and admin grants
+    counterparty_id BIGINT,
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_coin_transactions_user ON coin_transactions(guild_id, user_id, created_at DESC);
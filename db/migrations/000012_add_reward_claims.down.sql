@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS reward_claims;
+
+ALTER TABLE guilds DROP COLUMN IF EXISTS timezone;
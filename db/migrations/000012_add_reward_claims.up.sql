@@ -0,0 +1,13 @@
+-- Timezone used to decide when a new day starts for daily and weekly rewards
+ALTER TABLE guilds ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- Last claim and current streak of each member for /daily and /weekly
+CREATE TABLE IF NOT EXISTS reward_claims (
+    user_id BIGINT NOT NULL,
+    guild_id BIGINT NOT NULL,
+    -- 'daily' or 'weekly'
+    kind VARCHAR(20) NOT NULL,
+    streak INTEGER NOT NULL DEFAULT 0,
+    last_claimed_at TIMESTAMP,
+    PRIMARY KEY (user_id, guild_id, kind)
+);
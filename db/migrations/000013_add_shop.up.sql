@@ -0,0 +1,36 @@
+-- Items members can buy with Garrix coins through /shop
+CREATE TABLE IF NOT EXISTS shop_items (
+    id BIGSERIAL PRIMARY KEY,
+    guild_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(400) NOT NULL DEFAULT '',
+    -- 'role', 'xp_boost' or 'rank_color'
+    kind VARCHAR(20) NOT NULL,
+    price BIGINT NOT NULL CHECK (price > 0),
+    -- Role granted by 'role' items
+    role_id BIGINT,
+    -- XP multiplier and how long it lasts for 'xp_boost' items
+    boost_multiplier DOUBLE PRECISION,
+    boost_minutes INTEGER,
+    -- RGB colour used on the rank card for 'rank_color' items
+    color INTEGER,
+    -- Removed items are disabled rather than deleted so inventories keep them
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_shop_items_guild_name ON shop_items(guild_id, LOWER(name)) WHERE enabled;
+
+-- Every item a member has bought
+CREATE TABLE IF NOT EXISTS inventory (
+    id BIGSERIAL PRIMARY KEY,
+    guild_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    item_id BIGINT NOT NULL REFERENCES shop_items(id),
+    price_paid BIGINT NOT NULL,
+    purchased_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    -- When an XP boost stops applying, NULL for permanent items
+    expires_at TIMESTAMP
+);
+
+CREATE INDEX idx_inventory_user ON inventory(guild_id, user_id, purchased_at DESC);
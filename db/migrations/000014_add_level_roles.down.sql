@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS level_roles;
+
+ALTER TABLE guilds DROP COLUMN IF EXISTS stack_level_roles;
+ALTER TABLE guilds DROP COLUMN IF EXISTS level_up_message;
+ALTER TABLE guilds DROP COLUMN IF EXISTS level_up_announcements;
@@ -0,0 +1,13 @@
+-- Level-up announcements in the bot channel, the message can use {user} and {level}
+ALTER TABLE guilds ADD COLUMN IF NOT EXISTS level_up_announcements BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE guilds ADD COLUMN IF NOT EXISTS level_up_message VARCHAR(500);
+-- Whether members keep the roles of lower levels or only have the highest one
+ALTER TABLE guilds ADD COLUMN IF NOT EXISTS stack_level_roles BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Roles granted when members reach a level
+CREATE TABLE IF NOT EXISTS level_roles (
+    guild_id BIGINT NOT NULL,
+    level INTEGER NOT NULL,
+    role_id BIGINT NOT NULL,
+    PRIMARY KEY (guild_id, level)
+);
@@ -0,0 +1,23 @@
+-- XP multipliers for channels and roles on top of the guild's xp_multiplier,
+-- a multiplier of 0 means no XP is given
+CREATE TABLE IF NOT EXISTS xp_overrides (
+    guild_id BIGINT NOT NULL,
+    -- 'channel' or 'role'
+    target_type VARCHAR(10) NOT NULL,
+    target_id BIGINT NOT NULL,
+    multiplier DOUBLE PRECISION NOT NULL CHECK (multiplier >= 0),
+    PRIMARY KEY (guild_id, target_type, target_id)
+);
+
+-- Time-boxed server-wide XP multipliers, like a double XP weekend
+CREATE TABLE IF NOT EXISTS xp_events (
+    id BIGSERIAL PRIMARY KEY,
+    guild_id BIGINT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    multiplier DOUBLE PRECISION NOT NULL CHECK (multiplier > 0),
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    created_by BIGINT NOT NULL
+);
+
+CREATE INDEX idx_xp_events_guild ON xp_events(guild_id, ends_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS voice_activity;
+DROP TABLE IF EXISTS voice_sessions;
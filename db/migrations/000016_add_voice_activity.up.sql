@@ -0,0 +1,20 @@
+-- Members currently earning voice XP, kept so restarts don't lose progress
+CREATE TABLE IF NOT EXISTS voice_sessions (
+    user_id BIGINT NOT NULL,
+    guild_id BIGINT NOT NULL,
+    channel_id BIGINT NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    -- Time up to which the session has been credited with voice time and XP
+    credited_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, guild_id)
+);
+
+-- Total time each member has spent in voice
+CREATE TABLE IF NOT EXISTS voice_activity (
+    user_id BIGINT NOT NULL,
+    guild_id BIGINT NOT NULL,
+    seconds BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, guild_id)
+);
+
+CREATE INDEX idx_voice_activity_guild_seconds ON voice_activity(guild_id, seconds DESC);
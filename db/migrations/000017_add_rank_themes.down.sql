@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS user_rank_themes;
+DROP TABLE IF EXISTS rank_themes;
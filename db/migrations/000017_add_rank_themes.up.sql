@@ -0,0 +1,26 @@
+-- Rank card themes made by server admins, unlocked by reaching required_level
+CREATE TABLE IF NOT EXISTS rank_themes (
+    id BIGSERIAL PRIMARY KEY,
+    guild_id BIGINT NOT NULL,
+    name VARCHAR(50) NOT NULL,
+    background_url VARCHAR(500) NOT NULL,
+    -- Progress bar colour used unless the member picked their own
+    accent_color INTEGER,
+    required_level INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_rank_themes_guild_name ON rank_themes(guild_id, LOWER(name));
+
+-- The rank card theme each member saved with /rank theme
+CREATE TABLE IF NOT EXISTS user_rank_themes (
+    user_id BIGINT NOT NULL,
+    guild_id BIGINT NOT NULL,
+    -- Built-in template used when theme_id is NULL, empty for a random one
+    template VARCHAR(20) NOT NULL DEFAULT '',
+    theme_id BIGINT REFERENCES rank_themes(id) ON DELETE SET NULL,
+    accent_color INTEGER,
+    -- 'solid', 'gradient' or 'striped'
+    bar_style VARCHAR(20) NOT NULL DEFAULT 'solid',
+    PRIMARY KEY (user_id, guild_id)
+);
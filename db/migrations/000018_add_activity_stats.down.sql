@@ -0,0 +1,2 @@
+ALTER TABLE guilds DROP COLUMN IF EXISTS weekly_summary_at;
+DROP TABLE IF EXISTS activity_stats;
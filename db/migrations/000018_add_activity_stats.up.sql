@@ -0,0 +1,16 @@
+-- XP, messages and voice time earned by each member per hour, so leaderboards
+-- can cover a period instead of all time
+CREATE TABLE IF NOT EXISTS activity_stats (
+    user_id BIGINT NOT NULL,
+    guild_id BIGINT NOT NULL,
+    hour TIMESTAMP NOT NULL,
+    xp INTEGER NOT NULL DEFAULT 0,
+    messages INTEGER NOT NULL DEFAULT 0,
+    voice_seconds BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, guild_id, hour)
+);
+
+CREATE INDEX idx_activity_stats_guild_hour ON activity_stats(guild_id, hour);
+
+-- Start of the last week whose top chatters were posted to the bot channel
+ALTER TABLE guilds ADD COLUMN IF NOT EXISTS weekly_summary_at TIMESTAMP;
@@ -0,0 +1,14 @@
+-- Messages that were denied XP by the anti-spam heuristics, kept for
+-- moderators to review members farming XP
+CREATE TABLE IF NOT EXISTS xp_spam_flags (
+    id SERIAL PRIMARY KEY,
+    guild_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    channel_id BIGINT NOT NULL,
+    message_id BIGINT NOT NULL,
+    reason TEXT NOT NULL,
+    content VARCHAR(2050) NOT NULL,
+    flagged_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_xp_spam_flags_guild_user ON xp_spam_flags(guild_id, user_id, flagged_at DESC);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS automod_allowed_links;
+DROP TABLE IF EXISTS automod_rules;
@@ -0,0 +1,22 @@
+-- Rules the automod checks messages against
+CREATE TABLE IF NOT EXISTS automod_rules (
+    id SERIAL PRIMARY KEY,
+    guild_id BIGINT NOT NULL,
+    rule_type TEXT NOT NULL,
+    -- The banned word or regex of word and regex rules
+    pattern TEXT NOT NULL DEFAULT '',
+    -- The mention limit of mass_mentions rules and the percentage of caps rules
+    threshold INTEGER NOT NULL DEFAULT 0,
+    action TEXT NOT NULL,
+    timeout_seconds INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_automod_rules_guild ON automod_rules(guild_id);
+
+-- Domains the links rule lets through
+CREATE TABLE IF NOT EXISTS automod_allowed_links (
+    guild_id BIGINT NOT NULL,
+    domain TEXT NOT NULL,
+    PRIMARY KEY (guild_id, domain)
+);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_modlogs_active_warnings;
+DROP TABLE IF EXISTS warn_escalations;
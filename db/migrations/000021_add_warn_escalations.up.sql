@@ -0,0 +1,11 @@
+-- What happens to a member when their active warnings reach a count
+CREATE TABLE IF NOT EXISTS warn_escalations (
+    guild_id BIGINT NOT NULL,
+    warn_count INTEGER NOT NULL,
+    -- mute or tempban
+    action TEXT NOT NULL,
+    duration_seconds INTEGER NOT NULL,
+    PRIMARY KEY (guild_id, warn_count)
+);
+
+CREATE INDEX idx_modlogs_active_warnings ON modlogs(guild_id, user_id) WHERE log_type = 'warn' AND active = true;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS modlog_edits;
+DROP TABLE IF EXISTS modlog_case_counters;
+DROP INDEX IF EXISTS idx_modlogs_guild_case;
+ALTER TABLE modlogs DROP COLUMN IF EXISTS voided;
+ALTER TABLE modlogs DROP COLUMN IF EXISTS log_message_id;
+ALTER TABLE modlogs DROP COLUMN IF EXISTS log_channel_id;
+ALTER TABLE modlogs DROP COLUMN IF EXISTS case_number;
@@ -0,0 +1,44 @@
+-- Cases are numbered per guild so moderators can refer to them
+ALTER TABLE modlogs ADD COLUMN case_number INTEGER;
+
+UPDATE modlogs SET case_number = numbered.case_number
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY guild_id ORDER BY time, id) AS case_number
+    FROM modlogs
+) AS numbered
+WHERE modlogs.id = numbered.id;
+
+ALTER TABLE modlogs ALTER COLUMN case_number SET NOT NULL;
+
+CREATE UNIQUE INDEX idx_modlogs_guild_case ON modlogs(guild_id, case_number);
+
+-- Where the case was posted in the modlogs channel, so edits can update it
+ALTER TABLE modlogs ADD COLUMN log_channel_id BIGINT;
+ALTER TABLE modlogs ADD COLUMN log_message_id BIGINT;
+
+-- Voided cases were logged by mistake. They are kept for the audit trail but
+-- no longer count against the member
+ALTER TABLE modlogs ADD COLUMN voided BOOLEAN NOT NULL DEFAULT false;
+
+-- The last case number handed out in each guild
+CREATE TABLE IF NOT EXISTS modlog_case_counters (
+    guild_id BIGINT PRIMARY KEY,
+    last_case INTEGER NOT NULL
+);
+
+INSERT INTO modlog_case_counters (guild_id, last_case)
+SELECT guild_id, MAX(case_number) FROM modlogs GROUP BY guild_id;
+
+-- Changes made to cases after they were logged
+CREATE TABLE IF NOT EXISTS modlog_edits (
+    id SERIAL PRIMARY KEY,
+    modlog_id BIGINT NOT NULL REFERENCES modlogs(id) ON DELETE CASCADE,
+    editor_id BIGINT NOT NULL,
+    -- reason or void
+    edit_type TEXT NOT NULL,
+    old_reason VARCHAR(400),
+    new_reason VARCHAR(400),
+    edited_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_modlog_edits_modlog ON modlog_edits(modlog_id, edited_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS lockdown_channels;
+DROP TABLE IF EXISTS channel_locks;
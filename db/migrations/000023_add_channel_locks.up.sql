@@ -0,0 +1,26 @@
+-- Channels locked for @everyone, with the overwrite they had before so
+-- unlocking can put it back
+CREATE TABLE IF NOT EXISTS channel_locks (
+    channel_id BIGINT PRIMARY KEY,
+    guild_id BIGINT NOT NULL,
+    -- Whether @everyone had an overwrite at all, if not unlocking removes it
+    had_overwrite BOOLEAN NOT NULL,
+    previous_allow BIGINT NOT NULL DEFAULT 0,
+    previous_deny BIGINT NOT NULL DEFAULT 0,
+    locked_by BIGINT NOT NULL,
+    reason TEXT,
+    -- Locked as part of a server-wide lockdown
+    lockdown BOOLEAN NOT NULL DEFAULT false,
+    locked_at TIMESTAMP NOT NULL,
+    unlock_at TIMESTAMP
+);
+
+CREATE INDEX idx_channel_locks_guild ON channel_locks(guild_id);
+CREATE INDEX idx_channel_locks_unlock_at ON channel_locks(unlock_at) WHERE unlock_at IS NOT NULL;
+
+-- Channels a lockdown locks
+CREATE TABLE IF NOT EXISTS lockdown_channels (
+    guild_id BIGINT NOT NULL,
+    channel_id BIGINT NOT NULL,
+    PRIMARY KEY (guild_id, channel_id)
+);
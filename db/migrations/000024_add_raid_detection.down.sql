@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS raid_event_members;
+DROP TABLE IF EXISTS raid_events;
+DROP TABLE IF EXISTS raid_settings;
@@ -0,0 +1,44 @@
+-- How raids are detected from the rate of member joins
+CREATE TABLE IF NOT EXISTS raid_settings (
+    guild_id BIGINT PRIMARY KEY,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    -- A raid is this many joins within join_window_seconds
+    join_threshold INTEGER NOT NULL,
+    join_window_seconds INTEGER NOT NULL,
+    -- Only accounts younger than this count towards a raid, 0 counts every account
+    new_account_days INTEGER NOT NULL DEFAULT 0,
+    -- alert, kick or timeout: what raid mode does to new joins
+    action TEXT NOT NULL DEFAULT 'alert',
+    timeout_seconds INTEGER NOT NULL DEFAULT 0,
+    raid_mode_seconds INTEGER NOT NULL,
+    -- Where alerts go, the modlogs channel if not set
+    alert_channel_id BIGINT
+);
+
+-- Raids, detected or started by hand, and the raid mode they turned on
+CREATE TABLE IF NOT EXISTS raid_events (
+    id SERIAL PRIMARY KEY,
+    guild_id BIGINT NOT NULL,
+    -- The moderator who started raid mode, null when it was detected
+    started_by BIGINT,
+    action TEXT NOT NULL,
+    timeout_seconds INTEGER NOT NULL DEFAULT 0,
+    started_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP
+);
+
+CREATE INDEX idx_raid_events_guild ON raid_events(guild_id, started_at DESC);
+CREATE INDEX idx_raid_events_active ON raid_events(ends_at) WHERE ended_at IS NULL;
+-- A guild has at most one raid mode on at a time
+CREATE UNIQUE INDEX idx_raid_events_open ON raid_events(guild_id) WHERE ended_at IS NULL;
+
+-- Members who joined during a raid and what raid mode did to them
+CREATE TABLE IF NOT EXISTS raid_event_members (
+    raid_event_id INTEGER NOT NULL REFERENCES raid_events(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL,
+    joined_at TIMESTAMP NOT NULL,
+    -- alert, kick, timeout or failed
+    action TEXT NOT NULL,
+    PRIMARY KEY (raid_event_id, user_id)
+);
@@ -0,0 +1,40 @@
+-- name: AddActivity :exec
+INSERT INTO activity_stats (user_id, guild_id, hour, xp, messages, voice_seconds)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id, guild_id, hour) DO UPDATE
+SET xp = activity_stats.xp + EXCLUDED.xp,
+    messages = activity_stats.messages + EXCLUDED.messages,
+    voice_seconds = activity_stats.voice_seconds + EXCLUDED.voice_seconds;
+
+-- name: DeleteActivityBefore :exec
+DELETE FROM activity_stats
+WHERE hour < $1;
+
+-- name: GetActivityLeaderboard :many
+WITH activity AS (
+  SELECT user_id, SUM(CASE @metric::text WHEN 'xp' THEN xp WHEN 'messages' THEN messages ELSE voice_seconds END)::bigint AS amount
+  FROM activity_stats
+  WHERE guild_id = @guild_id AND hour >= @since AND hour < @until
+  GROUP BY user_id
+)
+SELECT activity.user_id, activity.amount, users.total_xp, COUNT(*) OVER () AS total
+FROM activity
+JOIN users ON users.id = activity.user_id AND users.guild_id = @guild_id
+WHERE activity.amount > 0
+ORDER BY activity.amount DESC, activity.user_id
+OFFSET @row_offset LIMIT 10;
+
+-- name: GetActivityLeaderboardPosition :one
+WITH activity AS (
+  SELECT user_id, SUM(CASE @metric::text WHEN 'xp' THEN xp WHEN 'messages' THEN messages ELSE voice_seconds END)::bigint AS amount
+  FROM activity_stats
+  WHERE guild_id = @guild_id AND hour >= @since AND hour < @until
+  GROUP BY user_id
+)
+SELECT position FROM (
+  SELECT activity.user_id, ROW_NUMBER() OVER (ORDER BY activity.amount DESC, activity.user_id) AS position
+  FROM activity
+  JOIN users ON users.id = activity.user_id AND users.guild_id = @guild_id
+  WHERE activity.amount > 0
+) AS ranked
+WHERE user_id = @user_id;
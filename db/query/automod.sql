@@ -0,0 +1,31 @@
+-- name: CreateAutomodRule :one
+INSERT INTO automod_rules (guild_id, rule_type, pattern, threshold, action, timeout_seconds, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: DeleteAutomodRule :execrows
+DELETE FROM automod_rules
+WHERE id = $1 AND guild_id = $2;
+
+-- name: DeleteAutomodRulesOfType :exec
+DELETE FROM automod_rules
+WHERE guild_id = $1 AND rule_type = $2;
+
+-- name: GetAutomodRules :many
+SELECT * FROM automod_rules
+WHERE guild_id = $1
+ORDER BY id;
+
+-- name: AddAutomodAllowedLink :exec
+INSERT INTO automod_allowed_links (guild_id, domain)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteAutomodAllowedLink :execrows
+DELETE FROM automod_allowed_links
+WHERE guild_id = $1 AND domain = $2;
+
+-- name: GetAutomodAllowedLinks :many
+SELECT domain FROM automod_allowed_links
+WHERE guild_id = $1
+ORDER BY domain;
@@ -0,0 +1,37 @@
+-- name: CreateChannelLock :execrows
+INSERT INTO channel_locks (channel_id, guild_id, had_overwrite, previous_allow, previous_deny, locked_by, reason, lockdown, locked_at, unlock_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (channel_id) DO NOTHING;
+
+-- name: GetChannelLock :one
+SELECT * FROM channel_locks
+WHERE channel_id = $1;
+
+-- name: GetGuildChannelLocks :many
+SELECT * FROM channel_locks
+WHERE guild_id = $1
+ORDER BY locked_at;
+
+-- name: DeleteChannelLock :exec
+DELETE FROM channel_locks
+WHERE channel_id = $1;
+
+-- name: GetExpiredChannelLocks :many
+SELECT * FROM channel_locks
+WHERE unlock_at IS NOT NULL
+  AND unlock_at <= NOW()
+ORDER BY unlock_at;
+
+-- name: AddLockdownChannel :exec
+INSERT INTO lockdown_channels (guild_id, channel_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteLockdownChannel :execrows
+DELETE FROM lockdown_channels
+WHERE guild_id = $1 AND channel_id = $2;
+
+-- name: GetLockdownChannels :many
+SELECT channel_id FROM lockdown_channels
+WHERE guild_id = $1
+ORDER BY channel_id;
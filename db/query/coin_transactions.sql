@@ -0,0 +1,23 @@
+-- name: CreateCoinTransaction :exec
+INSERT INTO coin_transactions (
+    guild_id,
+    user_id,
+    kind,
+    in_hand_change,
+    safe_change,
+    counterparty_id,
+    note,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+);
+
+-- name: GetCoinTransactionsByUser :many
+SELECT * FROM coin_transactions
+WHERE guild_id = $1 AND user_id = $2
+ORDER BY created_at DESC, id DESC
+LIMIT $3 OFFSET $4;
+
+-- name: GetCoinTransactionsByUserCount :one
+SELECT COUNT(*) FROM coin_transactions
+WHERE guild_id = $1 AND user_id = $2;
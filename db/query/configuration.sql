@@ -30,4 +30,39 @@ ON CONFLICT (guild_id) DO NOTHING
 RETURNING *;
 
 -- name: GetGuild :one
-SELECT * FROM guilds WHERE guild_id = $1;
\ No newline at end of file
+SELECT * FROM guilds WHERE guild_id = $1;
+
+-- name: GetGuildTimezone :one
+SELECT timezone FROM guilds WHERE guild_id = $1;
+
+-- name: SetGuildTimezone :exec
+UPDATE guilds
+SET timezone = $2
+WHERE guild_id = $1;
+
+-- name: GetLevelUpConfig :one
+SELECT bot_channel, level_up_announcements, level_up_message, stack_level_roles
+FROM guilds
+WHERE guild_id = $1;
+
+-- name: SetLevelUpAnnouncements :exec
+UPDATE guilds
+SET level_up_announcements = $2, level_up_message = $3
+WHERE guild_id = $1;
+
+-- name: SetStackLevelRoles :exec
+UPDATE guilds
+SET stack_level_roles = $2
+WHERE guild_id = $1;
+
+-- name: GetWeeklySummaryGuilds :many
+SELECT guild_id, bot_channel, timezone, weekly_summary_at FROM guilds
+WHERE bot_channel IS NOT NULL;
+
+-- name: SetWeeklySummaryAt :exec
+UPDATE guilds
+SET weekly_summary_at = $2
+WHERE guild_id = $1;
+
+-- name: GetModeratorRole :one
+SELECT moderator_role FROM guilds WHERE guild_id = $1;
@@ -0,0 +1,13 @@
+-- name: SetLevelRole :exec
+INSERT INTO level_roles (guild_id, level, role_id)
+VALUES ($1, $2, $3)
+ON CONFLICT (guild_id, level) DO UPDATE SET role_id = EXCLUDED.role_id;
+
+-- name: DeleteLevelRole :execrows
+DELETE FROM level_roles
+WHERE guild_id = $1 AND level = $2;
+
+-- name: GetLevelRoles :many
+SELECT * FROM level_roles
+WHERE guild_id = $1
+ORDER BY level;
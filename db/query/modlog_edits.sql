@@ -0,0 +1,9 @@
+-- name: CreateModlogEdit :exec
+INSERT INTO modlog_edits (modlog_id, editor_id, edit_type, old_reason, new_reason, edited_at)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: GetModlogEdits :many
+SELECT * FROM modlog_edits
+WHERE modlog_id = $1
+ORDER BY edited_at DESC
+LIMIT 10;
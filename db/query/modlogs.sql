@@ -1,4 +1,11 @@
 -- name: CreateModlog :one
+WITH next_case AS (
+    INSERT INTO modlog_case_counters (guild_id, last_case)
+    VALUES ($3, 1)
+    ON CONFLICT (guild_id)
+    DO UPDATE SET last_case = modlog_case_counters.last_case + 1
+    RETURNING last_case
+)
 INSERT INTO modlogs (
     user_id,
     moderator_id,
@@ -6,9 +13,10 @@ INSERT INTO modlogs (
     log_type,
     reason,
     expires_at,
-    active
+    active,
+    case_number
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
+    $1, $2, $3, $4, $5, $6, $7, (SELECT last_case FROM next_case)
 ) RETURNING *;
 
 -- name: GetModlogsByUser :many
@@ -68,3 +76,61 @@ WHERE user_id = $1
   AND active = true 
   AND expires_at > NOW()
 LIMIT 1;
+
+-- name: CountActiveWarnings :one
+SELECT COUNT(*) FROM modlogs
+WHERE user_id = $1 AND guild_id = $2 AND log_type = 'warn' AND active = true AND voided = false;
+
+-- name: GetActiveWarnings :many
+SELECT * FROM modlogs
+WHERE user_id = $1 AND guild_id = $2 AND log_type = 'warn' AND active = true AND voided = false
+ORDER BY time DESC
+LIMIT 25;
+
+-- name: ClearWarning :execrows
+UPDATE modlogs
+SET active = false
+WHERE guild_id = $1 AND user_id = $2 AND case_number = $3 AND log_type = 'warn' AND active = true AND voided = false;
+
+-- name: ClearWarnings :execrows
+UPDATE modlogs
+SET active = false
+WHERE user_id = $1 AND guild_id = $2 AND log_type = 'warn' AND active = true AND voided = false;
+
+-- name: GetGuildsWithExpiredActions :many
+SELECT DISTINCT guild_id FROM modlogs
+WHERE active = true
+  AND expires_at IS NOT NULL
+  AND expires_at <= NOW();
+
+-- name: GetModlogByCase :one
+SELECT * FROM modlogs
+WHERE guild_id = $1 AND case_number = $2;
+
+-- name: GetModlogByCaseForUpdate :one
+SELECT * FROM modlogs
+WHERE guild_id = $1 AND case_number = $2
+FOR UPDATE;
+
+-- name: UpdateModlogReason :exec
+UPDATE modlogs
+SET reason = $2
+WHERE id = $1;
+
+-- name: VoidModlog :exec
+UPDATE modlogs
+SET voided = true
+WHERE id = $1;
+
+-- name: SetModlogMessage :exec
+UPDATE modlogs
+SET log_channel_id = $2, log_message_id = $3
+WHERE id = $1;
+
+-- name: DeactivateTempBans :exec
+UPDATE modlogs
+SET active = false
+WHERE guild_id = @guild_id
+  AND user_id = ANY(@user_ids::bigint[])
+  AND log_type = 'tempban'
+  AND active = true;
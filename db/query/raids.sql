@@ -0,0 +1,72 @@
+-- name: GetRaidSettings :one
+SELECT * FROM raid_settings
+WHERE guild_id = $1;
+
+-- name: SetRaidSettings :exec
+INSERT INTO raid_settings (guild_id, enabled, join_threshold, join_window_seconds, new_account_days, action, timeout_seconds, raid_mode_seconds, alert_channel_id)
+VALUES ($1, true, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT (guild_id)
+DO UPDATE SET
+    enabled = true,
+    join_threshold = EXCLUDED.join_threshold,
+    join_window_seconds = EXCLUDED.join_window_seconds,
+    new_account_days = EXCLUDED.new_account_days,
+    action = EXCLUDED.action,
+    timeout_seconds = EXCLUDED.timeout_seconds,
+    raid_mode_seconds = EXCLUDED.raid_mode_seconds,
+    alert_channel_id = EXCLUDED.alert_channel_id;
+
+-- name: DisableRaidDetection :execrows
+UPDATE raid_settings
+SET enabled = false
+WHERE guild_id = $1 AND enabled = true;
+
+-- name: CreateRaidEvent :one
+INSERT INTO raid_events (guild_id, started_by, action, timeout_seconds, started_at, ends_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetActiveRaidEvent :one
+SELECT * FROM raid_events
+WHERE guild_id = $1
+  AND ended_at IS NULL
+  AND ends_at > NOW()
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: GetOpenRaidEvent :one
+SELECT * FROM raid_events
+WHERE guild_id = $1
+  AND ended_at IS NULL;
+
+-- name: GetExpiredRaidEvents :many
+SELECT * FROM raid_events
+WHERE ended_at IS NULL
+  AND ends_at <= NOW();
+
+-- name: EndRaidEvent :execrows
+UPDATE raid_events
+SET ended_at = $2
+WHERE id = $1 AND ended_at IS NULL;
+
+-- name: AddRaidEventMember :exec
+INSERT INTO raid_event_members (raid_event_id, user_id, joined_at, action)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING;
+
+-- name: CountRaidEventMembers :one
+SELECT COUNT(*) AS joins,
+    COUNT(*) FILTER (WHERE action IN ('kick', 'timeout')) AS actioned
+FROM raid_event_members
+WHERE raid_event_id = $1;
+
+-- name: GetRecentRaidEvents :many
+SELECT raid_events.id, raid_events.guild_id, raid_events.started_by, raid_events.action, raid_events.timeout_seconds, raid_events.started_at, raid_events.ends_at, raid_events.ended_at,
+    COUNT(raid_event_members.user_id) AS joins,
+    COUNT(raid_event_members.user_id) FILTER (WHERE raid_event_members.action IN ('kick', 'timeout')) AS actioned
+FROM raid_events
+LEFT JOIN raid_event_members ON raid_event_members.raid_event_id = raid_events.id
+WHERE raid_events.guild_id = $1
+GROUP BY raid_events.id
+ORDER BY raid_events.started_at DESC
+LIMIT 10;
@@ -0,0 +1,41 @@
+-- name: CreateRankTheme :one
+INSERT INTO rank_themes (guild_id, name, background_url, accent_color, required_level, created_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: DeleteRankTheme :execrows
+DELETE FROM rank_themes
+WHERE guild_id = $1 AND LOWER(name) = LOWER(@name);
+
+-- name: GetRankThemes :many
+SELECT * FROM rank_themes
+WHERE guild_id = $1
+ORDER BY required_level, name;
+
+-- name: GetRankTheme :one
+SELECT * FROM rank_themes
+WHERE guild_id = $1 AND id = $2;
+
+-- name: GetRankThemeByName :one
+SELECT * FROM rank_themes
+WHERE guild_id = $1 AND LOWER(name) = LOWER(@name);
+
+-- name: GetRankThemeNamesLike :many
+SELECT name FROM rank_themes
+WHERE guild_id = $1 AND name ILIKE $2
+ORDER BY required_level, name
+LIMIT 25;
+
+-- name: SetUserRankTheme :exec
+INSERT INTO user_rank_themes (user_id, guild_id, template, theme_id, accent_color, bar_style)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (user_id, guild_id) DO UPDATE
+SET template = EXCLUDED.template, theme_id = EXCLUDED.theme_id, accent_color = EXCLUDED.accent_color, bar_style = EXCLUDED.bar_style;
+
+-- name: GetUserRankTheme :one
+SELECT * FROM user_rank_themes
+WHERE user_id = $1 AND guild_id = $2;
+
+-- name: DeleteUserRankTheme :exec
+DELETE FROM user_rank_themes
+WHERE user_id = $1 AND guild_id = $2;
@@ -0,0 +1,14 @@
+-- name: EnsureRewardClaim :exec
+INSERT INTO reward_claims (user_id, guild_id, kind)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, guild_id, kind) DO NOTHING;
+
+-- name: GetRewardClaimForUpdate :one
+SELECT * FROM reward_claims
+WHERE user_id = $1 AND guild_id = $2 AND kind = $3
+FOR UPDATE;
+
+-- name: UpdateRewardClaim :exec
+UPDATE reward_claims
+SET streak = $4, last_claimed_at = $5
+WHERE user_id = $1 AND guild_id = $2 AND kind = $3;
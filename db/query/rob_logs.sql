@@ -0,0 +1,18 @@
+-- name: CreateRobLog :one
+INSERT INTO rob_logs (
+    guild_id,
+    robber_id,
+    victim_id,
+    outcome,
+    amount,
+    success_chance,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetLastRobAttempt :one
+SELECT created_at FROM rob_logs
+WHERE guild_id = $1 AND robber_id = $2 AND outcome <> 'protected'
+ORDER BY created_at DESC
+LIMIT 1;
@@ -0,0 +1,61 @@
+-- name: CreateShopItem :one
+INSERT INTO shop_items (guild_id, name, description, kind, price, role_id, boost_multiplier, boost_minutes, color, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetShopItemByName :one
+SELECT * FROM shop_items
+WHERE guild_id = $1 AND LOWER(name) = LOWER(@name) AND enabled;
+
+-- name: GetShopItems :many
+SELECT * FROM shop_items
+WHERE guild_id = $1 AND enabled
+ORDER BY price, name;
+
+-- name: GetShopItemNamesLike :many
+SELECT name FROM shop_items
+WHERE guild_id = $1 AND enabled AND name ILIKE $2
+ORDER BY price, name
+LIMIT 25;
+
+-- name: DisableShopItem :execrows
+UPDATE shop_items
+SET enabled = FALSE
+WHERE guild_id = $1 AND LOWER(name) = LOWER(@name) AND enabled;
+
+-- name: CreateInventoryItem :one
+INSERT INTO inventory (guild_id, user_id, item_id, price_paid, purchased_at, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING id;
+
+-- name: DeleteInventoryItem :exec
+DELETE FROM inventory WHERE id = $1;
+
+-- name: HasInventoryItem :one
+SELECT EXISTS (
+  SELECT 1 FROM inventory
+  WHERE guild_id = $1 AND user_id = $2 AND item_id = $3
+);
+
+-- name: GetInventory :many
+SELECT i.id, s.name, s.kind, i.price_paid, i.purchased_at, i.expires_at
+FROM inventory i
+JOIN shop_items s ON s.id = i.item_id
+WHERE i.guild_id = $1 AND i.user_id = $2
+ORDER BY i.purchased_at DESC;
+
+-- name: GetActiveXpBoost :one
+SELECT s.boost_multiplier, i.expires_at
+FROM inventory i
+JOIN shop_items s ON s.id = i.item_id
+WHERE i.guild_id = $1 AND i.user_id = $2 AND s.kind = 'xp_boost' AND i.expires_at > $3
+ORDER BY s.boost_multiplier DESC
+LIMIT 1;
+
+-- name: GetRankColor :one
+SELECT s.color
+FROM inventory i
+JOIN shop_items s ON s.id = i.item_id
+WHERE i.guild_id = $1 AND i.user_id = $2 AND s.kind = 'rank_color'
+ORDER BY i.purchased_at DESC
+LIMIT 1;
@@ -0,0 +1,48 @@
+-- name: CreateTag :one
+INSERT INTO tags (
+    guild_id,
+    name,
+    content,
+    creator_id
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetTag :one
+SELECT * FROM tags WHERE guild_id = $1 AND name = $2;
+
+-- name: UseTag :one
+UPDATE tags SET uses = COALESCE(uses, 0) + 1
+WHERE guild_id = $1 AND name = $2
+RETURNING *;
+
+-- name: UpdateTagContent :exec
+UPDATE tags SET content = $3 WHERE guild_id = $1 AND name = $2;
+
+-- name: TransferTag :exec
+UPDATE tags SET creator_id = $3 WHERE guild_id = $1 AND name = $2;
+
+-- name: DeleteTag :exec
+DELETE FROM tags WHERE guild_id = $1 AND name = $2;
+
+-- name: GetTagsByGuild :many
+SELECT * FROM tags
+WHERE guild_id = $1
+ORDER BY uses DESC NULLS LAST, name;
+
+-- name: GetTagsByCreator :many
+SELECT * FROM tags
+WHERE guild_id = $1 AND creator_id = $2
+ORDER BY uses DESC NULLS LAST, name;
+
+-- name: GetTagNamesLike :many
+SELECT name FROM tags
+WHERE guild_id = $1 AND name LIKE $2
+ORDER BY uses DESC NULLS LAST, name
+LIMIT 25;
+
+-- name: SearchTags :many
+SELECT * FROM tags
+WHERE guild_id = $1 AND (name LIKE $2 OR LOWER(content) LIKE $2)
+ORDER BY uses DESC NULLS LAST, name
+LIMIT $3;
@@ -1,25 +1,19 @@
 -- name: AddCoins :exec
-UPDATE users SET in_hand=in_hand + $3 WHERE id = $1 AND guild_id = $2;
+UPDATE users SET in_hand = COALESCE(in_hand, 0) + $3 WHERE id = $1 AND guild_id = $2;
 
 -- name: GetBalance :one
 SELECT garrix_coins, in_hand FROM users WHERE id = $1 AND guild_id = $2;
 
+-- name: GetBalanceForUpdate :one
+SELECT garrix_coins, in_hand FROM users WHERE id = $1 AND guild_id = $2 FOR UPDATE;
+
 -- name: WithdrawAmount :exec
 UPDATE users SET in_hand = in_hand + $3, garrix_coins = garrix_coins - $3 WHERE id = $1 AND guild_id = $2;
 
 -- name: DepositAmount :exec
 UPDATE users SET in_hand = in_hand - $3, garrix_coins = garrix_coins + $3 WHERE id = $1 AND guild_id = $2;
 
--- name: GiveCoins :exec
-WITH sender_update AS (
-    UPDATE users AS sender
-    SET in_hand = sender.in_hand - $4
-    WHERE sender.id = $1 AND sender.in_hand >= $3 AND sender.guild_id = $3
-    RETURNING sender.id
-)
-UPDATE users AS receiver
-SET in_hand = receiver.in_hand + $4
-WHERE receiver.id = $2 AND receiver.guild_id = $3
-AND EXISTS (SELECT 1 FROM sender_update);
+-- name: SpendCoins :exec
+UPDATE users SET in_hand = in_hand - $3, garrix_coins = garrix_coins - $4 WHERE id = $1 AND guild_id = $2;
 
--- TODO: Use sqlc.arg for argument names
\ No newline at end of file
+-- TODO: Use sqlc.arg for argument names
@@ -6,25 +6,35 @@ INSERT INTO users(id, guild_id)
 VALUES ($1, $2)
 RETURNING *;
 
+-- name: EnsureUser :exec
+INSERT INTO users(id, guild_id)
+VALUES ($1, $2)
+ON CONFLICT (id, guild_id) DO NOTHING;
+
+-- name: AddXp :one
+UPDATE users SET total_xp = COALESCE(total_xp, 0) + $3
+WHERE id = $1 AND guild_id = $2
+RETURNING total_xp;
+
 -- name: GetCoinsLeaderboard :many
-SELECT id, garrix_coins, in_hand FROM users
+SELECT id, garrix_coins, in_hand, total_xp, COUNT(*) OVER () AS total FROM users
 WHERE guild_id = $1
-ORDER BY garrix_coins + in_hand DESC OFFSET $2 LIMIT 10;
+ORDER BY garrix_coins + in_hand DESC, id OFFSET $2 LIMIT 10;
 
 -- name: GetLevelsLeaderboard :many
-SELECT id, total_xp FROM users
+SELECT id, total_xp, COUNT(*) OVER () AS total FROM users
 WHERE guild_id = $1
-ORDER BY total_xp DESC OFFSET $2 LIMIT 10;
+ORDER BY total_xp DESC, id OFFSET $2 LIMIT 10;
 
 -- name: GetMessagesSentLeaderboard :many
-SELECT id, messages_sent FROM users
+SELECT id, messages_sent, total_xp, COUNT(*) OVER () AS total FROM users
 WHERE guild_id = $1
-ORDER BY messages_sent DESC OFFSET $2 LIMIT 10;
+ORDER BY messages_sent DESC, id OFFSET $2 LIMIT 10;
 
 -- name: GetInHandLeaderboard :many
-SELECT id, in_hand FROM users
+SELECT id, in_hand, total_xp, COUNT(*) OVER () AS total FROM users
 WHERE guild_id = $1
-ORDER BY in_hand DESC OFFSET $2 LIMIT 10;
+ORDER BY in_hand DESC, id OFFSET $2 LIMIT 10;
 
 
 -- name: GetUserLevelData :one
@@ -36,4 +46,65 @@ WITH user_ranks AS (
 )
 SELECT *
 FROM user_ranks
-WHERE id = $1 AND guild_id = $2;
\ No newline at end of file
+WHERE id = $1 AND guild_id = $2;
+
+-- name: GetCoinsLeaderboardPosition :one
+SELECT position FROM (
+  SELECT id, ROW_NUMBER() OVER (ORDER BY garrix_coins + in_hand DESC, id) AS position
+  FROM users
+  WHERE guild_id = $1
+) AS ranked
+WHERE id = $2;
+
+-- name: GetInHandLeaderboardPosition :one
+SELECT position FROM (
+  SELECT id, ROW_NUMBER() OVER (ORDER BY in_hand DESC, id) AS position
+  FROM users
+  WHERE guild_id = $1
+) AS ranked
+WHERE id = $2;
+
+-- name: GetLevelsLeaderboardPosition :one
+SELECT position FROM (
+  SELECT id, ROW_NUMBER() OVER (ORDER BY total_xp DESC, id) AS position
+  FROM users
+  WHERE guild_id = $1
+) AS ranked
+WHERE id = $2;
+
+-- name: GetMessagesSentLeaderboardPosition :one
+SELECT position FROM (
+  SELECT id, ROW_NUMBER() OVER (ORDER BY messages_sent DESC, id) AS position
+  FROM users
+  WHERE guild_id = $1
+) AS ranked
+WHERE id = $2;
+
+-- name: GetUserForUpdate :one
+SELECT * FROM users WHERE id = $1 AND guild_id = $2 FOR UPDATE;
+
+-- name: SetXp :exec
+UPDATE users SET total_xp = $3 WHERE id = $1 AND guild_id = $2;
+
+-- name: ResetUser :exec
+UPDATE users SET messages_sent = 0, total_xp = 0, garrix_coins = 0, in_hand = 0
+WHERE id = $1 AND guild_id = $2;
+
+-- name: GetUserLevels :many
+SELECT id, total_xp, messages_sent FROM users
+WHERE guild_id = @guild_id AND id = ANY(@user_ids::bigint[]);
+
+-- name: ImportUserLevels :exec
+INSERT INTO users (id, guild_id, total_xp, messages_sent)
+SELECT unnest(@user_ids::bigint[]), @guild_id::bigint, unnest(@total_xps::int[]), unnest(@messages_sent::int[])
+ON CONFLICT (id, guild_id) DO UPDATE
+SET total_xp = CASE @strategy::text
+        WHEN 'overwrite' THEN EXCLUDED.total_xp
+        WHEN 'add' THEN LEAST(COALESCE(users.total_xp, 0)::bigint + EXCLUDED.total_xp, 2147483647)::int
+        ELSE GREATEST(users.total_xp, EXCLUDED.total_xp)
+    END,
+    messages_sent = CASE @strategy::text
+        WHEN 'overwrite' THEN EXCLUDED.messages_sent
+        WHEN 'add' THEN LEAST(COALESCE(users.messages_sent, 0)::bigint + EXCLUDED.messages_sent, 2147483647)::int
+        ELSE GREATEST(users.messages_sent, EXCLUDED.messages_sent)
+    END;
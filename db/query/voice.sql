@@ -0,0 +1,41 @@
+-- name: UpsertVoiceSession :exec
+INSERT INTO voice_sessions (user_id, guild_id, channel_id, started_at, credited_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id, guild_id) DO UPDATE
+SET channel_id = EXCLUDED.channel_id, started_at = EXCLUDED.started_at, credited_at = EXCLUDED.credited_at;
+
+-- name: GetVoiceSession :one
+SELECT * FROM voice_sessions
+WHERE user_id = $1 AND guild_id = $2;
+
+-- name: GetVoiceSessions :many
+SELECT * FROM voice_sessions;
+
+-- name: SetVoiceSessionCreditedAt :exec
+UPDATE voice_sessions
+SET credited_at = $3
+WHERE user_id = $1 AND guild_id = $2;
+
+-- name: DeleteVoiceSession :exec
+DELETE FROM voice_sessions
+WHERE user_id = $1 AND guild_id = $2;
+
+-- name: AddVoiceTime :exec
+INSERT INTO voice_activity (user_id, guild_id, seconds)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, guild_id) DO UPDATE SET seconds = voice_activity.seconds + EXCLUDED.seconds;
+
+-- name: GetVoiceTimeLeaderboard :many
+SELECT voice_activity.user_id, voice_activity.seconds, users.total_xp, COUNT(*) OVER () AS total FROM voice_activity
+JOIN users ON users.id = voice_activity.user_id AND users.guild_id = voice_activity.guild_id
+WHERE voice_activity.guild_id = $1
+ORDER BY voice_activity.seconds DESC, voice_activity.user_id OFFSET $2 LIMIT 10;
+
+-- name: GetVoiceTimeLeaderboardPosition :one
+SELECT position FROM (
+  SELECT voice_activity.user_id, ROW_NUMBER() OVER (ORDER BY voice_activity.seconds DESC, voice_activity.user_id) AS position
+  FROM voice_activity
+  JOIN users ON users.id = voice_activity.user_id AND users.guild_id = voice_activity.guild_id
+  WHERE voice_activity.guild_id = $1
+) AS ranked
+WHERE user_id = $2;
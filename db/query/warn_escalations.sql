@@ -0,0 +1,18 @@
+-- name: SetWarnEscalation :exec
+INSERT INTO warn_escalations (guild_id, warn_count, action, duration_seconds)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (guild_id, warn_count)
+DO UPDATE SET action = EXCLUDED.action, duration_seconds = EXCLUDED.duration_seconds;
+
+-- name: DeleteWarnEscalation :execrows
+DELETE FROM warn_escalations
+WHERE guild_id = $1 AND warn_count = $2;
+
+-- name: GetWarnEscalations :many
+SELECT * FROM warn_escalations
+WHERE guild_id = $1
+ORDER BY warn_count;
+
+-- name: GetWarnEscalation :one
+SELECT * FROM warn_escalations
+WHERE guild_id = $1 AND warn_count = $2;
@@ -0,0 +1,39 @@
+-- name: GetXpMultiplier :one
+SELECT xp_multiplier FROM guilds WHERE guild_id = $1;
+
+-- name: SetXpMultiplier :exec
+UPDATE guilds
+SET xp_multiplier = $2
+WHERE guild_id = $1;
+
+-- name: SetXpOverride :exec
+INSERT INTO xp_overrides (guild_id, target_type, target_id, multiplier)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (guild_id, target_type, target_id) DO UPDATE SET multiplier = EXCLUDED.multiplier;
+
+-- name: DeleteXpOverride :execrows
+DELETE FROM xp_overrides
+WHERE guild_id = $1 AND target_type = $2 AND target_id = $3;
+
+-- name: GetXpOverrides :many
+SELECT * FROM xp_overrides
+WHERE guild_id = $1
+ORDER BY target_type, multiplier DESC;
+
+-- name: CreateXpEvent :one
+INSERT INTO xp_events (guild_id, name, multiplier, starts_at, ends_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetXpEvents :many
+SELECT * FROM xp_events
+WHERE guild_id = $1 AND ends_at > $2
+ORDER BY starts_at;
+
+-- name: GetActiveXpEventMultiplier :one
+SELECT COALESCE(MAX(multiplier), 1)::DOUBLE PRECISION AS multiplier FROM xp_events
+WHERE guild_id = $1 AND starts_at <= $2 AND ends_at > $2;
+
+-- name: DeleteXpEvent :execrows
+DELETE FROM xp_events
+WHERE guild_id = $1 AND id = $2;
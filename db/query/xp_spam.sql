@@ -0,0 +1,25 @@
+-- name: CreateXpSpamFlag :exec
+INSERT INTO xp_spam_flags (guild_id, user_id, channel_id, message_id, reason, content, flagged_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: DeleteXpSpamFlags :execrows
+DELETE FROM xp_spam_flags
+WHERE guild_id = $1 AND user_id = $2;
+
+-- name: DeleteXpSpamFlagsBefore :exec
+DELETE FROM xp_spam_flags
+WHERE flagged_at < $1;
+
+-- name: GetXpSpamFlaggedUsers :many
+SELECT user_id, COUNT(*) AS flags, MAX(flagged_at)::timestamp AS last_flagged_at
+FROM xp_spam_flags
+WHERE guild_id = $1
+GROUP BY user_id
+ORDER BY flags DESC, last_flagged_at DESC
+LIMIT 15;
+
+-- name: GetXpSpamFlags :many
+SELECT * FROM xp_spam_flags
+WHERE guild_id = $1 AND user_id = $2
+ORDER BY flagged_at DESC
+LIMIT 10;
@@ -15,7 +15,7 @@ const createGuild = `-- name: CreateGuild :one
 INSERT INTO guilds(guild_id)
 VALUES ($1)
 ON CONFLICT (guild_id) DO NOTHING
-RETURNING guild_id, modlogs_channel, leave_join_logs_channel, youtube_notifications_channel, youtube_notifications_role, reddit_notifications_channel, reddit_notifications_role, stmpd_notifications_channel, stmpd_notifications_role, welcomes_channel, delete_logs_channel, edit_logs_channel, bot_channel, radio_voice_channel, news_role, xp_multiplier, tour_notifications_channel, tour_notifications_role, moderator_role
+RETURNING guild_id, modlogs_channel, leave_join_logs_channel, youtube_notifications_channel, youtube_notifications_role, reddit_notifications_channel, reddit_notifications_role, stmpd_notifications_channel, stmpd_notifications_role, welcomes_channel, delete_logs_channel, edit_logs_channel, bot_channel, radio_voice_channel, news_role, xp_multiplier, tour_notifications_channel, tour_notifications_role, moderator_role, timezone, level_up_announcements, level_up_message, stack_level_roles, weekly_summary_at
 `
 
 func (q *Queries) CreateGuild(ctx context.Context, guildID int64) (Guild, error) {
@@ -41,12 +41,17 @@ func (q *Queries) CreateGuild(ctx context.Context, guildID int64) (Guild, error)
 		&i.TourNotificationsChannel,
 		&i.TourNotificationsRole,
 		&i.ModeratorRole,
+		&i.Timezone,
+		&i.LevelUpAnnouncements,
+		&i.LevelUpMessage,
+		&i.StackLevelRoles,
+		&i.WeeklySummaryAt,
 	)
 	return i, err
 }
 
 const getGuild = `-- name: GetGuild :one
-SELECT guild_id, modlogs_channel, leave_join_logs_channel, youtube_notifications_channel, youtube_notifications_role, reddit_notifications_channel, reddit_notifications_role, stmpd_notifications_channel, stmpd_notifications_role, welcomes_channel, delete_logs_channel, edit_logs_channel, bot_channel, radio_voice_channel, news_role, xp_multiplier, tour_notifications_channel, tour_notifications_role, moderator_role FROM guilds WHERE guild_id = $1
+SELECT guild_id, modlogs_channel, leave_join_logs_channel, youtube_notifications_channel, youtube_notifications_role, reddit_notifications_channel, reddit_notifications_role, stmpd_notifications_channel, stmpd_notifications_role, welcomes_channel, delete_logs_channel, edit_logs_channel, bot_channel, radio_voice_channel, news_role, xp_multiplier, tour_notifications_channel, tour_notifications_role, moderator_role, timezone, level_up_announcements, level_up_message, stack_level_roles, weekly_summary_at FROM guilds WHERE guild_id = $1
 `
 
 func (q *Queries) GetGuild(ctx context.Context, guildID int64) (Guild, error) {
@@ -72,10 +77,62 @@ func (q *Queries) GetGuild(ctx context.Context, guildID int64) (Guild, error) {
 		&i.TourNotificationsChannel,
 		&i.TourNotificationsRole,
 		&i.ModeratorRole,
+		&i.Timezone,
+		&i.LevelUpAnnouncements,
+		&i.LevelUpMessage,
+		&i.StackLevelRoles,
+		&i.WeeklySummaryAt,
 	)
 	return i, err
 }
 
+const getGuildTimezone = `-- name: GetGuildTimezone :one
+SELECT timezone FROM guilds WHERE guild_id = $1
+`
+
+func (q *Queries) GetGuildTimezone(ctx context.Context, guildID int64) (string, error) {
+	row := q.db.QueryRow(ctx, getGuildTimezone, guildID)
+	var timezone string
+	err := row.Scan(&timezone)
+	return timezone, err
+}
+
+const getLevelUpConfig = `-- name: GetLevelUpConfig :one
+SELECT bot_channel, level_up_announcements, level_up_message, stack_level_roles
+FROM guilds
+WHERE guild_id = $1
+`
+
+type GetLevelUpConfigRow struct {
+	BotChannel           pgtype.Int8 `json:"botChannel"`
+	LevelUpAnnouncements bool        `json:"levelUpAnnouncements"`
+	LevelUpMessage       pgtype.Text `json:"levelUpMessage"`
+	StackLevelRoles      bool        `json:"stackLevelRoles"`
+}
+
+func (q *Queries) GetLevelUpConfig(ctx context.Context, guildID int64) (GetLevelUpConfigRow, error) {
+	row := q.db.QueryRow(ctx, getLevelUpConfig, guildID)
+	var i GetLevelUpConfigRow
+	err := row.Scan(
+		&i.BotChannel,
+		&i.LevelUpAnnouncements,
+		&i.LevelUpMessage,
+		&i.StackLevelRoles,
+	)
+	return i, err
+}
+
+const getModeratorRole = `-- name: GetModeratorRole :one
+SELECT moderator_role FROM guilds WHERE guild_id = $1
+`
+
+func (q *Queries) GetModeratorRole(ctx context.Context, guildID int64) (pgtype.Int8, error) {
+	row := q.db.QueryRow(ctx, getModeratorRole, guildID)
+	var moderator_role pgtype.Int8
+	err := row.Scan(&moderator_role)
+	return moderator_role, err
+}
+
 const getRadioVoiceChannels = `-- name: GetRadioVoiceChannels :many
 SELECT guild_id, radio_voice_channel
 FROM guilds
@@ -169,6 +226,43 @@ func (q *Queries) GetSTMPDNofiticationChannels(ctx context.Context) ([]GetSTMPDN
 	return items, nil
 }
 
+const getWeeklySummaryGuilds = `-- name: GetWeeklySummaryGuilds :many
+SELECT guild_id, bot_channel, timezone, weekly_summary_at FROM guilds
+WHERE bot_channel IS NOT NULL
+`
+
+type GetWeeklySummaryGuildsRow struct {
+	GuildID         int64            `json:"guildId"`
+	BotChannel      pgtype.Int8      `json:"botChannel"`
+	Timezone        string           `json:"timezone"`
+	WeeklySummaryAt pgtype.Timestamp `json:"weeklySummaryAt"`
+}
+
+func (q *Queries) GetWeeklySummaryGuilds(ctx context.Context) ([]GetWeeklySummaryGuildsRow, error) {
+	rows, err := q.db.Query(ctx, getWeeklySummaryGuilds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetWeeklySummaryGuildsRow
+	for rows.Next() {
+		var i GetWeeklySummaryGuildsRow
+		if err := rows.Scan(
+			&i.GuildID,
+			&i.BotChannel,
+			&i.Timezone,
+			&i.WeeklySummaryAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getYoutubeNotifactionChannels = `-- name: GetYoutubeNotifactionChannels :many
 SELECT youtube_notifications_channel, youtube_notifications_role 
 FROM guilds
@@ -200,6 +294,39 @@ func (q *Queries) GetYoutubeNotifactionChannels(ctx context.Context) ([]GetYoutu
 	return items, nil
 }
 
+const setGuildTimezone = `-- name: SetGuildTimezone :exec
+UPDATE guilds
+SET timezone = $2
+WHERE guild_id = $1
+`
+
+type SetGuildTimezoneParams struct {
+	GuildID  int64  `json:"guildId"`
+	Timezone string `json:"timezone"`
+}
+
+func (q *Queries) SetGuildTimezone(ctx context.Context, arg SetGuildTimezoneParams) error {
+	_, err := q.db.Exec(ctx, setGuildTimezone, arg.GuildID, arg.Timezone)
+	return err
+}
+
+const setLevelUpAnnouncements = `-- name: SetLevelUpAnnouncements :exec
+UPDATE guilds
+SET level_up_announcements = $2, level_up_message = $3
+WHERE guild_id = $1
+`
+
+type SetLevelUpAnnouncementsParams struct {
+	GuildID              int64       `json:"guildId"`
+	LevelUpAnnouncements bool        `json:"levelUpAnnouncements"`
+	LevelUpMessage       pgtype.Text `json:"levelUpMessage"`
+}
+
+func (q *Queries) SetLevelUpAnnouncements(ctx context.Context, arg SetLevelUpAnnouncementsParams) error {
+	_, err := q.db.Exec(ctx, setLevelUpAnnouncements, arg.GuildID, arg.LevelUpAnnouncements, arg.LevelUpMessage)
+	return err
+}
+
 const setModeratorRole = `-- name: SetModeratorRole :exec
 UPDATE guilds
 SET moderator_role = $2
@@ -215,3 +342,35 @@ func (q *Queries) SetModeratorRole(ctx context.Context, arg SetModeratorRolePara
 	_, err := q.db.Exec(ctx, setModeratorRole, arg.GuildID, arg.ModeratorRole)
 	return err
 }
+
+const setStackLevelRoles = `-- name: SetStackLevelRoles :exec
+UPDATE guilds
+SET stack_level_roles = $2
+WHERE guild_id = $1
+`
+
+type SetStackLevelRolesParams struct {
+	GuildID         int64 `json:"guildId"`
+	StackLevelRoles bool  `json:"stackLevelRoles"`
+}
+
+func (q *Queries) SetStackLevelRoles(ctx context.Context, arg SetStackLevelRolesParams) error {
+	_, err := q.db.Exec(ctx, setStackLevelRoles, arg.GuildID, arg.StackLevelRoles)
+	return err
+}
+
+const setWeeklySummaryAt = `-- name: SetWeeklySummaryAt :exec
+UPDATE guilds
+SET weekly_summary_at = $2
+WHERE guild_id = $1
+`
+
+type SetWeeklySummaryAtParams struct {
+	GuildID         int64            `json:"guildId"`
+	WeeklySummaryAt pgtype.Timestamp `json:"weeklySummaryAt"`
+}
+
+func (q *Queries) SetWeeklySummaryAt(ctx context.Context, arg SetWeeklySummaryAtParams) error {
+	_, err := q.db.Exec(ctx, setWeeklySummaryAt, arg.GuildID, arg.WeeklySummaryAt)
+	return err
+}
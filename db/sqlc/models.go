@@ -8,26 +8,82 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AutomodAllowedLink struct {
+	GuildID int64  `json:"guildId"`
+	Domain  string `json:"domain"`
+}
+
+type AutomodRule struct {
+	ID             int32            `json:"id"`
+	GuildID        int64            `json:"guildId"`
+	RuleType       string           `json:"ruleType"`
+	Pattern        string           `json:"pattern"`
+	Threshold      int32            `json:"threshold"`
+	Action         string           `json:"action"`
+	TimeoutSeconds int32            `json:"timeoutSeconds"`
+	CreatedAt      pgtype.Timestamp `json:"createdAt"`
+}
+
+type ChannelLock struct {
+	ChannelID     int64            `json:"channelId"`
+	GuildID       int64            `json:"guildId"`
+	HadOverwrite  bool             `json:"hadOverwrite"`
+	PreviousAllow int64            `json:"previousAllow"`
+	PreviousDeny  int64            `json:"previousDeny"`
+	LockedBy      int64            `json:"lockedBy"`
+	Reason        pgtype.Text      `json:"reason"`
+	Lockdown      bool             `json:"lockdown"`
+	LockedAt      pgtype.Timestamp `json:"lockedAt"`
+	UnlockAt      pgtype.Timestamp `json:"unlockAt"`
+}
+
+type CoinTransaction struct {
+	ID             int64            `json:"id"`
+	GuildID        int64            `json:"guildId"`
+	UserID         int64            `json:"userId"`
+	Kind           string           `json:"kind"`
+	InHandChange   int64            `json:"inHandChange"`
+	SafeChange     int64            `json:"safeChange"`
+	CounterpartyID pgtype.Int8      `json:"counterpartyId"`
+	Note           pgtype.Text      `json:"note"`
+	CreatedAt      pgtype.Timestamp `json:"createdAt"`
+}
+
 type Guild struct {
-	GuildID                     int64       `json:"guildId"`
-	ModlogsChannel              pgtype.Int8 `json:"modlogsChannel"`
-	LeaveJoinLogsChannel        pgtype.Int8 `json:"leaveJoinLogsChannel"`
-	YoutubeNotificationsChannel pgtype.Int8 `json:"youtubeNotificationsChannel"`
-	YoutubeNotificationsRole    pgtype.Int8 `json:"youtubeNotificationsRole"`
-	RedditNotificationsChannel  pgtype.Int8 `json:"redditNotificationsChannel"`
-	RedditNotificationsRole     pgtype.Int8 `json:"redditNotificationsRole"`
-	StmpdNotificationsChannel   pgtype.Int8 `json:"stmpdNotificationsChannel"`
-	StmpdNotificationsRole      pgtype.Int8 `json:"stmpdNotificationsRole"`
-	WelcomesChannel             pgtype.Int8 `json:"welcomesChannel"`
-	DeleteLogsChannel           pgtype.Int8 `json:"deleteLogsChannel"`
-	EditLogsChannel             pgtype.Int8 `json:"editLogsChannel"`
-	BotChannel                  pgtype.Int8 `json:"botChannel"`
-	RadioVoiceChannel           pgtype.Int8 `json:"radioVoiceChannel"`
-	NewsRole                    pgtype.Int8 `json:"newsRole"`
-	XpMultiplier                float64     `json:"xpMultiplier"`
-	TourNotificationsChannel    pgtype.Int8 `json:"tourNotificationsChannel"`
-	TourNotificationsRole       pgtype.Int8 `json:"tourNotificationsRole"`
-	ModeratorRole               pgtype.Int8 `json:"moderatorRole"`
+	GuildID                     int64            `json:"guildId"`
+	ModlogsChannel              pgtype.Int8      `json:"modlogsChannel"`
+	LeaveJoinLogsChannel        pgtype.Int8      `json:"leaveJoinLogsChannel"`
+	YoutubeNotificationsChannel pgtype.Int8      `json:"youtubeNotificationsChannel"`
+	YoutubeNotificationsRole    pgtype.Int8      `json:"youtubeNotificationsRole"`
+	RedditNotificationsChannel  pgtype.Int8      `json:"redditNotificationsChannel"`
+	RedditNotificationsRole     pgtype.Int8      `json:"redditNotificationsRole"`
+	StmpdNotificationsChannel   pgtype.Int8      `json:"stmpdNotificationsChannel"`
+	StmpdNotificationsRole      pgtype.Int8      `json:"stmpdNotificationsRole"`
+	WelcomesChannel             pgtype.Int8      `json:"welcomesChannel"`
+	DeleteLogsChannel           pgtype.Int8      `json:"deleteLogsChannel"`
+	EditLogsChannel             pgtype.Int8      `json:"editLogsChannel"`
+	BotChannel                  pgtype.Int8      `json:"botChannel"`
+	RadioVoiceChannel           pgtype.Int8      `json:"radioVoiceChannel"`
+	NewsRole                    pgtype.Int8      `json:"newsRole"`
+	XpMultiplier                float64          `json:"xpMultiplier"`
+	TourNotificationsChannel    pgtype.Int8      `json:"tourNotificationsChannel"`
+	TourNotificationsRole       pgtype.Int8      `json:"tourNotificationsRole"`
+	ModeratorRole               pgtype.Int8      `json:"moderatorRole"`
+	Timezone                    string           `json:"timezone"`
+	LevelUpAnnouncements        bool             `json:"levelUpAnnouncements"`
+	LevelUpMessage              pgtype.Text      `json:"levelUpMessage"`
+	StackLevelRoles             bool             `json:"stackLevelRoles"`
+	WeeklySummaryAt             pgtype.Timestamp `json:"weeklySummaryAt"`
+}
+
+type Inventory struct {
+	ID          int64            `json:"id"`
+	GuildID     int64            `json:"guildId"`
+	UserID      int64            `json:"userId"`
+	ItemID      int64            `json:"itemId"`
+	PricePaid   int64            `json:"pricePaid"`
+	PurchasedAt pgtype.Timestamp `json:"purchasedAt"`
+	ExpiresAt   pgtype.Timestamp `json:"expiresAt"`
 }
 
 type JoinLeaveLog struct {
@@ -36,6 +92,17 @@ type JoinLeaveLog struct {
 	Time     pgtype.Timestamp `json:"time"`
 }
 
+type LevelRole struct {
+	GuildID int64 `json:"guildId"`
+	Level   int32 `json:"level"`
+	RoleID  int64 `json:"roleId"`
+}
+
+type LockdownChannel struct {
+	GuildID   int64 `json:"guildId"`
+	ChannelID int64 `json:"channelId"`
+}
+
 type Message struct {
 	MessageID     int64            `json:"messageId"`
 	ChannelID     int64            `json:"channelId"`
@@ -47,21 +114,109 @@ type Message struct {
 }
 
 type Modlog struct {
-	ID          int64            `json:"id"`
+	ID           int64            `json:"id"`
+	UserID       int64            `json:"userId"`
+	ModeratorID  int64            `json:"moderatorId"`
+	LogType      string           `json:"logType"`
+	Reason       pgtype.Text      `json:"reason"`
+	Time         pgtype.Timestamp `json:"time"`
+	GuildID      int64            `json:"guildId"`
+	ExpiresAt    pgtype.Timestamp `json:"expiresAt"`
+	Active       pgtype.Bool      `json:"active"`
+	CaseNumber   int32            `json:"caseNumber"`
+	LogChannelID pgtype.Int8      `json:"logChannelId"`
+	LogMessageID pgtype.Int8      `json:"logMessageId"`
+	Voided       bool             `json:"voided"`
+}
+
+type ModlogEdit struct {
+	ID        int32            `json:"id"`
+	ModlogID  int64            `json:"modlogId"`
+	EditorID  int64            `json:"editorId"`
+	EditType  string           `json:"editType"`
+	OldReason pgtype.Text      `json:"oldReason"`
+	NewReason pgtype.Text      `json:"newReason"`
+	EditedAt  pgtype.Timestamp `json:"editedAt"`
+}
+
+type RaidEvent struct {
+	ID             int32            `json:"id"`
+	GuildID        int64            `json:"guildId"`
+	StartedBy      pgtype.Int8      `json:"startedBy"`
+	Action         string           `json:"action"`
+	TimeoutSeconds int32            `json:"timeoutSeconds"`
+	StartedAt      pgtype.Timestamp `json:"startedAt"`
+	EndsAt         pgtype.Timestamp `json:"endsAt"`
+	EndedAt        pgtype.Timestamp `json:"endedAt"`
+}
+
+type RaidEventMember struct {
+	RaidEventID int32            `json:"raidEventId"`
 	UserID      int64            `json:"userId"`
-	ModeratorID int64            `json:"moderatorId"`
-	LogType     string           `json:"logType"`
-	Reason      pgtype.Text      `json:"reason"`
-	Time        pgtype.Timestamp `json:"time"`
-	GuildID     int64            `json:"guildId"`
-	ExpiresAt   pgtype.Timestamp `json:"expiresAt"`
-	Active      pgtype.Bool      `json:"active"`
+	JoinedAt    pgtype.Timestamp `json:"joinedAt"`
+	Action      string           `json:"action"`
+}
+
+type RaidSetting struct {
+	GuildID           int64       `json:"guildId"`
+	Enabled           bool        `json:"enabled"`
+	JoinThreshold     int32       `json:"joinThreshold"`
+	JoinWindowSeconds int32       `json:"joinWindowSeconds"`
+	NewAccountDays    int32       `json:"newAccountDays"`
+	Action            string      `json:"action"`
+	TimeoutSeconds    int32       `json:"timeoutSeconds"`
+	RaidModeSeconds   int32       `json:"raidModeSeconds"`
+	AlertChannelID    pgtype.Int8 `json:"alertChannelId"`
+}
+
+type RankTheme struct {
+	ID            int64            `json:"id"`
+	GuildID       int64            `json:"guildId"`
+	Name          string           `json:"name"`
+	BackgroundUrl string           `json:"backgroundUrl"`
+	AccentColor   pgtype.Int4      `json:"accentColor"`
+	RequiredLevel int32            `json:"requiredLevel"`
+	CreatedAt     pgtype.Timestamp `json:"createdAt"`
 }
 
 type RedditPost struct {
 	PostID string `json:"postId"`
 }
 
+type RewardClaim struct {
+	UserID        int64            `json:"userId"`
+	GuildID       int64            `json:"guildId"`
+	Kind          string           `json:"kind"`
+	Streak        int32            `json:"streak"`
+	LastClaimedAt pgtype.Timestamp `json:"lastClaimedAt"`
+}
+
+type RobLog struct {
+	ID            int64            `json:"id"`
+	GuildID       int64            `json:"guildId"`
+	RobberID      int64            `json:"robberId"`
+	VictimID      int64            `json:"victimId"`
+	Outcome       string           `json:"outcome"`
+	Amount        int64            `json:"amount"`
+	SuccessChance float64          `json:"successChance"`
+	CreatedAt     pgtype.Timestamp `json:"createdAt"`
+}
+
+type ShopItem struct {
+	ID              int64            `json:"id"`
+	GuildID         int64            `json:"guildId"`
+	Name            string           `json:"name"`
+	Description     string           `json:"description"`
+	Kind            string           `json:"kind"`
+	Price           int64            `json:"price"`
+	RoleID          pgtype.Int8      `json:"roleId"`
+	BoostMultiplier pgtype.Float8    `json:"boostMultiplier"`
+	BoostMinutes    pgtype.Int4      `json:"boostMinutes"`
+	Color           pgtype.Int4      `json:"color"`
+	Enabled         bool             `json:"enabled"`
+	CreatedAt       pgtype.Timestamp `json:"createdAt"`
+}
+
 type Song struct {
 	ID              int64       `json:"id"`
 	Name            string      `json:"name"`
@@ -91,6 +246,7 @@ type Tag struct {
 	CreatedAt pgtype.Timestamp `json:"createdAt"`
 	Uses      pgtype.Int4      `json:"uses"`
 	Name      string           `json:"name"`
+	GuildID   int64            `json:"guildId"`
 }
 
 type TourShow struct {
@@ -114,6 +270,64 @@ type User struct {
 	GuildID      int64            `json:"guildId"`
 }
 
+type UserRankTheme struct {
+	UserID      int64       `json:"userId"`
+	GuildID     int64       `json:"guildId"`
+	Template    string      `json:"template"`
+	ThemeID     pgtype.Int8 `json:"themeId"`
+	AccentColor pgtype.Int4 `json:"accentColor"`
+	BarStyle    string      `json:"barStyle"`
+}
+
+type VoiceActivity struct {
+	UserID  int64 `json:"userId"`
+	GuildID int64 `json:"guildId"`
+	Seconds int64 `json:"seconds"`
+}
+
+type VoiceSession struct {
+	UserID     int64            `json:"userId"`
+	GuildID    int64            `json:"guildId"`
+	ChannelID  int64            `json:"channelId"`
+	StartedAt  pgtype.Timestamp `json:"startedAt"`
+	CreditedAt pgtype.Timestamp `json:"creditedAt"`
+}
+
+type WarnEscalation struct {
+	GuildID         int64  `json:"guildId"`
+	WarnCount       int32  `json:"warnCount"`
+	Action          string `json:"action"`
+	DurationSeconds int32  `json:"durationSeconds"`
+}
+
+type XpEvent struct {
+	ID         int64            `json:"id"`
+	GuildID    int64            `json:"guildId"`
+	Name       string           `json:"name"`
+	Multiplier float64          `json:"multiplier"`
+	StartsAt   pgtype.Timestamp `json:"startsAt"`
+	EndsAt     pgtype.Timestamp `json:"endsAt"`
+	CreatedBy  int64            `json:"createdBy"`
+}
+
+type XpOverride struct {
+	GuildID    int64   `json:"guildId"`
+	TargetType string  `json:"targetType"`
+	TargetID   int64   `json:"targetId"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+type XpSpamFlag struct {
+	ID        int32            `json:"id"`
+	GuildID   int64            `json:"guildId"`
+	UserID    int64            `json:"userId"`
+	ChannelID int64            `json:"channelId"`
+	MessageID int64            `json:"messageId"`
+	Reason    string           `json:"reason"`
+	Content   string           `json:"content"`
+	FlaggedAt pgtype.Timestamp `json:"flaggedAt"`
+}
+
 type YoutubeVideo struct {
 	VideoID string `json:"videoId"`
 }
@@ -11,7 +11,70 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const clearWarning = `-- name: ClearWarning :execrows
+UPDATE modlogs
+SET active = false
+WHERE guild_id = $1 AND user_id = $2 AND case_number = $3 AND log_type = 'warn' AND active = true AND voided = false
+`
+
+type ClearWarningParams struct {
+	GuildID    int64 `json:"guildId"`
+	UserID     int64 `json:"userId"`
+	CaseNumber int32 `json:"caseNumber"`
+}
+
+func (q *Queries) ClearWarning(ctx context.Context, arg ClearWarningParams) (int64, error) {
+	result, err := q.db.Exec(ctx, clearWarning, arg.GuildID, arg.UserID, arg.CaseNumber)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const clearWarnings = `-- name: ClearWarnings :execrows
+UPDATE modlogs
+SET active = false
+WHERE user_id = $1 AND guild_id = $2 AND log_type = 'warn' AND active = true AND voided = false
+`
+
+type ClearWarningsParams struct {
+	UserID  int64 `json:"userId"`
+	GuildID int64 `json:"guildId"`
+}
+
+func (q *Queries) ClearWarnings(ctx context.Context, arg ClearWarningsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, clearWarnings, arg.UserID, arg.GuildID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const countActiveWarnings = `-- name: CountActiveWarnings :one
+SELECT COUNT(*) FROM modlogs
+WHERE user_id = $1 AND guild_id = $2 AND log_type = 'warn' AND active = true AND voided = false
+`
+
+type CountActiveWarningsParams struct {
+	UserID  int64 `json:"userId"`
+	GuildID int64 `json:"guildId"`
+}
+
+func (q *Queries) CountActiveWarnings(ctx context.Context, arg CountActiveWarningsParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countActiveWarnings, arg.UserID, arg.GuildID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createModlog = `-- name: CreateModlog :one
+WITH next_case AS (
+    INSERT INTO modlog_case_counters (guild_id, last_case)
+    VALUES ($3, 1)
+    ON CONFLICT (guild_id)
+    DO UPDATE SET last_case = modlog_case_counters.last_case + 1
+    RETURNING last_case
+)
 INSERT INTO modlogs (
     user_id,
     moderator_id,
@@ -19,10 +82,11 @@ INSERT INTO modlogs (
     log_type,
     reason,
     expires_at,
-    active
+    active,
+    case_number
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7
-) RETURNING id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active
+    $1, $2, $3, $4, $5, $6, $7, (SELECT last_case FROM next_case)
+) RETURNING id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active, case_number, log_channel_id, log_message_id, voided
 `
 
 type CreateModlogParams struct {
@@ -56,6 +120,10 @@ func (q *Queries) CreateModlog(ctx context.Context, arg CreateModlogParams) (Mod
 		&i.GuildID,
 		&i.ExpiresAt,
 		&i.Active,
+		&i.CaseNumber,
+		&i.LogChannelID,
+		&i.LogMessageID,
+		&i.Voided,
 	)
 	return i, err
 }
@@ -71,8 +139,27 @@ func (q *Queries) DeactivateModlog(ctx context.Context, id int64) error {
 	return err
 }
 
+const deactivateTempBans = `-- name: DeactivateTempBans :exec
+UPDATE modlogs
+SET active = false
+WHERE guild_id = $1
+  AND user_id = ANY($2::bigint[])
+  AND log_type = 'tempban'
+  AND active = true
+`
+
+type DeactivateTempBansParams struct {
+	GuildID int64   `json:"guildId"`
+	UserIds []int64 `json:"userIds"`
+}
+
+func (q *Queries) DeactivateTempBans(ctx context.Context, arg DeactivateTempBansParams) error {
+	_, err := q.db.Exec(ctx, deactivateTempBans, arg.GuildID, arg.UserIds)
+	return err
+}
+
 const getActiveTempBanForUser = `-- name: GetActiveTempBanForUser :one
-SELECT id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active FROM modlogs
+SELECT id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active, case_number, log_channel_id, log_message_id, voided FROM modlogs
 WHERE user_id = $1 
   AND guild_id = $2 
   AND log_type = 'tempban' 
@@ -99,12 +186,16 @@ func (q *Queries) GetActiveTempBanForUser(ctx context.Context, arg GetActiveTemp
 		&i.GuildID,
 		&i.ExpiresAt,
 		&i.Active,
+		&i.CaseNumber,
+		&i.LogChannelID,
+		&i.LogMessageID,
+		&i.Voided,
 	)
 	return i, err
 }
 
 const getActiveTempMuteForUser = `-- name: GetActiveTempMuteForUser :one
-SELECT id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active FROM modlogs
+SELECT id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active, case_number, log_channel_id, log_message_id, voided FROM modlogs
 WHERE user_id = $1 
   AND guild_id = $2 
   AND log_type = 'tempmute' 
@@ -131,12 +222,16 @@ func (q *Queries) GetActiveTempMuteForUser(ctx context.Context, arg GetActiveTem
 		&i.GuildID,
 		&i.ExpiresAt,
 		&i.Active,
+		&i.CaseNumber,
+		&i.LogChannelID,
+		&i.LogMessageID,
+		&i.Voided,
 	)
 	return i, err
 }
 
 const getActiveTemporaryActions = `-- name: GetActiveTemporaryActions :many
-SELECT id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active FROM modlogs
+SELECT id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active, case_number, log_channel_id, log_message_id, voided FROM modlogs
 WHERE guild_id = $1 
   AND active = true 
   AND expires_at IS NOT NULL 
@@ -163,6 +258,56 @@ func (q *Queries) GetActiveTemporaryActions(ctx context.Context, guildID int64)
 			&i.GuildID,
 			&i.ExpiresAt,
 			&i.Active,
+			&i.CaseNumber,
+			&i.LogChannelID,
+			&i.LogMessageID,
+			&i.Voided,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getActiveWarnings = `-- name: GetActiveWarnings :many
+SELECT id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active, case_number, log_channel_id, log_message_id, voided FROM modlogs
+WHERE user_id = $1 AND guild_id = $2 AND log_type = 'warn' AND active = true AND voided = false
+ORDER BY time DESC
+LIMIT 25
+`
+
+type GetActiveWarningsParams struct {
+	UserID  int64 `json:"userId"`
+	GuildID int64 `json:"guildId"`
+}
+
+func (q *Queries) GetActiveWarnings(ctx context.Context, arg GetActiveWarningsParams) ([]Modlog, error) {
+	rows, err := q.db.Query(ctx, getActiveWarnings, arg.UserID, arg.GuildID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Modlog
+	for rows.Next() {
+		var i Modlog
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.ModeratorID,
+			&i.LogType,
+			&i.Reason,
+			&i.Time,
+			&i.GuildID,
+			&i.ExpiresAt,
+			&i.Active,
+			&i.CaseNumber,
+			&i.LogChannelID,
+			&i.LogMessageID,
+			&i.Voided,
 		); err != nil {
 			return nil, err
 		}
@@ -175,7 +320,7 @@ func (q *Queries) GetActiveTemporaryActions(ctx context.Context, guildID int64)
 }
 
 const getExpiredTemporaryActions = `-- name: GetExpiredTemporaryActions :many
-SELECT id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active FROM modlogs
+SELECT id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active, case_number, log_channel_id, log_message_id, voided FROM modlogs
 WHERE guild_id = $1 
   AND active = true 
   AND expires_at IS NOT NULL 
@@ -201,6 +346,10 @@ func (q *Queries) GetExpiredTemporaryActions(ctx context.Context, guildID int64)
 			&i.GuildID,
 			&i.ExpiresAt,
 			&i.Active,
+			&i.CaseNumber,
+			&i.LogChannelID,
+			&i.LogMessageID,
+			&i.Voided,
 		); err != nil {
 			return nil, err
 		}
@@ -212,8 +361,98 @@ func (q *Queries) GetExpiredTemporaryActions(ctx context.Context, guildID int64)
 	return items, nil
 }
 
+const getGuildsWithExpiredActions = `-- name: GetGuildsWithExpiredActions :many
+SELECT DISTINCT guild_id FROM modlogs
+WHERE active = true
+  AND expires_at IS NOT NULL
+  AND expires_at <= NOW()
+`
+
+func (q *Queries) GetGuildsWithExpiredActions(ctx context.Context) ([]int64, error) {
+	rows, err := q.db.Query(ctx, getGuildsWithExpiredActions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var guild_id int64
+		if err := rows.Scan(&guild_id); err != nil {
+			return nil, err
+		}
+		items = append(items, guild_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getModlogByCase = `-- name: GetModlogByCase :one
+SELECT id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active, case_number, log_channel_id, log_message_id, voided FROM modlogs
+WHERE guild_id = $1 AND case_number = $2
+`
+
+type GetModlogByCaseParams struct {
+	GuildID    int64 `json:"guildId"`
+	CaseNumber int32 `json:"caseNumber"`
+}
+
+func (q *Queries) GetModlogByCase(ctx context.Context, arg GetModlogByCaseParams) (Modlog, error) {
+	row := q.db.QueryRow(ctx, getModlogByCase, arg.GuildID, arg.CaseNumber)
+	var i Modlog
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ModeratorID,
+		&i.LogType,
+		&i.Reason,
+		&i.Time,
+		&i.GuildID,
+		&i.ExpiresAt,
+		&i.Active,
+		&i.CaseNumber,
+		&i.LogChannelID,
+		&i.LogMessageID,
+		&i.Voided,
+	)
+	return i, err
+}
+
+const getModlogByCaseForUpdate = `-- name: GetModlogByCaseForUpdate :one
+SELECT id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active, case_number, log_channel_id, log_message_id, voided FROM modlogs
+WHERE guild_id = $1 AND case_number = $2
+FOR UPDATE
+`
+
+type GetModlogByCaseForUpdateParams struct {
+	GuildID    int64 `json:"guildId"`
+	CaseNumber int32 `json:"caseNumber"`
+}
+
+func (q *Queries) GetModlogByCaseForUpdate(ctx context.Context, arg GetModlogByCaseForUpdateParams) (Modlog, error) {
+	row := q.db.QueryRow(ctx, getModlogByCaseForUpdate, arg.GuildID, arg.CaseNumber)
+	var i Modlog
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.ModeratorID,
+		&i.LogType,
+		&i.Reason,
+		&i.Time,
+		&i.GuildID,
+		&i.ExpiresAt,
+		&i.Active,
+		&i.CaseNumber,
+		&i.LogChannelID,
+		&i.LogMessageID,
+		&i.Voided,
+	)
+	return i, err
+}
+
 const getModlogByID = `-- name: GetModlogByID :one
-SELECT id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active FROM modlogs
+SELECT id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active, case_number, log_channel_id, log_message_id, voided FROM modlogs
 WHERE id = $1
 `
 
@@ -230,12 +469,16 @@ func (q *Queries) GetModlogByID(ctx context.Context, id int64) (Modlog, error) {
 		&i.GuildID,
 		&i.ExpiresAt,
 		&i.Active,
+		&i.CaseNumber,
+		&i.LogChannelID,
+		&i.LogMessageID,
+		&i.Voided,
 	)
 	return i, err
 }
 
 const getModlogsByGuild = `-- name: GetModlogsByGuild :many
-SELECT id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active FROM modlogs
+SELECT id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active, case_number, log_channel_id, log_message_id, voided FROM modlogs
 WHERE guild_id = $1
 ORDER BY time DESC
 LIMIT $2 OFFSET $3
@@ -266,6 +509,10 @@ func (q *Queries) GetModlogsByGuild(ctx context.Context, arg GetModlogsByGuildPa
 			&i.GuildID,
 			&i.ExpiresAt,
 			&i.Active,
+			&i.CaseNumber,
+			&i.LogChannelID,
+			&i.LogMessageID,
+			&i.Voided,
 		); err != nil {
 			return nil, err
 		}
@@ -278,7 +525,7 @@ func (q *Queries) GetModlogsByGuild(ctx context.Context, arg GetModlogsByGuildPa
 }
 
 const getModlogsByUser = `-- name: GetModlogsByUser :many
-SELECT id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active FROM modlogs
+SELECT id, user_id, moderator_id, log_type, reason, time, guild_id, expires_at, active, case_number, log_channel_id, log_message_id, voided FROM modlogs
 WHERE user_id = $1 AND guild_id = $2
 ORDER BY time DESC
 LIMIT $3 OFFSET $4
@@ -315,6 +562,10 @@ func (q *Queries) GetModlogsByUser(ctx context.Context, arg GetModlogsByUserPara
 			&i.GuildID,
 			&i.ExpiresAt,
 			&i.Active,
+			&i.CaseNumber,
+			&i.LogChannelID,
+			&i.LogMessageID,
+			&i.Voided,
 		); err != nil {
 			return nil, err
 		}
@@ -342,3 +593,47 @@ func (q *Queries) GetModlogsByUserCount(ctx context.Context, arg GetModlogsByUse
 	err := row.Scan(&count)
 	return count, err
 }
+
+const setModlogMessage = `-- name: SetModlogMessage :exec
+UPDATE modlogs
+SET log_channel_id = $2, log_message_id = $3
+WHERE id = $1
+`
+
+type SetModlogMessageParams struct {
+	ID           int64       `json:"id"`
+	LogChannelID pgtype.Int8 `json:"logChannelId"`
+	LogMessageID pgtype.Int8 `json:"logMessageId"`
+}
+
+func (q *Queries) SetModlogMessage(ctx context.Context, arg SetModlogMessageParams) error {
+	_, err := q.db.Exec(ctx, setModlogMessage, arg.ID, arg.LogChannelID, arg.LogMessageID)
+	return err
+}
+
+const updateModlogReason = `-- name: UpdateModlogReason :exec
+UPDATE modlogs
+SET reason = $2
+WHERE id = $1
+`
+
+type UpdateModlogReasonParams struct {
+	ID     int64       `json:"id"`
+	Reason pgtype.Text `json:"reason"`
+}
+
+func (q *Queries) UpdateModlogReason(ctx context.Context, arg UpdateModlogReasonParams) error {
+	_, err := q.db.Exec(ctx, updateModlogReason, arg.ID, arg.Reason)
+	return err
+}
+
+const voidModlog = `-- name: VoidModlog :exec
+UPDATE modlogs
+SET voided = true
+WHERE id = $1
+`
+
+func (q *Queries) VoidModlog(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, voidModlog, id)
+	return err
+}
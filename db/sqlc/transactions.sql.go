@@ -12,7 +12,7 @@ import (
 )
 
 const addCoins = `-- name: AddCoins :exec
-UPDATE users SET in_hand=in_hand + $3 WHERE id = $1 AND guild_id = $2
+UPDATE users SET in_hand = COALESCE(in_hand, 0) + $3 WHERE id = $1 AND guild_id = $2
 `
 
 type AddCoinsParams struct {
@@ -62,32 +62,44 @@ func (q *Queries) GetBalance(ctx context.Context, arg GetBalanceParams) (GetBala
 	return i, err
 }
 
-const giveCoins = `-- name: GiveCoins :exec
-WITH sender_update AS (
-    UPDATE users AS sender
-    SET in_hand = sender.in_hand - $4
-    WHERE sender.id = $1 AND sender.in_hand >= $3 AND sender.guild_id = $3
-    RETURNING sender.id
-)
-UPDATE users AS receiver
-SET in_hand = receiver.in_hand + $4
-WHERE receiver.id = $2 AND receiver.guild_id = $3
-AND EXISTS (SELECT 1 FROM sender_update)
+const getBalanceForUpdate = `-- name: GetBalanceForUpdate :one
+SELECT garrix_coins, in_hand FROM users WHERE id = $1 AND guild_id = $2 FOR UPDATE
 `
 
-type GiveCoinsParams struct {
-	ID      int64       `json:"id"`
-	ID_2    int64       `json:"id2"`
-	GuildID int64       `json:"guildId"`
-	InHand  pgtype.Int8 `json:"inHand"`
+type GetBalanceForUpdateParams struct {
+	ID      int64 `json:"id"`
+	GuildID int64 `json:"guildId"`
+}
+
+type GetBalanceForUpdateRow struct {
+	GarrixCoins pgtype.Int8 `json:"garrixCoins"`
+	InHand      pgtype.Int8 `json:"inHand"`
+}
+
+func (q *Queries) GetBalanceForUpdate(ctx context.Context, arg GetBalanceForUpdateParams) (GetBalanceForUpdateRow, error) {
+	row := q.db.QueryRow(ctx, getBalanceForUpdate, arg.ID, arg.GuildID)
+	var i GetBalanceForUpdateRow
+	err := row.Scan(&i.GarrixCoins, &i.InHand)
+	return i, err
+}
+
+const spendCoins = `-- name: SpendCoins :exec
+UPDATE users SET in_hand = in_hand - $3, garrix_coins = garrix_coins - $4 WHERE id = $1 AND guild_id = $2
+`
+
+type SpendCoinsParams struct {
+	ID          int64       `json:"id"`
+	GuildID     int64       `json:"guildId"`
+	InHand      pgtype.Int8 `json:"inHand"`
+	GarrixCoins pgtype.Int8 `json:"garrixCoins"`
 }
 
-func (q *Queries) GiveCoins(ctx context.Context, arg GiveCoinsParams) error {
-	_, err := q.db.Exec(ctx, giveCoins,
+func (q *Queries) SpendCoins(ctx context.Context, arg SpendCoinsParams) error {
+	_, err := q.db.Exec(ctx, spendCoins,
 		arg.ID,
-		arg.ID_2,
 		arg.GuildID,
 		arg.InHand,
+		arg.GarrixCoins,
 	)
 	return err
 }
@@ -11,6 +11,25 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addXp = `-- name: AddXp :one
+UPDATE users SET total_xp = COALESCE(total_xp, 0) + $3
+WHERE id = $1 AND guild_id = $2
+RETURNING total_xp
+`
+
+type AddXpParams struct {
+	ID      int64       `json:"id"`
+	GuildID int64       `json:"guildId"`
+	TotalXp pgtype.Int4 `json:"totalXp"`
+}
+
+func (q *Queries) AddXp(ctx context.Context, arg AddXpParams) (pgtype.Int4, error) {
+	row := q.db.QueryRow(ctx, addXp, arg.ID, arg.GuildID, arg.TotalXp)
+	var total_xp pgtype.Int4
+	err := row.Scan(&total_xp)
+	return total_xp, err
+}
+
 const createUser = `-- name: CreateUser :one
 INSERT INTO users(id, guild_id)
 VALUES ($1, $2)
@@ -37,10 +56,26 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 	return i, err
 }
 
+const ensureUser = `-- name: EnsureUser :exec
+INSERT INTO users(id, guild_id)
+VALUES ($1, $2)
+ON CONFLICT (id, guild_id) DO NOTHING
+`
+
+type EnsureUserParams struct {
+	ID      int64 `json:"id"`
+	GuildID int64 `json:"guildId"`
+}
+
+func (q *Queries) EnsureUser(ctx context.Context, arg EnsureUserParams) error {
+	_, err := q.db.Exec(ctx, ensureUser, arg.ID, arg.GuildID)
+	return err
+}
+
 const getCoinsLeaderboard = `-- name: GetCoinsLeaderboard :many
-SELECT id, garrix_coins, in_hand FROM users
+SELECT id, garrix_coins, in_hand, total_xp, COUNT(*) OVER () AS total FROM users
 WHERE guild_id = $1
-ORDER BY garrix_coins + in_hand DESC OFFSET $2 LIMIT 10
+ORDER BY garrix_coins + in_hand DESC, id OFFSET $2 LIMIT 10
 `
 
 type GetCoinsLeaderboardParams struct {
@@ -52,6 +87,8 @@ type GetCoinsLeaderboardRow struct {
 	ID          int64       `json:"id"`
 	GarrixCoins pgtype.Int8 `json:"garrixCoins"`
 	InHand      pgtype.Int8 `json:"inHand"`
+	TotalXp     pgtype.Int4 `json:"totalXp"`
+	Total       int64       `json:"total"`
 }
 
 func (q *Queries) GetCoinsLeaderboard(ctx context.Context, arg GetCoinsLeaderboardParams) ([]GetCoinsLeaderboardRow, error) {
@@ -63,7 +100,13 @@ func (q *Queries) GetCoinsLeaderboard(ctx context.Context, arg GetCoinsLeaderboa
 	var items []GetCoinsLeaderboardRow
 	for rows.Next() {
 		var i GetCoinsLeaderboardRow
-		if err := rows.Scan(&i.ID, &i.GarrixCoins, &i.InHand); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.GarrixCoins,
+			&i.InHand,
+			&i.TotalXp,
+			&i.Total,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -74,10 +117,31 @@ func (q *Queries) GetCoinsLeaderboard(ctx context.Context, arg GetCoinsLeaderboa
 	return items, nil
 }
 
+const getCoinsLeaderboardPosition = `-- name: GetCoinsLeaderboardPosition :one
+SELECT position FROM (
+  SELECT id, ROW_NUMBER() OVER (ORDER BY garrix_coins + in_hand DESC, id) AS position
+  FROM users
+  WHERE guild_id = $1
+) AS ranked
+WHERE id = $2
+`
+
+type GetCoinsLeaderboardPositionParams struct {
+	GuildID int64 `json:"guildId"`
+	ID      int64 `json:"id"`
+}
+
+func (q *Queries) GetCoinsLeaderboardPosition(ctx context.Context, arg GetCoinsLeaderboardPositionParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getCoinsLeaderboardPosition, arg.GuildID, arg.ID)
+	var position int64
+	err := row.Scan(&position)
+	return position, err
+}
+
 const getInHandLeaderboard = `-- name: GetInHandLeaderboard :many
-SELECT id, in_hand FROM users
+SELECT id, in_hand, total_xp, COUNT(*) OVER () AS total FROM users
 WHERE guild_id = $1
-ORDER BY in_hand DESC OFFSET $2 LIMIT 10
+ORDER BY in_hand DESC, id OFFSET $2 LIMIT 10
 `
 
 type GetInHandLeaderboardParams struct {
@@ -86,8 +150,10 @@ type GetInHandLeaderboardParams struct {
 }
 
 type GetInHandLeaderboardRow struct {
-	ID     int64       `json:"id"`
-	InHand pgtype.Int8 `json:"inHand"`
+	ID      int64       `json:"id"`
+	InHand  pgtype.Int8 `json:"inHand"`
+	TotalXp pgtype.Int4 `json:"totalXp"`
+	Total   int64       `json:"total"`
 }
 
 func (q *Queries) GetInHandLeaderboard(ctx context.Context, arg GetInHandLeaderboardParams) ([]GetInHandLeaderboardRow, error) {
@@ -99,7 +165,12 @@ func (q *Queries) GetInHandLeaderboard(ctx context.Context, arg GetInHandLeaderb
 	var items []GetInHandLeaderboardRow
 	for rows.Next() {
 		var i GetInHandLeaderboardRow
-		if err := rows.Scan(&i.ID, &i.InHand); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.InHand,
+			&i.TotalXp,
+			&i.Total,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -110,10 +181,31 @@ func (q *Queries) GetInHandLeaderboard(ctx context.Context, arg GetInHandLeaderb
 	return items, nil
 }
 
+const getInHandLeaderboardPosition = `-- name: GetInHandLeaderboardPosition :one
+SELECT position FROM (
+  SELECT id, ROW_NUMBER() OVER (ORDER BY in_hand DESC, id) AS position
+  FROM users
+  WHERE guild_id = $1
+) AS ranked
+WHERE id = $2
+`
+
+type GetInHandLeaderboardPositionParams struct {
+	GuildID int64 `json:"guildId"`
+	ID      int64 `json:"id"`
+}
+
+func (q *Queries) GetInHandLeaderboardPosition(ctx context.Context, arg GetInHandLeaderboardPositionParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getInHandLeaderboardPosition, arg.GuildID, arg.ID)
+	var position int64
+	err := row.Scan(&position)
+	return position, err
+}
+
 const getLevelsLeaderboard = `-- name: GetLevelsLeaderboard :many
-SELECT id, total_xp FROM users
+SELECT id, total_xp, COUNT(*) OVER () AS total FROM users
 WHERE guild_id = $1
-ORDER BY total_xp DESC OFFSET $2 LIMIT 10
+ORDER BY total_xp DESC, id OFFSET $2 LIMIT 10
 `
 
 type GetLevelsLeaderboardParams struct {
@@ -124,6 +216,7 @@ type GetLevelsLeaderboardParams struct {
 type GetLevelsLeaderboardRow struct {
 	ID      int64       `json:"id"`
 	TotalXp pgtype.Int4 `json:"totalXp"`
+	Total   int64       `json:"total"`
 }
 
 func (q *Queries) GetLevelsLeaderboard(ctx context.Context, arg GetLevelsLeaderboardParams) ([]GetLevelsLeaderboardRow, error) {
@@ -135,7 +228,7 @@ func (q *Queries) GetLevelsLeaderboard(ctx context.Context, arg GetLevelsLeaderb
 	var items []GetLevelsLeaderboardRow
 	for rows.Next() {
 		var i GetLevelsLeaderboardRow
-		if err := rows.Scan(&i.ID, &i.TotalXp); err != nil {
+		if err := rows.Scan(&i.ID, &i.TotalXp, &i.Total); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -146,10 +239,31 @@ func (q *Queries) GetLevelsLeaderboard(ctx context.Context, arg GetLevelsLeaderb
 	return items, nil
 }
 
+const getLevelsLeaderboardPosition = `-- name: GetLevelsLeaderboardPosition :one
+SELECT position FROM (
+  SELECT id, ROW_NUMBER() OVER (ORDER BY total_xp DESC, id) AS position
+  FROM users
+  WHERE guild_id = $1
+) AS ranked
+WHERE id = $2
+`
+
+type GetLevelsLeaderboardPositionParams struct {
+	GuildID int64 `json:"guildId"`
+	ID      int64 `json:"id"`
+}
+
+func (q *Queries) GetLevelsLeaderboardPosition(ctx context.Context, arg GetLevelsLeaderboardPositionParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getLevelsLeaderboardPosition, arg.GuildID, arg.ID)
+	var position int64
+	err := row.Scan(&position)
+	return position, err
+}
+
 const getMessagesSentLeaderboard = `-- name: GetMessagesSentLeaderboard :many
-SELECT id, messages_sent FROM users
+SELECT id, messages_sent, total_xp, COUNT(*) OVER () AS total FROM users
 WHERE guild_id = $1
-ORDER BY messages_sent DESC OFFSET $2 LIMIT 10
+ORDER BY messages_sent DESC, id OFFSET $2 LIMIT 10
 `
 
 type GetMessagesSentLeaderboardParams struct {
@@ -160,6 +274,8 @@ type GetMessagesSentLeaderboardParams struct {
 type GetMessagesSentLeaderboardRow struct {
 	ID           int64       `json:"id"`
 	MessagesSent pgtype.Int4 `json:"messagesSent"`
+	TotalXp      pgtype.Int4 `json:"totalXp"`
+	Total        int64       `json:"total"`
 }
 
 func (q *Queries) GetMessagesSentLeaderboard(ctx context.Context, arg GetMessagesSentLeaderboardParams) ([]GetMessagesSentLeaderboardRow, error) {
@@ -171,7 +287,12 @@ func (q *Queries) GetMessagesSentLeaderboard(ctx context.Context, arg GetMessage
 	var items []GetMessagesSentLeaderboardRow
 	for rows.Next() {
 		var i GetMessagesSentLeaderboardRow
-		if err := rows.Scan(&i.ID, &i.MessagesSent); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.MessagesSent,
+			&i.TotalXp,
+			&i.Total,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -182,6 +303,27 @@ func (q *Queries) GetMessagesSentLeaderboard(ctx context.Context, arg GetMessage
 	return items, nil
 }
 
+const getMessagesSentLeaderboardPosition = `-- name: GetMessagesSentLeaderboardPosition :one
+SELECT position FROM (
+  SELECT id, ROW_NUMBER() OVER (ORDER BY messages_sent DESC, id) AS position
+  FROM users
+  WHERE guild_id = $1
+) AS ranked
+WHERE id = $2
+`
+
+type GetMessagesSentLeaderboardPositionParams struct {
+	GuildID int64 `json:"guildId"`
+	ID      int64 `json:"id"`
+}
+
+func (q *Queries) GetMessagesSentLeaderboardPosition(ctx context.Context, arg GetMessagesSentLeaderboardPositionParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getMessagesSentLeaderboardPosition, arg.GuildID, arg.ID)
+	var position int64
+	err := row.Scan(&position)
+	return position, err
+}
+
 const getUser = `-- name: GetUser :one
 SELECT id, messages_sent, total_xp, last_xp_added, garrix_coins, in_hand, guild_id FROM users WHERE id = $1 AND guild_id = $2
 `
@@ -206,6 +348,30 @@ func (q *Queries) GetUser(ctx context.Context, arg GetUserParams) (User, error)
 	return i, err
 }
 
+const getUserForUpdate = `-- name: GetUserForUpdate :one
+SELECT id, messages_sent, total_xp, last_xp_added, garrix_coins, in_hand, guild_id FROM users WHERE id = $1 AND guild_id = $2 FOR UPDATE
+`
+
+type GetUserForUpdateParams struct {
+	ID      int64 `json:"id"`
+	GuildID int64 `json:"guildId"`
+}
+
+func (q *Queries) GetUserForUpdate(ctx context.Context, arg GetUserForUpdateParams) (User, error) {
+	row := q.db.QueryRow(ctx, getUserForUpdate, arg.ID, arg.GuildID)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.MessagesSent,
+		&i.TotalXp,
+		&i.LastXpAdded,
+		&i.GarrixCoins,
+		&i.InHand,
+		&i.GuildID,
+	)
+	return i, err
+}
+
 const getUserLevelData = `-- name: GetUserLevelData :one
 WITH user_ranks AS (
   SELECT id, messages_sent, total_xp, last_xp_added, garrix_coins, in_hand, guild_id,
@@ -249,3 +415,104 @@ func (q *Queries) GetUserLevelData(ctx context.Context, arg GetUserLevelDataPara
 	)
 	return i, err
 }
+
+const getUserLevels = `-- name: GetUserLevels :many
+SELECT id, total_xp, messages_sent FROM users
+WHERE guild_id = $1 AND id = ANY($2::bigint[])
+`
+
+type GetUserLevelsParams struct {
+	GuildID int64   `json:"guildId"`
+	UserIds []int64 `json:"userIds"`
+}
+
+type GetUserLevelsRow struct {
+	ID           int64       `json:"id"`
+	TotalXp      pgtype.Int4 `json:"totalXp"`
+	MessagesSent pgtype.Int4 `json:"messagesSent"`
+}
+
+func (q *Queries) GetUserLevels(ctx context.Context, arg GetUserLevelsParams) ([]GetUserLevelsRow, error) {
+	rows, err := q.db.Query(ctx, getUserLevels, arg.GuildID, arg.UserIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserLevelsRow
+	for rows.Next() {
+		var i GetUserLevelsRow
+		if err := rows.Scan(&i.ID, &i.TotalXp, &i.MessagesSent); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const importUserLevels = `-- name: ImportUserLevels :exec
+INSERT INTO users (id, guild_id, total_xp, messages_sent)
+SELECT unnest($1::bigint[]), $2::bigint, unnest($3::int[]), unnest($4::int[])
+ON CONFLICT (id, guild_id) DO UPDATE
+SET total_xp = CASE $5::text
+        WHEN 'overwrite' THEN EXCLUDED.total_xp
+        WHEN 'add' THEN LEAST(COALESCE(users.total_xp, 0)::bigint + EXCLUDED.total_xp, 2147483647)::int
+        ELSE GREATEST(users.total_xp, EXCLUDED.total_xp)
+    END,
+    messages_sent = CASE $5::text
+        WHEN 'overwrite' THEN EXCLUDED.messages_sent
+        WHEN 'add' THEN LEAST(COALESCE(users.messages_sent, 0)::bigint + EXCLUDED.messages_sent, 2147483647)::int
+        ELSE GREATEST(users.messages_sent, EXCLUDED.messages_sent)
+    END
+`
+
+type ImportUserLevelsParams struct {
+	UserIds      []int64 `json:"userIds"`
+	GuildID      int64   `json:"guildId"`
+	TotalXps     []int32 `json:"totalXps"`
+	MessagesSent []int32 `json:"messagesSent"`
+	Strategy     string  `json:"strategy"`
+}
+
+func (q *Queries) ImportUserLevels(ctx context.Context, arg ImportUserLevelsParams) error {
+	_, err := q.db.Exec(ctx, importUserLevels,
+		arg.UserIds,
+		arg.GuildID,
+		arg.TotalXps,
+		arg.MessagesSent,
+		arg.Strategy,
+	)
+	return err
+}
+
+const resetUser = `-- name: ResetUser :exec
+UPDATE users SET messages_sent = 0, total_xp = 0, garrix_coins = 0, in_hand = 0
+WHERE id = $1 AND guild_id = $2
+`
+
+type ResetUserParams struct {
+	ID      int64 `json:"id"`
+	GuildID int64 `json:"guildId"`
+}
+
+func (q *Queries) ResetUser(ctx context.Context, arg ResetUserParams) error {
+	_, err := q.db.Exec(ctx, resetUser, arg.ID, arg.GuildID)
+	return err
+}
+
+const setXp = `-- name: SetXp :exec
+UPDATE users SET total_xp = $3 WHERE id = $1 AND guild_id = $2
+`
+
+type SetXpParams struct {
+	ID      int64       `json:"id"`
+	GuildID int64       `json:"guildId"`
+	TotalXp pgtype.Int4 `json:"totalXp"`
+}
+
+func (q *Queries) SetXp(ctx context.Context, arg SetXpParams) error {
+	_, err := q.db.Exec(ctx, setXp, arg.ID, arg.GuildID, arg.TotalXp)
+	return err
+}
@@ -2,16 +2,18 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
+	// Embedded so guild timezones load in images without tzdata installed
+	_ "time/tzdata"
 
 	"github.com/disgoorg/disgo/bot"
 	"github.com/disgoorg/disgo/handler"
-	"github.com/disgoorg/snowflake/v2"
 	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
 	"github.com/milindmadhukar/MartinGarrixBot/mgbot/commands"
 	"github.com/milindmadhukar/MartinGarrixBot/mgbot/handlers"
@@ -39,6 +41,10 @@ func main() {
 	path := flag.String("config", "config.toml", "path to config")
 	shouldClearCommands := flag.Bool("clear-commands", false, "Whether to clear commands from discord")
 	fetchAllBeatport := flag.Bool("fetch-all-beatport", false, "Fetch all beatport songs (initial bulk import, no announcements)")
+	importLevelsPath := flag.String("import-levels", "", "Import levels from a MEE6 or other bot's JSON/CSV export into --import-guild, then exit")
+	importGuild := flag.Uint64("import-guild", 0, "Guild to import levels into")
+	importStrategy := flag.String("import-strategy", mgbot.ImportKeepMax, "How imported levels combine with existing ones (max, overwrite or add)")
+	importDryRun := flag.Bool("import-dry-run", false, "Report what --import-levels would change without writing anything")
 	flag.Parse()
 
 	cfg, err := mgbot.LoadConfig(*path)
@@ -61,6 +67,14 @@ func main() {
 		os.Exit(-1)
 	}
 
+	if *importLevelsPath != "" {
+		if err = importLevels(b, *importLevelsPath, int64(*importGuild), *importStrategy, *importDryRun); err != nil {
+			slog.Error("Failed to import levels", slog.Any("err", err))
+			os.Exit(-1)
+		}
+		return
+	}
+
 	// Started after SetupDB so the database check has a pool, but before the
 	// gateway opens so /health answers during startup (reporting unhealthy)
 	// rather than refusing connections.
@@ -110,48 +124,44 @@ func main() {
 		)
 	}
 
-	// TODO: Seems out of place, place somwehere more appropriate
-	go func() {
-		for {
-			if b.IsReady {
-				go handlers.GetRedditPosts(b, time.NewTicker(3*time.Minute))
-				go handlers.GetYoutubeVideos(b, time.NewTicker(3*time.Minute))
-				go handlers.GetAllStmpdReleases(b, time.NewTicker(15*time.Minute))
-				go handlers.GetBeatportReleases(b, time.NewTicker(15*time.Minute), *fetchAllBeatport)
-				go handlers.GetAllTourShows(b, time.NewTicker(10*time.Minute))
-
-				// Auto-start radio in all configured guilds (only if Lavalink is connected)
-				go func() {
-					time.Sleep(5 * time.Second) // Wait for everything to be ready
-
-					// Only auto-start if Lavalink is connected
-					if b.RadioManager == nil || !b.RadioManager.IsLavalinkConnected() {
-						slog.Info("Lavalink not connected - skipping auto-start of radio")
-						return
-					}
-
-					radioConfigs, err := b.Queries.GetRadioVoiceChannels(context.Background())
-					if err != nil {
-						slog.Error("Failed to get radio configurations", slog.Any("err", err))
-						return
-					}
-
-					for _, config := range radioConfigs {
-						if config.RadioVoiceChannel.Valid {
-							guildID := snowflake.ID(config.GuildID)
-							slog.Info("Auto-starting radio", slog.String("guild_id", guildID.String()))
-							if err := b.StartRadioInGuild(context.Background(), guildID); err != nil {
-								slog.Error("Failed to start radio", slog.Any("err", err), slog.String("guild_id", guildID.String()))
-							}
-						}
-					}
-				}()
-
-				return
+	// Background fetchers. The scheduler is started from OnReady once the
+	// gateway is up, and restarts any job that fails or panics.
+	b.Scheduler.Register("reddit", 3*time.Minute, func(ctx context.Context) (int, error) {
+		return handlers.GetRedditPosts(ctx, b)
+	})
+	b.Scheduler.Register("youtube", 3*time.Minute, func(ctx context.Context) (int, error) {
+		return handlers.GetYoutubeVideos(ctx, b)
+	})
+	b.Scheduler.Register("stmpd", 15*time.Minute, func(ctx context.Context) (int, error) {
+		return handlers.GetAllStmpdReleases(ctx, b)
+	})
+	if b.BeatportClient != nil {
+		// --fetch-all-beatport only applies until the first successful run,
+		// after which the job switches to normal periodic mode.
+		beatportFetchAll := *fetchAllBeatport
+		b.Scheduler.Register("beatport", 15*time.Minute, func(ctx context.Context) (int, error) {
+			found, err := handlers.GetBeatportReleases(ctx, b, beatportFetchAll)
+			if err != nil {
+				return 0, err
 			}
-			time.Sleep(1 * time.Second)
-		}
-	}()
+			if beatportFetchAll {
+				slog.Info("Initial beatport bulk import complete, switching to normal periodic mode")
+				beatportFetchAll = false
+			}
+			return found, nil
+		})
+	}
+	b.Scheduler.Register("tour", 10*time.Minute, func(ctx context.Context) (int, error) {
+		return handlers.GetAllTourShows(ctx, b)
+	})
+	b.Scheduler.Register("voice_xp", mgbot.VoiceXpInterval, b.CreditVoiceSessions)
+	b.Scheduler.Register("weekly_summary", mgbot.WeeklySummaryInterval, b.PostWeeklySummaries)
+	b.Scheduler.Register("activity_prune", mgbot.ActivityPruneInterval, b.PruneActivity)
+	b.Scheduler.Register("xp_spam_prune", mgbot.XpSpamFlagPruneInterval, b.PruneXpSpamFlags)
+	b.Scheduler.Register("xp_spam_tracker_prune", mgbot.XpSpamTrackerPruneInterval, b.PruneXpSpamTracker)
+	b.Scheduler.Register("temporary_action_expiry", mgbot.TemporaryActionExpiryInterval, b.ExpireTemporaryActions)
+	b.Scheduler.Register("channel_lock_expiry", mgbot.ChannelLockExpiryInterval, b.UnlockExpiredChannels)
+	b.Scheduler.Register("raid_mode_expiry", mgbot.RaidModeExpiryInterval, b.EndExpiredRaidModes)
 
 	defer func() {
 		ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
@@ -186,12 +196,54 @@ func main() {
 	<-s
 	slog.Info("Shutting down bot...")
 
-	// Graceful shutdown: disconnect from all radio channels and clear status
+	// Graceful shutdown: stop background jobs, disconnect from all radio
+	// channels and clear status
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
+	b.Scheduler.Stop(shutdownCtx)
+
 	if b.RadioManager != nil {
 		slog.Info("Disconnecting from all radio channels...")
 		b.DisconnectAllRadioChannels(shutdownCtx)
 	}
 }
+
+// importLevels runs --import-levels, logging progress as it goes.
+func importLevels(b *mgbot.MartinGarrixBot, path string, guildID int64, strategy string, dryRun bool) error {
+	if guildID == 0 {
+		return errors.New("--import-guild is required")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	records, err := mgbot.ParseLevelImport(file)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Importing levels", slog.Int("members", len(records)), slog.Int64("guild_id", guildID), slog.String("strategy", strategy), slog.Bool("dry_run", dryRun))
+	report, err := b.ImportLevels(context.Background(), guildID, records, strategy, dryRun, func(done, total int) {
+		slog.Info("Importing levels", slog.Int("done", done), slog.Int("total", total))
+	})
+	if err != nil {
+		return err
+	}
+
+	slog.Info("Level import complete",
+		slog.Bool("dry_run", dryRun),
+		slog.Int("total", report.Total),
+		slog.Int("created", report.Created),
+		slog.Int("updated", report.Updated),
+		slog.Int("unchanged", report.Unchanged),
+	)
+	if !dryRun && len(report.Levels) > 0 {
+		// The bot isn't connected to Discord here
+		slog.Info("Level roles weren't synced, members get theirs on their next level up", slog.Int("members", len(report.Levels)))
+	}
+	return nil
+}
@@ -0,0 +1,178 @@
+package mgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+// Periods a leaderboard can cover.
+const (
+	PeriodToday   = "today"
+	PeriodWeek    = "week"
+	PeriodMonth   = "month"
+	PeriodAllTime = "all-time"
+)
+
+// What a period leaderboard ranks members by.
+const (
+	ActivityXp       = "xp"
+	ActivityMessages = "messages"
+	ActivityVoice    = "voice"
+)
+
+const (
+	// WeeklySummaryInterval is how often guilds are checked for a weekly
+	// summary to post. It is posted on the first check on a Monday.
+	WeeklySummaryInterval = time.Hour
+	// ActivityPruneInterval is how often activity too old for any period
+	// leaderboard is deleted.
+	ActivityPruneInterval = 24 * time.Hour
+	// activityRetention covers the longest period plus the week the Monday
+	// summary looks back on.
+	activityRetention = 40 * 24 * time.Hour
+)
+
+// ActivityHour returns the hour bucket activity at t is recorded in. Buckets
+// are hours in UTC, so periods in timezones with a half hour offset are off by
+// up to 30 minutes.
+func ActivityHour(t time.Time) pgtype.Timestamp {
+	return pgtype.Timestamp{Time: t.UTC().Truncate(time.Hour), Valid: true}
+}
+
+// GuildLocation returns the guild's configured timezone, falling back to UTC.
+func (b *MartinGarrixBot) GuildLocation(ctx context.Context, guildID int64) *time.Location {
+	timezone, err := b.Queries.GetGuildTimezone(ctx, guildID)
+	if err != nil {
+		if !errors.Is(err, db.ErrRecordNotFound) {
+			slog.Error("Failed to get guild timezone", slog.Any("err", err))
+		}
+		return time.UTC
+	}
+	return loadLocation(timezone)
+}
+
+func loadLocation(timezone string) *time.Location {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		slog.Warn("Invalid guild timezone, using UTC", slog.String("timezone", timezone), slog.Any("err", err))
+		return time.UTC
+	}
+	return loc
+}
+
+// StartOfDay returns midnight of the day containing t.
+func StartOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// StartOfWeek returns the start of the week containing t. Weeks start on
+// Monday.
+func StartOfWeek(t time.Time) time.Time {
+	day := StartOfDay(t)
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// PeriodStart returns the start of the period containing t, in t's location.
+func PeriodStart(period string, t time.Time) time.Time {
+	switch period {
+	case PeriodToday:
+		return StartOfDay(t)
+	case PeriodWeek:
+		return StartOfWeek(t)
+	case PeriodMonth:
+		year, month, _ := t.Date()
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Time{}
+}
+
+// PostWeeklySummaries posts the top chatters of last week to the bot channel of
+// every guild where it is Monday and they haven't been posted yet. It returns
+// the number of summaries posted.
+func (b *MartinGarrixBot) PostWeeklySummaries(ctx context.Context) (int, error) {
+	guilds, err := b.Queries.GetWeeklySummaryGuilds(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	posted := 0
+	for _, guild := range guilds {
+		now := time.Now().In(loadLocation(guild.Timezone))
+		if now.Weekday() != time.Monday {
+			continue
+		}
+
+		weekStart := StartOfWeek(now)
+		if guild.WeeklySummaryAt.Valid && !guild.WeeklySummaryAt.Time.Before(weekStart.UTC()) {
+			continue
+		}
+
+		// A guild whose bot channel is gone shouldn't hold up the others, it is
+		// retried on the next run
+		if err := b.postWeeklySummary(ctx, guild, weekStart); err != nil {
+			slog.Error("Failed to post weekly summary", slog.Int64("guild_id", guild.GuildID), slog.Any("err", err))
+			continue
+		}
+		posted++
+	}
+
+	return posted, nil
+}
+
+func (b *MartinGarrixBot) postWeeklySummary(ctx context.Context, guild db.GetWeeklySummaryGuildsRow, weekStart time.Time) error {
+	lastWeek := weekStart.AddDate(0, 0, -7)
+
+	chatters, err := b.Queries.GetActivityLeaderboard(ctx, db.GetActivityLeaderboardParams{
+		Metric:  ActivityMessages,
+		GuildID: guild.GuildID,
+		Since:   pgtype.Timestamp{Time: lastWeek.UTC(), Valid: true},
+		Until:   pgtype.Timestamp{Time: weekStart.UTC(), Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	// A quiet week is marked as done without posting anything
+	if len(chatters) > 0 {
+		lines := make([]string, len(chatters))
+		for i, chatter := range chatters {
+			lines[i] = fmt.Sprintf("**%d.** %s - %d messages", i+1, discord.UserMention(snowflake.ID(chatter.UserID)), chatter.Amount)
+		}
+
+		embed := discord.NewEmbedBuilder().
+			SetTitle("Top Chatters of the Week").
+			SetDescription(strings.Join(lines, "\n")).
+			SetColor(utils.ColorSuccess).
+			SetFooterTextf("%s - %s | See more with /leaderboard", lastWeek.Format("2 Jan"), weekStart.AddDate(0, 0, -1).Format("2 Jan")).
+			Build()
+
+		_, err = b.Client.Rest().CreateMessage(snowflake.ID(guild.BotChannel.Int64), discord.NewMessageCreateBuilder().
+			SetEmbeds(embed).
+			Build(),
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return b.Queries.SetWeeklySummaryAt(ctx, db.SetWeeklySummaryAtParams{
+		GuildID:         guild.GuildID,
+		WeeklySummaryAt: pgtype.Timestamp{Time: weekStart.UTC(), Valid: true},
+	})
+}
+
+// PruneActivity deletes activity older than any period leaderboard needs.
+func (b *MartinGarrixBot) PruneActivity(ctx context.Context) (int, error) {
+	return 0, b.Queries.DeleteActivityBefore(ctx, ActivityHour(time.Now().Add(-activityRetention)))
+}
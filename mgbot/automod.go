@@ -0,0 +1,335 @@
+package mgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+)
+
+// Kinds of automod rule.
+const (
+	AutomodWord         = "word"
+	AutomodRegex        = "regex"
+	AutomodInvites      = "invites"
+	AutomodMassMentions = "mass_mentions"
+	AutomodCaps         = "caps"
+	AutomodLinks        = "links"
+)
+
+// What the automod does about a message breaking a rule. Every action
+// deletes the message, warn and timeout also punish the member.
+const (
+	AutomodDelete  = "delete"
+	AutomodWarn    = "warn"
+	AutomodTimeout = "timeout"
+)
+
+const (
+	// DefaultAutomodTimeout is how long timeout rules without their own
+	// duration time members out for.
+	DefaultAutomodTimeout = 10 * time.Minute
+	// automodCapsMinLetters is the fewest letters a message needs before the
+	// caps rule looks at it, so "OK" and "LOL" are let through.
+	automodCapsMinLetters = 10
+	// automodNoticeDelay is how long the notice of a removed message stays up.
+	automodNoticeDelay = 10 * time.Second
+)
+
+// automodSeverity orders actions so the harshest one of the rules a message
+// breaks is taken.
+var automodSeverity = map[string]int{
+	AutomodDelete:  0,
+	AutomodWarn:    1,
+	AutomodTimeout: 2,
+}
+
+var (
+	invitePattern = regexp.MustCompile(`(?i)(discord\.gg|discord(app)?\.com/invite)/[\w-]+`)
+	linkPattern   = regexp.MustCompile(`(?i)https?://([^\s/?#<>]+)`)
+	// automodPatterns caches compiled word and regex rules by rule type and
+	// pattern.
+	automodPatterns sync.Map
+)
+
+// automodGuild is a guild's automod configuration, cached so messages can be
+// checked without querying the database.
+type automodGuild struct {
+	rules         []db.AutomodRule
+	allowedLinks  []string
+	moderatorRole pgtype.Int8
+}
+
+// AutomodViolation is a rule a message broke.
+type AutomodViolation struct {
+	Rule db.AutomodRule
+	// Reason describes how the rule was broken, for the modlogs.
+	Reason string
+}
+
+// AutomodPattern compiles the pattern of a word or regex rule. Words match
+// whole words and both are case insensitive.
+func AutomodPattern(ruleType, pattern string) (*regexp.Regexp, error) {
+	key := ruleType + ":" + pattern
+	if cached, ok := automodPatterns.Load(key); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+
+	expr := "(?i)" + pattern
+	if ruleType == AutomodWord {
+		expr = `(?i)(^|\W)` + regexp.QuoteMeta(pattern) + `($|\W)`
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	automodPatterns.Store(key, re)
+	return re, nil
+}
+
+// InvalidateAutomod forgets guildID's cached automod configuration, so changes
+// to its rules, allowed links or moderator role apply to the next message.
+func (b *MartinGarrixBot) InvalidateAutomod(guildID snowflake.ID) {
+	b.automodMu.Lock()
+	defer b.automodMu.Unlock()
+
+	delete(b.automodGuilds, guildID)
+	b.automodGeneration++
+}
+
+// automodConfig returns guildID's automod configuration, loading it from the
+// database the first time.
+func (b *MartinGarrixBot) automodConfig(ctx context.Context, guildID snowflake.ID) (automodGuild, error) {
+	b.automodMu.Lock()
+	config, ok := b.automodGuilds[guildID]
+	generation := b.automodGeneration
+	b.automodMu.Unlock()
+	if ok {
+		return config, nil
+	}
+
+	var err error
+	if config.rules, err = b.Queries.GetAutomodRules(ctx, int64(guildID)); err != nil {
+		return config, err
+	}
+	if config.allowedLinks, err = b.Queries.GetAutomodAllowedLinks(ctx, int64(guildID)); err != nil {
+		return config, err
+	}
+	config.moderatorRole, err = b.Queries.GetModeratorRole(ctx, int64(guildID))
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		return config, err
+	}
+
+	b.automodMu.Lock()
+	defer b.automodMu.Unlock()
+	// Not cached if it was invalidated while loading, since it may be stale
+	if b.automodGeneration == generation {
+		b.automodGuilds[guildID] = config
+	}
+	return config, nil
+}
+
+// CheckAutomod returns the rule message breaks with the harshest action, or
+// nil if it breaks none. Members who can manage messages and moderators are
+// exempt.
+func (b *MartinGarrixBot) CheckAutomod(ctx context.Context, guildID snowflake.ID, message discord.Message) (*AutomodViolation, error) {
+	config, err := b.automodConfig(ctx, guildID)
+	if err != nil || len(config.rules) == 0 {
+		return nil, err
+	}
+
+	if b.automodExempt(guildID, message, config.moderatorRole) {
+		return nil, nil
+	}
+
+	var violation *AutomodViolation
+	for _, rule := range config.rules {
+		if violation != nil && automodSeverity[rule.Action] <= automodSeverity[violation.Rule.Action] {
+			continue
+		}
+
+		reason, err := automodMatch(rule, message, config.allowedLinks)
+		if err != nil {
+			slog.Error("Invalid automod rule", slog.Int("rule_id", int(rule.ID)), slog.Any("err", err))
+			continue
+		}
+		if reason != "" {
+			violation = &AutomodViolation{Rule: rule, Reason: reason}
+		}
+	}
+
+	return violation, nil
+}
+
+func (b *MartinGarrixBot) automodExempt(guildID snowflake.ID, message discord.Message, moderatorRole pgtype.Int8) bool {
+	var member discord.Member
+	if message.Member != nil {
+		member = *message.Member
+		member.User = message.Author
+	} else if cached, ok := b.Client.Caches().Member(guildID, message.Author.ID); ok {
+		member = cached
+	} else {
+		return false
+	}
+	member.GuildID = guildID
+
+	if b.Client.Caches().MemberPermissions(member).Has(discord.PermissionManageMessages) {
+		return true
+	}
+
+	for _, roleID := range member.RoleIDs {
+		if moderatorRole.Valid && roleID == snowflake.ID(moderatorRole.Int64) {
+			return true
+		}
+	}
+	return false
+}
+
+// automodMatch returns how message breaks rule, or "" if it doesn't.
+func automodMatch(rule db.AutomodRule, message discord.Message, allowedLinks []string) (string, error) {
+	switch rule.RuleType {
+	case AutomodWord, AutomodRegex:
+		re, err := AutomodPattern(rule.RuleType, rule.Pattern)
+		if err != nil {
+			return "", err
+		}
+		if re.MatchString(message.Content) {
+			return fmt.Sprintf("Matched filter `%s`", rule.Pattern), nil
+		}
+
+	case AutomodInvites:
+		if invitePattern.MatchString(message.Content) {
+			return "Posted an invite link", nil
+		}
+
+	case AutomodMassMentions:
+		mentions := len(message.Mentions) + len(message.MentionRoles)
+		if message.MentionEveryone {
+			mentions++
+		}
+		if mentions >= int(rule.Threshold) {
+			return fmt.Sprintf("Mentioned %d users or roles", mentions), nil
+		}
+
+	case AutomodCaps:
+		letters, upper := 0, 0
+		for _, r := range message.Content {
+			if unicode.IsLetter(r) {
+				letters++
+				if unicode.IsUpper(r) {
+					upper++
+				}
+			}
+		}
+		if letters >= automodCapsMinLetters && upper*100 >= letters*int(rule.Threshold) {
+			return fmt.Sprintf("Message was %d%% capital letters", upper*100/letters), nil
+		}
+
+	case AutomodLinks:
+		for _, match := range linkPattern.FindAllStringSubmatch(message.Content, -1) {
+			if domain := strings.ToLower(match[1]); !linkAllowed(domain, allowedLinks) {
+				return fmt.Sprintf("Posted a link to %s", domain), nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// linkAllowed reports whether domain is one of allowed or a subdomain of one.
+func linkAllowed(domain string, allowed []string) bool {
+	// Ports and credentials aren't part of the domain
+	if i := strings.LastIndex(domain, "@"); i != -1 {
+		domain = domain[i+1:]
+	}
+	if i := strings.Index(domain, ":"); i != -1 {
+		domain = domain[:i]
+	}
+
+	for _, allowedDomain := range allowed {
+		if domain == allowedDomain || strings.HasSuffix(domain, "."+allowedDomain) {
+			return true
+		}
+	}
+	return false
+}
+
+// ApplyAutomod deletes message and takes the action of the rule it broke,
+// logging it as a case.
+func (b *MartinGarrixBot) ApplyAutomod(ctx context.Context, guildID snowflake.ID, message discord.Message, violation AutomodViolation) error {
+	rule := violation.Rule
+	reason := "AutoMod: " + violation.Reason
+	botID := b.Client.ID()
+
+	if err := b.Client.Rest().DeleteMessage(message.ChannelID, message.ID, rest.WithReason(reason)); err != nil {
+		return err
+	}
+
+	// The reason isn't repeated in the channel so banned words aren't either
+	notice := fmt.Sprintf("%s, your message was removed by AutoMod.", discord.UserMention(message.Author.ID))
+
+	switch rule.Action {
+	case AutomodWarn:
+		result, err := b.WarnMember(ctx, guildID, message.Author.ID, botID, reason)
+		if err != nil {
+			return err
+		}
+		notice += " This is a warning."
+		if result.Escalation != nil && result.EscalationErr == nil && result.Escalation.Action == EscalationMute {
+			notice += fmt.Sprintf(" You have been timed out until <t:%d:t> for reaching %d warnings.", result.ExpiresAt.Unix(), result.Warnings)
+		}
+
+	case AutomodTimeout:
+		duration := time.Duration(rule.TimeoutSeconds) * time.Second
+		if duration <= 0 {
+			duration = DefaultAutomodTimeout
+		}
+		until, err := b.MuteMember(ctx, guildID, message.Author.ID, botID, duration, reason)
+		if err != nil {
+			return err
+		}
+		notice += fmt.Sprintf(" You have been timed out until <t:%d:t>.", until.Unix())
+
+	default:
+		if _, err := b.LogModAction(ctx, db.CreateModlogParams{
+			UserID:      int64(message.Author.ID),
+			ModeratorID: int64(botID),
+			GuildID:     int64(guildID),
+			LogType:     "automod",
+			Reason:      pgtype.Text{String: reason, Valid: true},
+			ExpiresAt:   pgtype.Timestamp{Valid: false},
+			Active:      pgtype.Bool{Bool: true, Valid: true},
+		}); err != nil {
+			slog.Error("Failed to create modlog entry", slog.Any("err", err))
+		}
+	}
+
+	sent, err := b.Client.Rest().CreateMessage(message.ChannelID, discord.NewMessageCreateBuilder().
+		SetContent(notice).
+		SetAllowedMentions(&discord.AllowedMentions{Users: []snowflake.ID{message.Author.ID}}).
+		Build(),
+	)
+	if err != nil {
+		return err
+	}
+
+	// The delay blocks, so it is waited out away from the gateway
+	go func() {
+		if err := b.Client.Rest().DeleteMessage(message.ChannelID, sent.ID, rest.WithDelay(automodNoticeDelay)); err != nil {
+			slog.Error("Failed to delete automod notice", slog.Any("err", err))
+		}
+	}()
+	return nil
+}
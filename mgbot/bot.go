@@ -7,6 +7,8 @@ import (
 	"io"
 	"log/slog"
 	"os"
+	"slices"
+	"sync"
 	"time"
 
 	"github.com/disgoorg/disgo"
@@ -17,6 +19,7 @@ import (
 	"github.com/disgoorg/disgo/gateway"
 	"github.com/disgoorg/disgolink/v3/disgolink"
 	"github.com/disgoorg/paginator"
+	"github.com/disgoorg/snowflake/v2"
 	"github.com/gocolly/colly/v2"
 	"github.com/golang-migrate/migrate/v4"
 	migratePgx "github.com/golang-migrate/migrate/v4/database/pgx/v5"
@@ -33,8 +36,14 @@ func New(cfg Config, version string, commit string) *MartinGarrixBot {
 	return &MartinGarrixBot{
 		Cfg:       cfg,
 		Paginator: paginator.New(),
+		Scheduler: NewScheduler(),
+		XpSpam:    NewXpSpamTracker(),
+		Raids:     NewRaidTracker(),
 		Version:   version,
 		Commit:    commit,
+
+		voiceLocks:    make(map[[2]snowflake.ID]*sync.Mutex),
+		automodGuilds: make(map[snowflake.ID]automodGuild),
 	}
 }
 
@@ -42,10 +51,30 @@ type MartinGarrixBot struct {
 	Cfg       Config
 	Client    bot.Client
 	Paginator *paginator.Manager
+	Scheduler *Scheduler
+	XpSpam    *XpSpamTracker
+	Raids     *RaidTracker
 	Version   string
 	Commit    string
 	IsReady   bool
 
+	// readyOnce guards work that should only happen on the first Ready event,
+	// not again after the gateway re-identifies.
+	readyOnce sync.Once
+	// voiceMu guards voiceLocks and voiceOnlineSince. It is never held
+	// across database calls.
+	voiceMu sync.Mutex
+	// voiceLocks serialise crediting each member's voice session between the
+	// voice state listener and the voice_xp job.
+	voiceLocks map[[2]snowflake.ID]*sync.Mutex
+	// voiceOnlineSince is when the bot last connected to the gateway.
+	voiceOnlineSince time.Time
+	// automodMu guards automodGuilds, the cached automod configuration of
+	// each guild, and automodGeneration, which changes on every invalidation.
+	automodMu         sync.Mutex
+	automodGuilds     map[snowflake.ID]automodGuild
+	automodGeneration uint64
+
 	DB             *pgxpool.Pool
 	Queries        *db.Queries
 	YoutubeService *youtube.Service
@@ -57,10 +86,15 @@ type MartinGarrixBot struct {
 	BeatportClient *utils.BeatportClient
 }
 
+// IsOwner reports whether userID is one of the configured bot owners.
+func (b *MartinGarrixBot) IsOwner(userID snowflake.ID) bool {
+	return slices.Contains(b.Cfg.Bot.OwnerIDs, userID)
+}
+
 func (b *MartinGarrixBot) SetupBot(listeners ...bot.EventListener) error {
 	client, err := disgo.New(b.Cfg.Bot.Token,
 		bot.WithGatewayConfigOpts(gateway.WithIntents(gateway.IntentGuilds, gateway.IntentGuildMessages, gateway.IntentMessageContent, gateway.IntentGuildMembers, gateway.IntentGuildVoiceStates)),
-		bot.WithCacheConfigOpts(cache.WithCaches(cache.FlagGuilds, cache.FlagMessages, cache.FlagVoiceStates, cache.FlagMembers)),
+		bot.WithCacheConfigOpts(cache.WithCaches(cache.FlagGuilds, cache.FlagMessages, cache.FlagVoiceStates, cache.FlagMembers, cache.FlagRoles)),
 		bot.WithEventListeners(b.Paginator),
 		bot.WithEventListeners(listeners...),
 	)
@@ -190,6 +224,12 @@ func (b *MartinGarrixBot) OnReady(e *events.Ready) {
 	}
 
 	b.IsReady = true
+	b.MarkVoiceOnline(time.Now().UTC())
+
+	b.readyOnce.Do(func() {
+		b.Scheduler.Start()
+		go b.AutoStartRadio()
+	})
 }
 
 // ensureGuildConfigurations checks if configurations exist for all guilds and creates missing ones
@@ -0,0 +1,352 @@
+package mgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+)
+
+const (
+	// ChannelLockExpiryInterval is how often timed locks are lifted.
+	ChannelLockExpiryInterval = time.Minute
+	// MaxSlowmode is the longest slowmode Discord allows.
+	MaxSlowmode = 6 * time.Hour
+)
+
+// lockPermissions are what locking a channel denies @everyone.
+const lockPermissions = discord.PermissionSendMessages |
+	discord.PermissionSendMessagesInThreads |
+	discord.PermissionCreatePublicThreads |
+	discord.PermissionCreatePrivateThreads |
+	discord.PermissionAddReactions
+
+var (
+	ErrChannelLocked      = errors.New("channel is already locked")
+	ErrChannelNotLocked   = errors.New("channel isn't locked")
+	ErrNoLockdownChannels = errors.New("no lockdown channels are configured")
+	ErrNoLockdown         = errors.New("there is no lockdown to end")
+)
+
+// LockdownResult is the outcome of starting or ending a lockdown.
+type LockdownResult struct {
+	Channels []snowflake.ID
+	// Skipped are channels that were already locked when a lockdown started.
+	Skipped []snowflake.ID
+	Failed  []snowflake.ID
+}
+
+// LockChannel stops @everyone from talking in a channel and logs it as a case.
+// The channel's previous @everyone overwrite is saved so unlocking puts it back
+// exactly. A non-zero duration unlocks the channel automatically once it is up.
+func (b *MartinGarrixBot) LockChannel(ctx context.Context, guildID, channelID, moderatorID snowflake.ID, duration time.Duration, reason string) error {
+	if err := b.lockChannel(ctx, guildID, channelID, moderatorID, unlockTime(duration), false, reason); err != nil {
+		return err
+	}
+
+	summary := fmt.Sprintf("Locked <#%d>", channelID)
+	if duration > 0 {
+		summary += " for " + FormatDuration(duration)
+	}
+	b.logGuildAction(ctx, guildID, moderatorID, "lock", withReason(summary, reason))
+	return nil
+}
+
+// UnlockChannel restores the @everyone overwrite a channel had before it was
+// locked and logs it as a case.
+func (b *MartinGarrixBot) UnlockChannel(ctx context.Context, guildID, channelID, moderatorID snowflake.ID, reason string) error {
+	lock, err := b.Queries.GetChannelLock(ctx, int64(channelID))
+	if errors.Is(err, db.ErrRecordNotFound) || (err == nil && lock.GuildID != int64(guildID)) {
+		return ErrChannelNotLocked
+	} else if err != nil {
+		return err
+	}
+
+	if err := b.unlockChannel(ctx, lock, reason); err != nil {
+		return err
+	}
+
+	b.logGuildAction(ctx, guildID, moderatorID, "unlock", withReason(fmt.Sprintf("Unlocked <#%d>", channelID), reason))
+	return nil
+}
+
+// Lockdown locks every one of the guild's lockdown channels and logs it as a
+// single case. Channels that are already locked are left as they are, so
+// ending the lockdown doesn't unlock them.
+func (b *MartinGarrixBot) Lockdown(ctx context.Context, guildID, moderatorID snowflake.ID, duration time.Duration, reason string) (LockdownResult, error) {
+	var result LockdownResult
+
+	channelIDs, err := b.Queries.GetLockdownChannels(ctx, int64(guildID))
+	if err != nil {
+		return result, err
+	}
+	if len(channelIDs) == 0 {
+		return result, ErrNoLockdownChannels
+	}
+
+	auditReason := "Lockdown"
+	if reason != "" {
+		auditReason += ": " + reason
+	}
+
+	unlockAt := unlockTime(duration)
+	for _, id := range channelIDs {
+		channelID := snowflake.ID(id)
+		err := b.lockChannel(ctx, guildID, channelID, moderatorID, unlockAt, true, auditReason)
+		switch {
+		case errors.Is(err, ErrChannelLocked):
+			result.Skipped = append(result.Skipped, channelID)
+		case err != nil:
+			slog.Error("Failed to lock channel for lockdown",
+				slog.Int64("guild_id", int64(guildID)),
+				slog.Int64("channel_id", id),
+				slog.Any("err", err),
+			)
+			result.Failed = append(result.Failed, channelID)
+		default:
+			result.Channels = append(result.Channels, channelID)
+		}
+	}
+
+	if len(result.Channels) > 0 {
+		summary := fmt.Sprintf("Locked down %d channels", len(result.Channels))
+		if duration > 0 {
+			summary += " for " + FormatDuration(duration)
+		}
+		b.logGuildAction(ctx, guildID, moderatorID, "lockdown", withReason(summary, reason))
+	}
+
+	return result, nil
+}
+
+// EndLockdown unlocks the channels locked by a lockdown and logs it as a
+// single case. Channels locked on their own stay locked.
+func (b *MartinGarrixBot) EndLockdown(ctx context.Context, guildID, moderatorID snowflake.ID, reason string) (LockdownResult, error) {
+	var result LockdownResult
+
+	locks, err := b.Queries.GetGuildChannelLocks(ctx, int64(guildID))
+	if err != nil {
+		return result, err
+	}
+
+	auditReason := "Lockdown ended"
+	if reason != "" {
+		auditReason += ": " + reason
+	}
+
+	for _, lock := range locks {
+		if !lock.Lockdown {
+			continue
+		}
+
+		channelID := snowflake.ID(lock.ChannelID)
+		if err := b.unlockChannel(ctx, lock, auditReason); err != nil {
+			slog.Error("Failed to unlock channel after lockdown",
+				slog.Int64("guild_id", lock.GuildID),
+				slog.Int64("channel_id", lock.ChannelID),
+				slog.Any("err", err),
+			)
+			result.Failed = append(result.Failed, channelID)
+			continue
+		}
+		result.Channels = append(result.Channels, channelID)
+	}
+
+	if len(result.Channels) == 0 && len(result.Failed) == 0 {
+		return result, ErrNoLockdown
+	}
+
+	if len(result.Channels) > 0 {
+		summary := fmt.Sprintf("Ended the lockdown of %d channels", len(result.Channels))
+		b.logGuildAction(ctx, guildID, moderatorID, "unlockdown", withReason(summary, reason))
+	}
+
+	return result, nil
+}
+
+// SetSlowmode sets how long members have to wait between messages in a
+// channel, turning slowmode off for 0, and logs it as a case.
+func (b *MartinGarrixBot) SetSlowmode(ctx context.Context, guildID, channelID, moderatorID snowflake.ID, delay time.Duration, reason string) error {
+	seconds := int(delay / time.Second)
+	_, err := b.Client.Rest().UpdateChannel(channelID,
+		discord.GuildTextChannelUpdate{
+			RateLimitPerUser: &seconds,
+		},
+		rest.WithReason(reason),
+	)
+	if err != nil {
+		return err
+	}
+
+	summary := fmt.Sprintf("Turned off slowmode in <#%d>", channelID)
+	if delay > 0 {
+		summary = fmt.Sprintf("Set slowmode in <#%d> to %s", channelID, FormatDuration(delay))
+	}
+	b.logGuildAction(ctx, guildID, moderatorID, "slowmode", withReason(summary, reason))
+	return nil
+}
+
+// UnlockExpiredChannels unlocks channels whose timed locks are up, logging one
+// case per guild. Like tempbans, locks that expired while the bot was down are
+// lifted on the first run.
+func (b *MartinGarrixBot) UnlockExpiredChannels(ctx context.Context) (int, error) {
+	locks, err := b.Queries.GetExpiredChannelLocks(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	unlocked := make(map[int64][]string)
+	for _, lock := range locks {
+		err := b.unlockChannel(ctx, lock, "Lock expired")
+		var restErr rest.Error
+		if errors.As(err, &restErr) && restErr.Response != nil &&
+			(restErr.Response.StatusCode == http.StatusForbidden || restErr.Code == discordErrUnknownGuild) {
+			// The bot has left the guild or lost the permission to manage the
+			// channel, so retrying won't help
+			slog.Warn("Dropping expired channel lock that can't be lifted",
+				slog.Int64("guild_id", lock.GuildID),
+				slog.Int64("channel_id", lock.ChannelID),
+				slog.Any("err", err),
+			)
+			if err := b.Queries.DeleteChannelLock(ctx, lock.ChannelID); err != nil {
+				slog.Error("Failed to delete channel lock", slog.Any("err", err))
+			}
+			continue
+		}
+		if err != nil {
+			// Kept so it is retried on the next run
+			slog.Error("Failed to unlock expired channel lock",
+				slog.Int64("guild_id", lock.GuildID),
+				slog.Int64("channel_id", lock.ChannelID),
+				slog.Any("err", err),
+			)
+			continue
+		}
+		unlocked[lock.GuildID] = append(unlocked[lock.GuildID], fmt.Sprintf("<#%d>", lock.ChannelID))
+	}
+
+	botID := b.Client.ID()
+	count := 0
+	for guildID, channels := range unlocked {
+		count += len(channels)
+		b.logGuildAction(ctx, snowflake.ID(guildID), botID, "auto-unlock",
+			"Lock expired for "+strings.Join(channels, ", "))
+	}
+
+	return count, nil
+}
+
+func (b *MartinGarrixBot) lockChannel(ctx context.Context, guildID, channelID, moderatorID snowflake.ID, unlockAt pgtype.Timestamp, lockdown bool, reason string) error {
+	_, err := b.Queries.GetChannelLock(ctx, int64(channelID))
+	if err == nil {
+		return ErrChannelLocked
+	} else if !errors.Is(err, db.ErrRecordNotFound) {
+		return err
+	}
+
+	channel, err := b.Client.Rest().GetChannel(channelID)
+	if err != nil {
+		return err
+	}
+	guildChannel, ok := channel.(discord.GuildChannel)
+	if !ok || guildChannel.GuildID() != guildID {
+		return fmt.Errorf("channel %d isn't in this server", channelID)
+	}
+
+	// The @everyone role shares the guild's ID
+	previous, hadOverwrite := guildChannel.PermissionOverwrites().Role(guildID)
+
+	// Saved first so a lock that half succeeds can still be undone
+	created, err := b.Queries.CreateChannelLock(ctx, db.CreateChannelLockParams{
+		ChannelID:     int64(channelID),
+		GuildID:       int64(guildID),
+		HadOverwrite:  hadOverwrite,
+		PreviousAllow: int64(previous.Allow),
+		PreviousDeny:  int64(previous.Deny),
+		LockedBy:      int64(moderatorID),
+		Reason:        pgtype.Text{String: reason, Valid: reason != ""},
+		Lockdown:      lockdown,
+		LockedAt:      pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+		UnlockAt:      unlockAt,
+	})
+	if err != nil {
+		return err
+	}
+	if created == 0 {
+		return ErrChannelLocked
+	}
+
+	allow := previous.Allow.Remove(lockPermissions)
+	deny := previous.Deny.Add(lockPermissions)
+	err = b.Client.Rest().UpdatePermissionOverwrite(channelID, guildID,
+		discord.RolePermissionOverwriteUpdate{
+			Allow: &allow,
+			Deny:  &deny,
+		},
+		rest.WithReason(reason),
+	)
+	if err != nil {
+		if deleteErr := b.Queries.DeleteChannelLock(ctx, int64(channelID)); deleteErr != nil {
+			slog.Error("Failed to delete channel lock", slog.Any("err", deleteErr))
+		}
+		return err
+	}
+
+	return nil
+}
+
+// unlockChannel puts back the @everyone overwrite saved by lock, removing it
+// if the channel had none, and forgets the lock.
+func (b *MartinGarrixBot) unlockChannel(ctx context.Context, lock db.ChannelLock, reason string) error {
+	channelID := snowflake.ID(lock.ChannelID)
+	everyoneID := snowflake.ID(lock.GuildID)
+
+	var err error
+	if lock.HadOverwrite {
+		allow := discord.Permissions(lock.PreviousAllow)
+		deny := discord.Permissions(lock.PreviousDeny)
+		err = b.Client.Rest().UpdatePermissionOverwrite(channelID, everyoneID,
+			discord.RolePermissionOverwriteUpdate{
+				Allow: &allow,
+				Deny:  &deny,
+			},
+			rest.WithReason(reason),
+		)
+	} else {
+		err = b.Client.Rest().DeletePermissionOverwrite(channelID, everyoneID, rest.WithReason(reason))
+	}
+
+	var restErr rest.Error
+	if errors.As(err, &restErr) && restErr.Response != nil && restErr.Response.StatusCode == http.StatusNotFound {
+		// The channel was deleted while locked
+		err = nil
+	}
+	if err != nil {
+		return err
+	}
+
+	return b.Queries.DeleteChannelLock(ctx, lock.ChannelID)
+}
+
+func unlockTime(duration time.Duration) pgtype.Timestamp {
+	if duration <= 0 {
+		return pgtype.Timestamp{Valid: false}
+	}
+	return pgtype.Timestamp{Time: time.Now().UTC().Add(duration), Valid: true}
+}
+
+func withReason(summary, reason string) string {
+	if reason == "" {
+		return summary
+	}
+	return summary + ": " + reason
+}
@@ -0,0 +1,266 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+// adminJobRunTimeout bounds how long /admin jobs run waits for a manual run
+// before replying. The run itself keeps going in the background.
+const adminJobRunTimeout = 5 * time.Minute
+
+var adminJobOption = discord.ApplicationCommandOptionString{
+	Name:         "job",
+	Description:  "The background job",
+	Required:     true,
+	Autocomplete: true,
+}
+
+var admin = discord.SlashCommandCreate{
+	Name:        "admin",
+	Description: "Bot owner and administrator commands",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionSubCommandGroup{
+			Name:        "jobs",
+			Description: "Inspect and control the background fetchers",
+			Options: []discord.ApplicationCommandOptionSubCommand{
+				{
+					Name:        "list",
+					Description: "List every background job with its last run and last error",
+				},
+				{
+					Name:        "run",
+					Description: "Run a background job right now",
+					Options:     []discord.ApplicationCommandOption{adminJobOption},
+				},
+				{
+					Name:        "pause",
+					Description: "Stop a background job from running on its schedule",
+					Options:     []discord.ApplicationCommandOption{adminJobOption},
+				},
+				{
+					Name:        "resume",
+					Description: "Let a paused background job run on its schedule again",
+					Options:     []discord.ApplicationCommandOption{adminJobOption},
+				},
+			},
+		},
+		adminXpGroup,
+		adminCoinsGroup,
+		adminResetUserCommand,
+		adminImportLevelsCommand,
+	},
+}
+
+func AdminHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
+	return func(e *handler.CommandEvent) error {
+		// Bot owners can use this anywhere, otherwise Administrator is required
+		member := e.Member()
+		if !b.IsOwner(e.User().ID) && (member == nil || !member.Permissions.Has(discord.PermissionAdministrator)) {
+			return e.Respond(discord.InteractionResponseTypeCreateMessage,
+				discord.NewMessageCreateBuilder().
+					SetEmbeds(utils.FailureEmbed("Permission Denied",
+						"Only the bot owner and administrators can use admin commands.")).
+					SetEphemeral(true).
+					Build(),
+			)
+		}
+
+		data := e.SlashCommandInteractionData()
+		if data.SubCommandGroupName == nil || *data.SubCommandGroupName != "jobs" {
+			// Everything but jobs works on a member of the server
+			if e.GuildID() == nil {
+				return respondAdminError(e, "Server Only", "This command can only be used in a server.")
+			}
+		}
+
+		switch {
+		case data.SubCommandGroupName == nil:
+			switch *data.SubCommandName {
+			case "reset-user":
+				return handleAdminResetUser(b, e)
+			case "import-levels":
+				return handleAdminImportLevels(b, e)
+			}
+		case *data.SubCommandGroupName == "xp":
+			return handleAdminXp(b, e)
+		case *data.SubCommandGroupName == "coins":
+			return handleAdminCoins(b, e)
+		case *data.SubCommandGroupName == "jobs":
+			switch *data.SubCommandName {
+			case "list":
+				return handleJobsList(b, e)
+			case "run":
+				return handleJobsRun(b, e)
+			case "pause":
+				return handleJobsPause(b, e, true)
+			case "resume":
+				return handleJobsPause(b, e, false)
+			}
+		}
+
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.FailureEmbed("Invalid Command", "Unknown subcommand")).
+				SetEphemeral(true).
+				Build(),
+		)
+	}
+}
+
+func AdminAutocompleteHandler(b *mgbot.MartinGarrixBot) handler.AutocompleteHandler {
+	return func(e *handler.AutocompleteEvent) error {
+		input := strings.ToLower(e.Data.String("job"))
+
+		var choices []discord.AutocompleteChoice
+		for _, job := range b.Scheduler.Status() {
+			if !strings.Contains(job.Name, input) {
+				continue
+			}
+			choices = append(choices, discord.AutocompleteChoiceString{
+				Name:  job.Name,
+				Value: job.Name,
+			})
+		}
+
+		return e.AutocompleteResult(choices)
+	}
+}
+
+func handleJobsList(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	jobs := b.Scheduler.Status()
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle("Background Jobs").
+		SetColor(utils.ColorInfo)
+
+	if len(jobs) == 0 {
+		embed.SetDescription("No background jobs are registered.")
+	}
+
+	for _, job := range jobs {
+		embed.AddField(job.Name, formatJobStatus(job), false)
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed.Build()).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func handleJobsRun(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	name := e.SlashCommandInteractionData().String("job")
+
+	if err := e.DeferCreateMessage(true); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(e.Ctx, adminJobRunTimeout)
+	defer cancel()
+
+	status, err := b.Scheduler.RunNow(ctx, name)
+
+	var embed discord.Embed
+	switch {
+	case errors.Is(err, mgbot.ErrJobNotFound):
+		embed = utils.FailureEmbed("Unknown Job", fmt.Sprintf("There is no background job called `%s`.", name))
+	case errors.Is(err, mgbot.ErrSchedulerNotStarted):
+		embed = utils.FailureEmbed("Not Ready", "Background jobs have not been started yet, try again in a moment.")
+	case errors.Is(err, mgbot.ErrJobAlreadyQueued):
+		embed = utils.FailureEmbed("Already Queued", fmt.Sprintf("`%s` already has a manual run queued.", name))
+	case errors.Is(err, context.DeadlineExceeded):
+		embed = discord.NewEmbedBuilder().
+			SetTitle("Still Running").
+			SetDescription(fmt.Sprintf("`%s` is taking a while. Check `/admin jobs list` for the result.", name)).
+			SetColor(utils.ColorWarning).
+			Build()
+	case err != nil:
+		embed = utils.FailureEmbed("Run Failed", fmt.Sprintf("`%s` failed: %s", name, err.Error()))
+	default:
+		embed = utils.SuccessEmbed("Run Complete",
+			fmt.Sprintf("`%s` finished and found %d new item(s).", name, status.LastItems))
+	}
+
+	_, err = e.UpdateInteractionResponse(discord.NewMessageUpdateBuilder().
+		SetEmbeds(embed).
+		Build())
+	return err
+}
+
+func handleJobsPause(b *mgbot.MartinGarrixBot, e *handler.CommandEvent, pause bool) error {
+	name := e.SlashCommandInteractionData().String("job")
+
+	var err error
+	if pause {
+		err = b.Scheduler.Pause(name)
+	} else {
+		err = b.Scheduler.Resume(name)
+	}
+
+	if err != nil {
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.FailureEmbed("Unknown Job",
+					fmt.Sprintf("There is no background job called `%s`.", name))).
+				SetEphemeral(true).
+				Build(),
+		)
+	}
+
+	title, description := "Job Resumed", fmt.Sprintf("`%s` will run on its schedule again.", name)
+	if pause {
+		title, description = "Job Paused", fmt.Sprintf("`%s` will not run on its schedule until it is resumed. You can still run it with `/admin jobs run`.", name)
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed(title, description)).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func formatJobStatus(job mgbot.JobStatus) string {
+	var sb strings.Builder
+
+	switch {
+	case job.Running:
+		sb.WriteString("**Status:** Running\n")
+	case job.Paused:
+		sb.WriteString("**Status:** Paused\n")
+	default:
+		sb.WriteString("**Status:** Idle\n")
+	}
+
+	sb.WriteString(fmt.Sprintf("**Interval:** %s\n", job.Interval))
+
+	if job.LastRun.IsZero() {
+		sb.WriteString("**Last run:** Never\n")
+	} else {
+		sb.WriteString(fmt.Sprintf("**Last run:** <t:%d:R>\n", job.LastRun.Unix()))
+		sb.WriteString(fmt.Sprintf("**Items found:** %d\n", job.LastItems))
+	}
+
+	if !job.Paused && !job.NextRun.IsZero() {
+		sb.WriteString(fmt.Sprintf("**Next run:** <t:%d:R>\n", job.NextRun.Unix()))
+	}
+
+	if job.LastError != "" {
+		sb.WriteString(fmt.Sprintf("**Last error:** <t:%d:R>\n```%s```", job.LastErrorAt.Unix(), utils.CutString(job.LastError, 500)))
+		if job.Failures > 0 {
+			sb.WriteString(fmt.Sprintf("\n**Consecutive failures:** %d", job.Failures))
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,150 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+// levelImportMaxSize bounds the exports /admin import-levels downloads. MEE6
+// exports are well under this even for large servers.
+const levelImportMaxSize = 25 << 20
+
+// levelImportProgressInterval is the least time between progress edits, so a
+// large import doesn't hit the interaction edit rate limit.
+const levelImportProgressInterval = 2 * time.Second
+
+var adminImportLevelsCommand = discord.ApplicationCommandOptionSubCommand{
+	Name:        "import-levels",
+	Description: "Import levels from a MEE6 or other bot's JSON/CSV export (bot owner only)",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionAttachment{
+			Name:        "file",
+			Description: "The JSON or CSV export with user ids, XP and message counts",
+			Required:    true,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "strategy",
+			Description: "How imported levels combine with members' existing levels",
+			Required:    false,
+			Choices: []discord.ApplicationCommandOptionChoiceString{
+				{Name: "Keep the higher one", Value: mgbot.ImportKeepMax},
+				{Name: "Overwrite", Value: mgbot.ImportOverwrite},
+				{Name: "Add together", Value: mgbot.ImportAdd},
+			},
+		},
+		discord.ApplicationCommandOptionBool{
+			Name:        "dry-run",
+			Description: "Report what would change without writing anything",
+			Required:    false,
+		},
+	},
+}
+
+func handleAdminImportLevels(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	if !b.IsOwner(e.User().ID) {
+		return respondAdminError(e, "Permission Denied", "Only the bot owner can import levels.")
+	}
+
+	data := e.SlashCommandInteractionData()
+	file := data.Attachment("file")
+	strategy := data.String("strategy")
+	if strategy == "" {
+		strategy = mgbot.ImportKeepMax
+	}
+	dryRun := data.Bool("dry-run")
+
+	if file.Size > levelImportMaxSize {
+		return respondAdminError(e, "File Too Large",
+			fmt.Sprintf("Exports can be at most %d MB.", levelImportMaxSize>>20))
+	}
+
+	if err := e.DeferCreateMessage(true); err != nil {
+		return err
+	}
+
+	update := func(embed discord.Embed) {
+		_, _ = e.UpdateInteractionResponse(discord.NewMessageUpdateBuilder().
+			SetEmbeds(embed).
+			Build())
+	}
+
+	records, err := downloadLevelImport(e.Ctx, file.URL)
+	if err != nil {
+		update(utils.FailureEmbed("Import Failed", fmt.Sprintf("Couldn't read `%s`: %s", file.Filename, err.Error())))
+		return nil
+	}
+
+	title := "Importing Levels"
+	if dryRun {
+		title = "Importing Levels (dry run)"
+	}
+
+	var lastProgress time.Time
+	report, err := b.ImportLevels(e.Ctx, int64(*e.GuildID()), records, strategy, dryRun, func(done, total int) {
+		if done < total && time.Since(lastProgress) < levelImportProgressInterval {
+			return
+		}
+		lastProgress = time.Now()
+		update(discord.NewEmbedBuilder().
+			SetTitle(title).
+			SetDescription(fmt.Sprintf("Processed %d of %d members...", done, total)).
+			SetColor(utils.ColorInfo).
+			Build())
+	})
+	if err != nil {
+		message := fmt.Sprintf("Nothing was written: %s", err.Error())
+		if !dryRun {
+			message = fmt.Sprintf("Stopped after importing %d of %d members: %s",
+				report.Created+report.Updated+report.Unchanged, report.Total, err.Error())
+		}
+		update(utils.FailureEmbed("Import Failed", message))
+		return nil
+	}
+	if !dryRun {
+		go b.SyncImportedLevelRoles(context.Background(), *e.GuildID(), report.Levels)
+	}
+
+	description := fmt.Sprintf("Imported %d members from `%s`.", report.Total, file.Filename)
+	if dryRun {
+		description = fmt.Sprintf("Nothing was written. Importing the %d members in `%s` would make these changes.", report.Total, file.Filename)
+	}
+
+	update(discord.NewEmbedBuilder().
+		SetTitle(title).
+		SetDescription(description).
+		SetColor(utils.ColorSuccess).
+		AddField("Created", fmt.Sprint(report.Created), true).
+		AddField("Updated", fmt.Sprint(report.Updated), true).
+		AddField("Unchanged", fmt.Sprint(report.Unchanged), true).
+		AddField("Strategy", strategy, true).
+		Build())
+	return nil
+}
+
+func downloadLevelImport(ctx context.Context, url string) ([]mgbot.LevelImportRecord, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(resp.Status)
+	}
+
+	return mgbot.ParseLevelImport(io.LimitReader(resp.Body, levelImportMaxSize))
+}
@@ -0,0 +1,375 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/json"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+const (
+	// adminMaxXp keeps corrected XP well inside the range of total_xp.
+	adminMaxXp = 100_000_000
+	// adminMaxCoins keeps corrected balances well inside the range of in_hand.
+	adminMaxCoins = 1_000_000_000_000
+	// adminReasonMaxLength leaves room in the modlog reason for the
+	// description of the change it is appended to.
+	adminReasonMaxLength = mgbot.ModlogReasonMaxLength - 150
+)
+
+var adminXpGroup = adminAdjustGroup("xp", "XP", adminMaxXp)
+
+var adminCoinsGroup = adminAdjustGroup("coins", "coins in hand", adminMaxCoins)
+
+var adminResetUserCommand = discord.ApplicationCommandOptionSubCommand{
+	Name:        "reset-user",
+	Description: "Reset a member's XP, messages and coins to zero",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionUser{
+			Name:        "user",
+			Description: "The member to reset",
+			Required:    true,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "reason",
+			Description: "Why the member is being reset",
+			Required:    false,
+			MaxLength:   json.Ptr(adminReasonMaxLength),
+		},
+	},
+}
+
+// adminAdjustGroup builds the set, add and remove subcommands for correcting
+// one of a member's balances.
+func adminAdjustGroup(name, unit string, maxValue int) discord.ApplicationCommandOptionSubCommandGroup {
+	options := func(verb string, minValue int) []discord.ApplicationCommandOption {
+		return []discord.ApplicationCommandOption{
+			discord.ApplicationCommandOptionUser{
+				Name:        "user",
+				Description: "The member to correct",
+				Required:    true,
+			},
+			discord.ApplicationCommandOptionInt{
+				Name:        "amount",
+				Description: fmt.Sprintf("How much %s to %s", unit, verb),
+				Required:    true,
+				MinValue:    json.Ptr(minValue),
+				MaxValue:    json.Ptr(maxValue),
+			},
+			discord.ApplicationCommandOptionString{
+				Name:        "reason",
+				Description: "Why the correction is being made",
+				Required:    false,
+				MaxLength:   json.Ptr(adminReasonMaxLength),
+			},
+		}
+	}
+
+	return discord.ApplicationCommandOptionSubCommandGroup{
+		Name:        name,
+		Description: fmt.Sprintf("Correct a member's %s", unit),
+		Options: []discord.ApplicationCommandOptionSubCommand{
+			{
+				Name:        "set",
+				Description: fmt.Sprintf("Set a member's %s", unit),
+				Options:     options("set", 0),
+			},
+			{
+				Name:        "add",
+				Description: fmt.Sprintf("Give a member %s", unit),
+				Options:     options("add", 1),
+			},
+			{
+				Name:        "remove",
+				Description: fmt.Sprintf("Take %s from a member", unit),
+				Options:     options("remove", 1),
+			},
+		},
+	}
+}
+
+// adminAdjustment is a correction made with /admin xp or /admin coins.
+type adminAdjustment struct {
+	action string
+	before int64
+	after  int64
+}
+
+// newAdminAdjustment applies action to before, returning an error describing
+// why the result is out of range if it isn't between 0 and limit.
+func newAdminAdjustment(action string, before, amount, limit int64, unit string) (adminAdjustment, error) {
+	after := amount
+	switch action {
+	case "add":
+		after = before + amount
+	case "remove":
+		after = before - amount
+	}
+
+	if after < 0 {
+		return adminAdjustment{}, fmt.Errorf("they only have %d %s, so %d can't be removed", before, unit, amount)
+	}
+	if after > limit {
+		return adminAdjustment{}, fmt.Errorf("that would give them %d %s, more than the limit of %d", after, unit, limit)
+	}
+
+	return adminAdjustment{action: action, before: before, after: after}, nil
+}
+
+func (a adminAdjustment) String(unit string) string {
+	return fmt.Sprintf("%d %s -> %d %s", a.before, unit, a.after, unit)
+}
+
+// errAdminInvalid wraps validation errors so they are shown to the admin
+// instead of being reported as a database failure.
+type errAdminInvalid struct{ err error }
+
+func (e errAdminInvalid) Error() string { return e.err.Error() }
+
+func handleAdminXp(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	target := data.User("user")
+	reason := data.String("reason")
+	guildID := *e.GuildID()
+
+	if target.Bot {
+		return respondAdminError(e, "Invalid User", "Bots don't earn XP.")
+	}
+
+	var adjustment adminAdjustment
+	err := adminUpdateUser(e.Ctx, b, guildID, target.ID, e.User().ID, reason, func(q *db.Queries, user db.User) (string, string, error) {
+		var err error
+		adjustment, err = newAdminAdjustment(*data.SubCommandName, int64(user.TotalXp.Int32), int64(data.Int("amount")), adminMaxXp, "XP")
+		if err != nil {
+			return "", "", errAdminInvalid{err}
+		}
+
+		err = q.SetXp(e.Ctx, db.SetXpParams{
+			ID:      int64(target.ID),
+			GuildID: int64(guildID),
+			TotalXp: pgtype.Int4{Int32: int32(adjustment.after), Valid: true},
+		})
+		return "xp_" + adjustment.action, adjustment.String("XP"), err
+	})
+	if err != nil {
+		return respondAdminUpdateError(e, err)
+	}
+
+	levelBefore := utils.GetUserLevelData(int32(adjustment.before)).Lvl
+	levelAfter := utils.GetUserLevelData(int32(adjustment.after)).Lvl
+	if levelAfter != levelBefore {
+		if err := b.SyncLevelRoles(e.Ctx, guildID, target.ID, levelAfter); err != nil {
+			slog.Error("Failed to update level roles", slog.Any("err", err))
+		}
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle("XP Updated").
+		SetDescription(fmt.Sprintf("%s's XP has been corrected.", discord.UserMention(target.ID))).
+		SetColor(utils.ColorSuccess).
+		AddField("Before", fmt.Sprintf("%d XP (level %d)", adjustment.before, levelBefore), true).
+		AddField("After", fmt.Sprintf("%d XP (level %d)", adjustment.after, levelAfter), true)
+	if reason != "" {
+		embed.AddField("Reason", reason, false)
+	}
+
+	return respondAdminEmbed(e, embed.Build())
+}
+
+func handleAdminCoins(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	target := data.User("user")
+	reason := data.String("reason")
+	guildID := *e.GuildID()
+
+	if target.Bot {
+		return respondAdminError(e, "Invalid User", "Bots don't have coins.")
+	}
+
+	var adjustment adminAdjustment
+	err := adminUpdateUser(e.Ctx, b, guildID, target.ID, e.User().ID, reason, func(q *db.Queries, user db.User) (string, string, error) {
+		var err error
+		adjustment, err = newAdminAdjustment(*data.SubCommandName, user.InHand.Int64, int64(data.Int("amount")), adminMaxCoins, "coins in hand")
+		if err != nil {
+			return "", "", errAdminInvalid{err}
+		}
+
+		change := adjustment.after - adjustment.before
+		if err := q.AddCoins(e.Ctx, db.AddCoinsParams{
+			ID:      int64(target.ID),
+			GuildID: int64(guildID),
+			InHand:  pgtype.Int8{Int64: change, Valid: true},
+		}); err != nil {
+			return "", "", err
+		}
+
+		err = mgbot.RecordCoinTransactions(e.Ctx, q, db.CreateCoinTransactionParams{
+			GuildID:        int64(guildID),
+			UserID:         int64(target.ID),
+			Kind:           mgbot.CoinTxAdminGrant,
+			InHandChange:   change,
+			CounterpartyID: pgtype.Int8{Int64: int64(e.User().ID), Valid: true},
+			Note:           pgtype.Text{String: reason, Valid: reason != ""},
+		})
+		return "coins_" + adjustment.action, adjustment.String("coins in hand"), err
+	})
+	if err != nil {
+		return respondAdminUpdateError(e, err)
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle("Coins Updated").
+		SetDescription(fmt.Sprintf("%s's coins in hand have been corrected.", discord.UserMention(target.ID))).
+		SetColor(utils.ColorSuccess).
+		AddField("Before", fmt.Sprintf("%d coins", adjustment.before), true).
+		AddField("After", fmt.Sprintf("%d coins", adjustment.after), true)
+	if reason != "" {
+		embed.AddField("Reason", reason, false)
+	}
+
+	return respondAdminEmbed(e, embed.Build())
+}
+
+func handleAdminResetUser(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	target := data.User("user")
+	reason := data.String("reason")
+	guildID := *e.GuildID()
+
+	var before db.User
+	err := adminUpdateUser(e.Ctx, b, guildID, target.ID, e.User().ID, reason, func(q *db.Queries, user db.User) (string, string, error) {
+		before = user
+		change := fmt.Sprintf("%d XP, %d messages, %d coins in hand and %d in the safe reset to 0",
+			user.TotalXp.Int32, user.MessagesSent.Int32, user.InHand.Int64, user.GarrixCoins.Int64)
+
+		if err := q.ResetUser(e.Ctx, db.ResetUserParams{ID: int64(target.ID), GuildID: int64(guildID)}); err != nil {
+			return "", "", err
+		}
+
+		if user.InHand.Int64 == 0 && user.GarrixCoins.Int64 == 0 {
+			return "reset_user", change, nil
+		}
+		err := mgbot.RecordCoinTransactions(e.Ctx, q, db.CreateCoinTransactionParams{
+			GuildID:        int64(guildID),
+			UserID:         int64(target.ID),
+			Kind:           mgbot.CoinTxAdminReset,
+			InHandChange:   -user.InHand.Int64,
+			SafeChange:     -user.GarrixCoins.Int64,
+			CounterpartyID: pgtype.Int8{Int64: int64(e.User().ID), Valid: true},
+			Note:           pgtype.Text{String: reason, Valid: reason != ""},
+		})
+		return "reset_user", change, err
+	})
+	if err != nil {
+		return respondAdminUpdateError(e, err)
+	}
+
+	if utils.GetUserLevelData(before.TotalXp.Int32).Lvl > 0 {
+		if err := b.SyncLevelRoles(e.Ctx, guildID, target.ID, 0); err != nil {
+			slog.Error("Failed to update level roles", slog.Any("err", err))
+		}
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle("User Reset").
+		SetDescription(fmt.Sprintf("%s's progress has been reset.", discord.UserMention(target.ID))).
+		SetColor(utils.ColorSuccess).
+		AddField("Before", fmt.Sprintf("%d XP (level %d)\n%d messages\n%d coins in hand\n%d coins in the safe",
+			before.TotalXp.Int32, utils.GetUserLevelData(before.TotalXp.Int32).Lvl, before.MessagesSent.Int32,
+			before.InHand.Int64, before.GarrixCoins.Int64), true).
+		AddField("After", "0 XP (level 0)\n0 messages\n0 coins in hand\n0 coins in the safe", true)
+	if reason != "" {
+		embed.AddField("Reason", reason, false)
+	}
+
+	return respondAdminEmbed(e, embed.Build())
+}
+
+// adminUpdateUser locks the member's row and runs update on it, then records
+// the change in the modlogs in the same transaction and posts it to the modlog
+// channel. update returns the log type and a description of the change.
+func adminUpdateUser(ctx context.Context, b *mgbot.MartinGarrixBot, guildID, userID, moderatorID snowflake.ID, reason string, update func(q *db.Queries, user db.User) (string, string, error)) error {
+	tx, err := b.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	q := b.Queries.WithTx(tx)
+
+	if err := q.EnsureUser(ctx, db.EnsureUserParams{ID: int64(userID), GuildID: int64(guildID)}); err != nil {
+		return err
+	}
+
+	user, err := q.GetUserForUpdate(ctx, db.GetUserForUpdateParams{ID: int64(userID), GuildID: int64(guildID)})
+	if err != nil {
+		return err
+	}
+
+	logType, change, err := update(q, user)
+	if err != nil {
+		return err
+	}
+
+	logReason := change
+	if reason != "" {
+		logReason += ": " + reason
+	}
+
+	modlog, err := q.CreateModlog(ctx, db.CreateModlogParams{
+		UserID:      int64(userID),
+		ModeratorID: int64(moderatorID),
+		GuildID:     int64(guildID),
+		LogType:     logType,
+		Reason:      pgtype.Text{String: logReason, Valid: true},
+		ExpiresAt:   pgtype.Timestamp{Valid: false},
+		Active:      pgtype.Bool{Bool: true, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	b.PostModlog(ctx, modlog)
+	return nil
+}
+
+func respondAdminUpdateError(e *handler.CommandEvent, err error) error {
+	var invalid errAdminInvalid
+	if errors.As(err, &invalid) {
+		return respondAdminError(e, "Invalid Amount", invalid.Error())
+	}
+
+	slog.Error("Failed to update user", slog.Any("err", err))
+	return respondAdminError(e, "Update Failed", "Something went wrong while updating the member, nothing was changed.")
+}
+
+func respondAdminError(e *handler.CommandEvent, title, description string) error {
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.FailureEmbed(title, description)).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func respondAdminEmbed(e *handler.CommandEvent, embed discord.Embed) error {
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed).
+			SetEphemeral(true).
+			Build(),
+	)
+}
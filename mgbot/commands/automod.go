@@ -0,0 +1,472 @@
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/json"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+// automodMaxTimeout is the longest timeout Discord allows.
+const automodMaxTimeout = 28 * 24 * time.Hour
+
+var automodRuleLabels = map[string]string{
+	mgbot.AutomodWord:         "Banned word",
+	mgbot.AutomodRegex:        "Regex",
+	mgbot.AutomodInvites:      "Invite links",
+	mgbot.AutomodMassMentions: "Mass mentions",
+	mgbot.AutomodCaps:         "Caps",
+	mgbot.AutomodLinks:        "Links",
+}
+
+var automodActionOptions = []discord.ApplicationCommandOption{
+	discord.ApplicationCommandOptionString{
+		Name:        "action",
+		Description: "What to do about messages breaking the rule",
+		Required:    true,
+		Choices: []discord.ApplicationCommandOptionChoiceString{
+			{Name: "Delete the message", Value: mgbot.AutomodDelete},
+			{Name: "Delete and warn", Value: mgbot.AutomodWarn},
+			{Name: "Delete and time out", Value: mgbot.AutomodTimeout},
+		},
+	},
+	discord.ApplicationCommandOptionString{
+		Name:        "timeout",
+		Description: "How long to time out for (e.g., 10m, 1h, 1d), defaults to 10 minutes",
+		Required:    false,
+	},
+}
+
+var automodDomainOption = discord.ApplicationCommandOptionString{
+	Name:        "domain",
+	Description: "The domain, e.g. youtube.com. Its subdomains are included",
+	Required:    true,
+}
+
+var automod = discord.SlashCommandCreate{
+	Name:        "automod",
+	Description: "Configure automatic message filtering",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "filter",
+			Description: "Filter messages containing a word or matching a regex",
+			Options: append([]discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionString{
+					Name:        "pattern",
+					Description: "The word, or a regex if regex is set",
+					Required:    true,
+					MaxLength:   json.Ptr(200),
+				},
+				discord.ApplicationCommandOptionBool{
+					Name:        "regex",
+					Description: "Treat the pattern as a regex instead of a whole word",
+					Required:    false,
+				},
+			}, automodActionOptions...),
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "invites",
+			Description: "Filter Discord invite links",
+			Options:     automodActionOptions,
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "mass-mentions",
+			Description: "Filter messages mentioning too many users and roles",
+			Options: append([]discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionInt{
+					Name:        "limit",
+					Description: "How many mentions a message can't reach",
+					Required:    true,
+					MinValue:    json.Ptr(2),
+					MaxValue:    json.Ptr(50),
+				},
+			}, automodActionOptions...),
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "caps",
+			Description: "Filter messages mostly in capital letters",
+			Options: append([]discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionInt{
+					Name:        "percent",
+					Description: "The share of capital letters a message can't reach",
+					Required:    true,
+					MinValue:    json.Ptr(50),
+					MaxValue:    json.Ptr(100),
+				},
+			}, automodActionOptions...),
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "links",
+			Description: "Filter links to domains that aren't allowed with /automod allow-link",
+			Options:     automodActionOptions,
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "allow-link",
+			Description: "Let links to a domain through the links filter",
+			Options:     []discord.ApplicationCommandOption{automodDomainOption},
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "disallow-link",
+			Description: "Stop letting links to a domain through the links filter",
+			Options:     []discord.ApplicationCommandOption{automodDomainOption},
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "remove",
+			Description: "Remove an automod rule",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionInt{
+					Name:         "rule",
+					Description:  "The rule to remove",
+					Required:     true,
+					Autocomplete: true,
+				},
+			},
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "list",
+			Description: "List the automod rules and allowed links",
+		},
+	},
+}
+
+func AutomodHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
+	return func(e *handler.CommandEvent) error {
+		if !e.Member().Permissions.Has(discord.PermissionAdministrator) {
+			return respondConfigError(e, "Permission Denied", "Only administrators can configure the automod.")
+		}
+		// Every change applies to the next message checked
+		defer b.InvalidateAutomod(*e.GuildID())
+
+		data := e.SlashCommandInteractionData()
+		switch *data.SubCommandName {
+		case "filter":
+			return handleAutomodFilter(b, e)
+		case "invites":
+			return handleAutomodSetRule(b, e, mgbot.AutomodInvites, 0)
+		case "mass-mentions":
+			return handleAutomodSetRule(b, e, mgbot.AutomodMassMentions, int32(data.Int("limit")))
+		case "caps":
+			return handleAutomodSetRule(b, e, mgbot.AutomodCaps, int32(data.Int("percent")))
+		case "links":
+			return handleAutomodSetRule(b, e, mgbot.AutomodLinks, 0)
+		case "allow-link", "disallow-link":
+			return handleAutomodLink(b, e)
+		case "remove":
+			return handleAutomodRemove(b, e)
+		case "list":
+			return handleAutomodList(b, e)
+		}
+
+		return respondConfigError(e, "Invalid Command", "Unknown subcommand")
+	}
+}
+
+func AutomodAutocompleteHandler(b *mgbot.MartinGarrixBot) handler.AutocompleteHandler {
+	return func(e *handler.AutocompleteEvent) error {
+		rules, err := b.Queries.GetAutomodRules(e.Ctx, int64(*e.GuildID()))
+		if err != nil {
+			slog.Error("Failed to get automod rules", slog.Any("err", err))
+			return e.AutocompleteResult(nil)
+		}
+
+		input := strings.ToLower(e.Data.String("rule"))
+		var choices []discord.AutocompleteChoice
+		for _, rule := range rules {
+			name := utils.CutString(fmt.Sprintf("#%d %s", rule.ID, formatAutomodRule(rule)), 100)
+			if !strings.Contains(strings.ToLower(name), input) {
+				continue
+			}
+			choices = append(choices, discord.AutocompleteChoiceInt{
+				Name:  name,
+				Value: int(rule.ID),
+			})
+			if len(choices) == 25 {
+				break
+			}
+		}
+
+		return e.AutocompleteResult(choices)
+	}
+}
+
+// automodAction reads the action options, returning the timeout in seconds.
+func automodAction(data discord.SlashCommandInteractionData) (string, int32, error) {
+	action := data.String("action")
+	timeout, ok := data.OptString("timeout")
+	if !ok {
+		return action, 0, nil
+	}
+
+	if action != mgbot.AutomodTimeout {
+		return "", 0, fmt.Errorf("a timeout duration only applies to the timeout action")
+	}
+
+	duration, err := parseDuration(timeout)
+	if err != nil {
+		return "", 0, err
+	}
+	if duration > automodMaxTimeout {
+		return "", 0, fmt.Errorf("timeouts can be at most 28 days")
+	}
+	return action, int32(duration.Seconds()), nil
+}
+
+func handleAutomodFilter(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	pattern := strings.TrimSpace(data.String("pattern"))
+
+	ruleType := mgbot.AutomodWord
+	if data.Bool("regex") {
+		ruleType = mgbot.AutomodRegex
+	}
+
+	if pattern == "" {
+		return respondConfigError(e, "Invalid Filter", "The pattern can't be empty.")
+	}
+	re, err := mgbot.AutomodPattern(ruleType, pattern)
+	if err != nil {
+		return respondConfigError(e, "Invalid Regex", fmt.Sprintf("`%s` isn't a valid regex: %s", pattern, err.Error()))
+	}
+	if re.MatchString("") {
+		return respondConfigError(e, "Invalid Regex", fmt.Sprintf("`%s` matches an empty message, so it would remove every message.", pattern))
+	}
+
+	action, timeout, err := automodAction(data)
+	if err != nil {
+		return respondConfigError(e, "Invalid Action", fmt.Sprintf("Couldn't use that action: %s.", err.Error()))
+	}
+
+	rule, err := b.Queries.CreateAutomodRule(e.Ctx, db.CreateAutomodRuleParams{
+		GuildID:        int64(*e.GuildID()),
+		RuleType:       ruleType,
+		Pattern:        pattern,
+		Action:         action,
+		TimeoutSeconds: timeout,
+		CreatedAt:      pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		slog.Error("Failed to create automod rule", slog.Any("err", err))
+		return respondConfigError(e, "Configuration Failed", "Failed to add the filter.")
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Filter Added", fmt.Sprintf("Rule #%d: %s", rule.ID, formatAutomodRule(rule)))).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+// handleAutomodSetRule sets the guild's rule of ruleType, replacing the one it
+// had. Unlike filters, a guild has at most one of each of these rules.
+func handleAutomodSetRule(b *mgbot.MartinGarrixBot, e *handler.CommandEvent, ruleType string, threshold int32) error {
+	action, timeout, err := automodAction(e.SlashCommandInteractionData())
+	if err != nil {
+		return respondConfigError(e, "Invalid Action", fmt.Sprintf("Couldn't use that action: %s.", err.Error()))
+	}
+
+	guildID := int64(*e.GuildID())
+
+	tx, err := b.DB.Begin(e.Ctx)
+	if err != nil {
+		slog.Error("Failed to begin transaction", slog.Any("err", err))
+		return respondConfigError(e, "Configuration Failed", "Failed to update the automod.")
+	}
+	defer tx.Rollback(e.Ctx)
+
+	q := b.Queries.WithTx(tx)
+	if err := q.DeleteAutomodRulesOfType(e.Ctx, db.DeleteAutomodRulesOfTypeParams{
+		GuildID:  guildID,
+		RuleType: ruleType,
+	}); err != nil {
+		slog.Error("Failed to replace automod rule", slog.Any("err", err))
+		return respondConfigError(e, "Configuration Failed", "Failed to update the automod.")
+	}
+
+	rule, err := q.CreateAutomodRule(e.Ctx, db.CreateAutomodRuleParams{
+		GuildID:        guildID,
+		RuleType:       ruleType,
+		Threshold:      threshold,
+		Action:         action,
+		TimeoutSeconds: timeout,
+		CreatedAt:      pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		slog.Error("Failed to create automod rule", slog.Any("err", err))
+		return respondConfigError(e, "Configuration Failed", "Failed to update the automod.")
+	}
+
+	if err := tx.Commit(e.Ctx); err != nil {
+		slog.Error("Failed to commit automod rule", slog.Any("err", err))
+		return respondConfigError(e, "Configuration Failed", "Failed to update the automod.")
+	}
+
+	description := fmt.Sprintf("Rule #%d: %s", rule.ID, formatAutomodRule(rule))
+	if ruleType == mgbot.AutomodLinks {
+		description += "\nLet domains through with `/automod allow-link`."
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Automod Updated", description)).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func handleAutomodLink(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	domain := normalizeDomain(data.String("domain"))
+	if domain == "" || !strings.Contains(domain, ".") {
+		return respondConfigError(e, "Invalid Domain", "Give a domain like `youtube.com`.")
+	}
+
+	guildID := int64(*e.GuildID())
+
+	if *data.SubCommandName == "allow-link" {
+		if err := b.Queries.AddAutomodAllowedLink(e.Ctx, db.AddAutomodAllowedLinkParams{
+			GuildID: guildID,
+			Domain:  domain,
+		}); err != nil {
+			slog.Error("Failed to allow automod link", slog.Any("err", err))
+			return respondConfigError(e, "Configuration Failed", "Failed to allow the domain.")
+		}
+
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.SuccessEmbed("Link Allowed", fmt.Sprintf("Links to `%s` and its subdomains get through the links filter.", domain))).
+				SetEphemeral(true).
+				Build(),
+		)
+	}
+
+	removed, err := b.Queries.DeleteAutomodAllowedLink(e.Ctx, db.DeleteAutomodAllowedLinkParams{
+		GuildID: guildID,
+		Domain:  domain,
+	})
+	if err != nil {
+		slog.Error("Failed to disallow automod link", slog.Any("err", err))
+		return respondConfigError(e, "Configuration Failed", "Failed to disallow the domain.")
+	}
+	if removed == 0 {
+		return respondConfigError(e, "Not Found", fmt.Sprintf("`%s` isn't an allowed domain.", domain))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Link Disallowed", fmt.Sprintf("Links to `%s` are filtered again.", domain))).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func handleAutomodRemove(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	ruleID := e.SlashCommandInteractionData().Int("rule")
+
+	removed, err := b.Queries.DeleteAutomodRule(e.Ctx, db.DeleteAutomodRuleParams{
+		ID:      int32(ruleID),
+		GuildID: int64(*e.GuildID()),
+	})
+	if err != nil {
+		slog.Error("Failed to remove automod rule", slog.Any("err", err))
+		return respondConfigError(e, "Configuration Failed", "Failed to remove the rule.")
+	}
+	if removed == 0 {
+		return respondConfigError(e, "Not Found", fmt.Sprintf("There is no automod rule #%d.", ruleID))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Rule Removed", fmt.Sprintf("Automod rule #%d has been removed.", ruleID))).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func handleAutomodList(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	guildID := int64(*e.GuildID())
+
+	rules, err := b.Queries.GetAutomodRules(e.Ctx, guildID)
+	if err != nil {
+		slog.Error("Failed to get automod rules", slog.Any("err", err))
+		return respondConfigError(e, "Error", "Failed to fetch the automod rules.")
+	}
+
+	links, err := b.Queries.GetAutomodAllowedLinks(e.Ctx, guildID)
+	if err != nil {
+		slog.Error("Failed to get automod allowed links", slog.Any("err", err))
+		return respondConfigError(e, "Error", "Failed to fetch the automod rules.")
+	}
+
+	var sb strings.Builder
+	for _, rule := range rules {
+		sb.WriteString(fmt.Sprintf("**#%d** %s\n", rule.ID, formatAutomodRule(rule)))
+	}
+	if len(rules) == 0 {
+		sb.WriteString("No rules, the automod is off.")
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle("Automod Rules").
+		SetDescription(utils.CutString(sb.String(), 4000)).
+		SetColor(utils.ColorInfo).
+		SetFooterText("Moderators and members who can manage messages are exempt")
+
+	if len(links) > 0 {
+		embed.AddField("Allowed Links", utils.CutString("`"+strings.Join(links, "`, `")+"`", 1024), false)
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed.Build()).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func formatAutomodRule(rule db.AutomodRule) string {
+	label, ok := automodRuleLabels[rule.RuleType]
+	if !ok {
+		label = rule.RuleType
+	}
+
+	var condition string
+	switch rule.RuleType {
+	case mgbot.AutomodWord, mgbot.AutomodRegex:
+		condition = fmt.Sprintf(" `%s`", rule.Pattern)
+	case mgbot.AutomodMassMentions:
+		condition = fmt.Sprintf(" of %d or more", rule.Threshold)
+	case mgbot.AutomodCaps:
+		condition = fmt.Sprintf(" of %d%% or more", rule.Threshold)
+	}
+
+	action := rule.Action
+	if rule.Action == mgbot.AutomodTimeout {
+		seconds := rule.TimeoutSeconds
+		if seconds <= 0 {
+			seconds = int32(mgbot.DefaultAutomodTimeout.Seconds())
+		}
+		action = fmt.Sprintf("timeout for %s", mgbot.FormatDuration(time.Duration(seconds)*time.Second))
+	}
+
+	return fmt.Sprintf("%s%s: %s", label, condition, action)
+}
+
+// normalizeDomain turns a pasted URL or domain into a bare lowercase domain.
+func normalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.TrimPrefix(domain, "https://")
+	domain = strings.TrimPrefix(domain, "http://")
+	if i := strings.IndexAny(domain, "/?#:"); i != -1 {
+		domain = domain[:i]
+	}
+	return strings.TrimPrefix(domain, "www.")
+}
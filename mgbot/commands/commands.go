@@ -17,6 +17,11 @@ var Commands = []discord.ApplicationCommandCreate{
 	withdraw,
 	deposit,
 	give,
+	rob,
+	daily,
+	weekly,
+	transactions,
+	shop,
 	leaderboard,
 	links,
 	rank,
@@ -24,7 +29,10 @@ var Commands = []discord.ApplicationCommandCreate{
 	//whois,
 	version,
 	moderation,
+	automod,
 	config,
+	admin,
+	tag,
 }
 
 func SetupHandlers(b *mgbot.MartinGarrixBot) *handler.Mux {
@@ -37,10 +45,18 @@ func SetupHandlers(b *mgbot.MartinGarrixBot) *handler.Mux {
 	rootHandler.Command("/withdraw", WithdrawHandler(b))
 	rootHandler.Command("/deposit", DepositHandler(b))
 	rootHandler.Command("/give", GiveHandler(b))
+	rootHandler.Command("/rob", RobHandler(b))
+	rootHandler.Command("/daily", DailyHandler(b))
+	rootHandler.Command("/weekly", WeeklyHandler(b))
+	rootHandler.Command("/transactions", TransactionsHandler(b))
+	rootHandler.Command("/shop", ShopHandler(b))
+	rootHandler.Autocomplete("/shop", ShopAutocompleteHandler(b))
 
 	rootHandler.Command("/rank", RankHandler(b))
-	// BUG: This is not being recognized
+	rootHandler.Autocomplete("/rank", RankAutocompleteHandler(b))
+	rootHandler.Component("/rank-theme/save/{background}/{accent}/{bar}", RankThemeSaveHandler(b))
 	rootHandler.Command("/leaderboard", LeaderboardHandler(b))
+	rootHandler.Component("/leaderboard/{category}/{period}/{user}/{action}/{page}", LeaderboardPageHandler(b))
 
 	rootHandler.Command("/links", LinksHandler(b))
 	rootHandler.Autocomplete("/links", LinksAutocompleteHandler(b))
@@ -53,6 +69,15 @@ func SetupHandlers(b *mgbot.MartinGarrixBot) *handler.Mux {
 
 	rootHandler.Command("/config", ConfigHandler(b))
 
+	rootHandler.Command("/automod", AutomodHandler(b))
+	rootHandler.Autocomplete("/automod", AutomodAutocompleteHandler(b))
+
+	rootHandler.Command("/tag", TagHandler(b))
+	rootHandler.Autocomplete("/tag", TagAutocompleteHandler(b))
+
+	rootHandler.Command("/admin", AdminHandler(b))
+	rootHandler.Autocomplete("/admin", AdminAutocompleteHandler(b))
+
 	fun := handler.New()
 	fun.Command("/8ball", EightBallHandler)
 	fun.Command("/lyrics", LyricsHandler(b))
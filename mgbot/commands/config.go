@@ -2,15 +2,26 @@ package commands
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/json"
 	"github.com/jackc/pgx/v5/pgtype"
 	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
 	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
 	"github.com/milindmadhukar/MartinGarrixBot/utils"
 )
 
+var levelRoleLevelOption = discord.ApplicationCommandOptionInt{
+	Name:        "level",
+	Description: "The level",
+	Required:    true,
+	MinValue:    json.Ptr(1),
+	MaxValue:    json.Ptr(1000),
+}
+
 var config = discord.SlashCommandCreate{
 	Name:        "config",
 	Description: "Configure bot settings for this server",
@@ -26,6 +37,80 @@ var config = discord.SlashCommandCreate{
 				},
 			},
 		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "set-timezone",
+			Description: "Set the timezone used for daily and weekly rewards",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionString{
+					Name:        "timezone",
+					Description: "An IANA timezone name, e.g. Europe/Amsterdam",
+					Required:    true,
+				},
+			},
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "set-level-up",
+			Description: "Configure level up announcements in the bot channel",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionBool{
+					Name:        "enabled",
+					Description: "Whether level ups should be announced",
+					Required:    true,
+				},
+				discord.ApplicationCommandOptionString{
+					Name:        "message",
+					Description: "The announcement, {user} and {level} are replaced",
+					Required:    false,
+					MaxLength:   json.Ptr(500),
+				},
+			},
+		},
+		discord.ApplicationCommandOptionSubCommandGroup{
+			Name:        "level-role",
+			Description: "Configure roles given when members reach a level",
+			Options: []discord.ApplicationCommandOptionSubCommand{
+				{
+					Name:        "add",
+					Description: "Give a role to members who reach a level",
+					Options: []discord.ApplicationCommandOption{
+						levelRoleLevelOption,
+						discord.ApplicationCommandOptionRole{
+							Name:        "role",
+							Description: "The role to give",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Name:        "remove",
+					Description: "Stop giving a role for a level",
+					Options:     []discord.ApplicationCommandOption{levelRoleLevelOption},
+				},
+				{
+					Name:        "list",
+					Description: "List the level roles",
+				},
+				{
+					Name:        "mode",
+					Description: "Choose whether members keep lower level roles",
+					Options: []discord.ApplicationCommandOption{
+						discord.ApplicationCommandOptionString{
+							Name:        "mode",
+							Description: "Stack keeps every level role, replace keeps only the highest",
+							Required:    true,
+							Choices: []discord.ApplicationCommandOptionChoiceString{
+								{Name: "Stack", Value: "stack"},
+								{Name: "Replace", Value: "replace"},
+							},
+						},
+					},
+				},
+			},
+		},
+		configXpGroup,
+		configWarnEscalationGroup,
+		configLockdownGroup,
+		configRaidGroup,
 		discord.ApplicationCommandOptionSubCommand{
 			Name:        "view",
 			Description: "View current server configuration",
@@ -49,9 +134,42 @@ func ConfigHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
 		data := e.SlashCommandInteractionData()
 		subcommand := data.SubCommandName
 
+		if data.SubCommandGroupName != nil && *data.SubCommandGroupName == "xp" {
+			return handleConfigXp(b, e)
+		}
+
+		if data.SubCommandGroupName != nil && *data.SubCommandGroupName == "warn-escalation" {
+			return handleConfigWarnEscalation(b, e)
+		}
+
+		if data.SubCommandGroupName != nil && *data.SubCommandGroupName == "lockdown-channels" {
+			return handleConfigLockdown(b, e)
+		}
+
+		if data.SubCommandGroupName != nil && *data.SubCommandGroupName == "raid-detection" {
+			return handleConfigRaid(b, e)
+		}
+
+		if data.SubCommandGroupName != nil && *data.SubCommandGroupName == "level-role" {
+			switch *subcommand {
+			case "add":
+				return handleAddLevelRole(b, e)
+			case "remove":
+				return handleRemoveLevelRole(b, e)
+			case "list":
+				return handleListLevelRoles(b, e)
+			case "mode":
+				return handleLevelRoleMode(b, e)
+			}
+		}
+
 		switch *subcommand {
 		case "set-moderator-role":
 			return handleSetModeratorRole(b, e)
+		case "set-timezone":
+			return handleSetTimezone(b, e)
+		case "set-level-up":
+			return handleSetLevelUp(b, e)
 		case "view":
 			return handleViewConfig(b, e)
 		default:
@@ -88,6 +206,7 @@ func handleSetModeratorRole(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) e
 				Build(),
 		)
 	}
+	b.InvalidateAutomod(guildID)
 
 	embed := discord.NewEmbedBuilder().
 		SetTitle("Moderator Role Updated").
@@ -104,6 +223,254 @@ func handleSetModeratorRole(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) e
 	)
 }
 
+func handleSetTimezone(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	timezone := strings.TrimSpace(data.String("timezone"))
+
+	// "Local" would mean the timezone of whatever machine the bot runs on
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" || timezone == "Local" {
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.FailureEmbed("Invalid Timezone",
+					fmt.Sprintf("`%s` is not a valid IANA timezone, e.g. `Europe/Amsterdam` or `UTC`.", timezone))).
+				SetEphemeral(true).
+				Build(),
+		)
+	}
+
+	err = b.Queries.SetGuildTimezone(e.Ctx, db.SetGuildTimezoneParams{
+		GuildID:  int64(*e.GuildID()),
+		Timezone: loc.String(),
+	})
+
+	if err != nil {
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.FailureEmbed("Configuration Failed",
+					fmt.Sprintf("Failed to update timezone: %s", err.Error()))).
+				SetEphemeral(true).
+				Build(),
+		)
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle("Timezone Updated").
+		SetDescription(fmt.Sprintf("The server timezone has been set to `%s`", loc.String())).
+		AddField("What this means",
+			"Daily rewards reset at midnight and weekly rewards on Monday at midnight in this timezone.",
+			false).
+		SetColor(utils.ColorSuccess)
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed.Build()).
+			Build(),
+	)
+}
+
+func handleSetLevelUp(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	enabled := data.Bool("enabled")
+	message, hasMessage := data.OptString("message")
+	message = strings.TrimSpace(message)
+
+	err := b.Queries.SetLevelUpAnnouncements(e.Ctx, db.SetLevelUpAnnouncementsParams{
+		GuildID:              int64(*e.GuildID()),
+		LevelUpAnnouncements: enabled,
+		LevelUpMessage:       pgtype.Text{String: message, Valid: hasMessage && message != ""},
+	})
+
+	if err != nil {
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.FailureEmbed("Configuration Failed",
+					fmt.Sprintf("Failed to update level up announcements: %s", err.Error()))).
+				SetEphemeral(true).
+				Build(),
+		)
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetColor(utils.ColorSuccess)
+
+	if enabled {
+		if message == "" {
+			message = mgbot.DefaultLevelUpMessage
+		}
+		embed.SetTitle("Level Up Announcements Enabled").
+			SetDescription("Level ups will be announced in the bot channel.").
+			AddField("Message", message, false)
+	} else {
+		embed.SetTitle("Level Up Announcements Disabled").
+			SetDescription("Level ups will no longer be announced. Level roles are still given.")
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed.Build()).
+			Build(),
+	)
+}
+
+func handleAddLevelRole(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	level := data.Int("level")
+	role := data.Role("role")
+
+	if role.Managed || role.ID == *e.GuildID() {
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.FailureEmbed("Invalid Role", "That role can't be given to members.")).
+				SetEphemeral(true).
+				Build(),
+		)
+	}
+
+	err := b.Queries.SetLevelRole(e.Ctx, db.SetLevelRoleParams{
+		GuildID: int64(*e.GuildID()),
+		Level:   int32(level),
+		RoleID:  int64(role.ID),
+	})
+
+	if err != nil {
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.FailureEmbed("Configuration Failed",
+					fmt.Sprintf("Failed to add level role: %s", err.Error()))).
+				SetEphemeral(true).
+				Build(),
+		)
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle("Level Role Added").
+		SetDescription(fmt.Sprintf("Members reaching level **%d** will get <@&%d>", level, role.ID)).
+		AddField("Note",
+			"Members already above this level get the role the next time they level up.",
+			false).
+		SetColor(utils.ColorSuccess)
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed.Build()).
+			Build(),
+	)
+}
+
+func handleRemoveLevelRole(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	level := e.SlashCommandInteractionData().Int("level")
+
+	removed, err := b.Queries.DeleteLevelRole(e.Ctx, db.DeleteLevelRoleParams{
+		GuildID: int64(*e.GuildID()),
+		Level:   int32(level),
+	})
+
+	if err != nil {
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.FailureEmbed("Configuration Failed",
+					fmt.Sprintf("Failed to remove level role: %s", err.Error()))).
+				SetEphemeral(true).
+				Build(),
+		)
+	}
+
+	if removed == 0 {
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.FailureEmbed("Not Found", fmt.Sprintf("There is no role for level %d.", level))).
+				SetEphemeral(true).
+				Build(),
+		)
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Level Role Removed",
+				fmt.Sprintf("Level %d no longer gives a role. Members keep the role if they already have it.", level))).
+			Build(),
+	)
+}
+
+func handleListLevelRoles(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	guildID := int64(*e.GuildID())
+
+	levelRoles, err := b.Queries.GetLevelRoles(e.Ctx, guildID)
+	if err != nil {
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.FailureEmbed("Error", "Failed to fetch level roles")).
+				SetEphemeral(true).
+				Build(),
+		)
+	}
+
+	config, err := b.Queries.GetLevelUpConfig(e.Ctx, guildID)
+	if err != nil {
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.FailureEmbed("Error", "Failed to fetch server configuration")).
+				SetEphemeral(true).
+				Build(),
+		)
+	}
+
+	description := "No level roles configured"
+	if len(levelRoles) > 0 {
+		var sb strings.Builder
+		for _, levelRole := range levelRoles {
+			sb.WriteString(fmt.Sprintf("**Level %d:** <@&%d>\n", levelRole.Level, levelRole.RoleID))
+		}
+		description = sb.String()
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle("Level Roles").
+		SetDescription(description).
+		AddField("Mode", levelRoleModeName(config.StackLevelRoles), false).
+		SetColor(utils.ColorInfo)
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed.Build()).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func handleLevelRoleMode(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	stack := e.SlashCommandInteractionData().String("mode") == "stack"
+
+	err := b.Queries.SetStackLevelRoles(e.Ctx, db.SetStackLevelRolesParams{
+		GuildID:         int64(*e.GuildID()),
+		StackLevelRoles: stack,
+	})
+
+	if err != nil {
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.FailureEmbed("Configuration Failed",
+					fmt.Sprintf("Failed to update level role mode: %s", err.Error()))).
+				SetEphemeral(true).
+				Build(),
+		)
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Level Role Mode Updated", levelRoleModeName(stack))).
+			Build(),
+	)
+}
+
+func levelRoleModeName(stack bool) string {
+	if stack {
+		return "Stack: members keep the roles of every level they reached"
+	}
+	return "Replace: members only keep the role of their highest level"
+}
+
 func handleViewConfig(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
 	guildID := *e.GuildID()
 
@@ -153,6 +520,16 @@ func handleViewConfig(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
 	// XP Multiplier
 	embed.AddField("XP Multiplier", fmt.Sprintf("%.1fx", config.XpMultiplier), true)
 
+	// Timezone
+	embed.AddField("Timezone", config.Timezone, true)
+
+	// Level Ups
+	if config.LevelUpAnnouncements {
+		embed.AddField("Level Up Announcements", "Enabled (in the bot channel)", true)
+	} else {
+		embed.AddField("Level Up Announcements", "Disabled", true)
+	}
+
 	// Notifications section
 	notificationsText := ""
 
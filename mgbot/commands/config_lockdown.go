@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+// lockableChannelTypes are the channels @everyone can be locked out of.
+var lockableChannelTypes = []discord.ChannelType{
+	discord.ChannelTypeGuildText,
+	discord.ChannelTypeGuildNews,
+	discord.ChannelTypeGuildVoice,
+	discord.ChannelTypeGuildStageVoice,
+	discord.ChannelTypeGuildForum,
+}
+
+var lockdownChannelOption = discord.ApplicationCommandOptionChannel{
+	Name:         "channel",
+	Description:  "The channel",
+	Required:     true,
+	ChannelTypes: lockableChannelTypes,
+}
+
+var configLockdownGroup = discord.ApplicationCommandOptionSubCommandGroup{
+	Name:        "lockdown-channels",
+	Description: "Configure the channels /moderation lockdown locks",
+	Options: []discord.ApplicationCommandOptionSubCommand{
+		{
+			Name:        "add",
+			Description: "Lock a channel during lockdowns",
+			Options: []discord.ApplicationCommandOption{
+				lockdownChannelOption,
+			},
+		},
+		{
+			Name:        "remove",
+			Description: "Stop locking a channel during lockdowns",
+			Options: []discord.ApplicationCommandOption{
+				lockdownChannelOption,
+			},
+		},
+		{
+			Name:        "list",
+			Description: "List the channels locked during lockdowns",
+		},
+	},
+}
+
+func handleConfigLockdown(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	switch *e.SlashCommandInteractionData().SubCommandName {
+	case "add":
+		return handleAddLockdownChannel(b, e)
+	case "remove":
+		return handleRemoveLockdownChannel(b, e)
+	case "list":
+		return handleListLockdownChannels(b, e)
+	}
+
+	return respondConfigError(e, "Invalid Command", "Unknown subcommand")
+}
+
+func handleAddLockdownChannel(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	channel := e.SlashCommandInteractionData().Channel("channel")
+
+	err := b.Queries.AddLockdownChannel(e.Ctx, db.AddLockdownChannelParams{
+		GuildID:   int64(*e.GuildID()),
+		ChannelID: int64(channel.ID),
+	})
+	if err != nil {
+		return respondConfigError(e, "Configuration Failed", fmt.Sprintf("Failed to add lockdown channel: %s", err.Error()))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Lockdown Channel Added",
+				fmt.Sprintf("<#%d> will be locked during lockdowns.", channel.ID))).
+			Build(),
+	)
+}
+
+func handleRemoveLockdownChannel(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	channel := e.SlashCommandInteractionData().Channel("channel")
+
+	removed, err := b.Queries.DeleteLockdownChannel(e.Ctx, db.DeleteLockdownChannelParams{
+		GuildID:   int64(*e.GuildID()),
+		ChannelID: int64(channel.ID),
+	})
+	if err != nil {
+		return respondConfigError(e, "Configuration Failed", fmt.Sprintf("Failed to remove lockdown channel: %s", err.Error()))
+	}
+	if removed == 0 {
+		return respondConfigError(e, "Not Found", fmt.Sprintf("<#%d> isn't a lockdown channel.", channel.ID))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Lockdown Channel Removed",
+				fmt.Sprintf("<#%d> will no longer be locked during lockdowns.", channel.ID))).
+			Build(),
+	)
+}
+
+func handleListLockdownChannels(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	channelIDs, err := b.Queries.GetLockdownChannels(e.Ctx, int64(*e.GuildID()))
+	if err != nil {
+		return respondConfigError(e, "Error", fmt.Sprintf("Failed to fetch lockdown channels: %s", err.Error()))
+	}
+
+	description := "No lockdown channels are set. Use `/config lockdown-channels add` to add one."
+	if len(channelIDs) > 0 {
+		lines := make([]string, len(channelIDs))
+		for i, channelID := range channelIDs {
+			lines[i] = fmt.Sprintf("• <#%d>", channelID)
+		}
+		description = strings.Join(lines, "\n")
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle("Lockdown Channels").
+		SetDescription(description).
+		SetColor(utils.ColorInfo).
+		Build()
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed).
+			SetEphemeral(true).
+			Build(),
+	)
+}
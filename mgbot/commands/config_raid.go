@@ -0,0 +1,236 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/json"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+// raidModeMaxDuration is the longest raid mode can stay on by itself.
+const raidModeMaxDuration = 24 * time.Hour
+
+var configRaidGroup = discord.ApplicationCommandOptionSubCommandGroup{
+	Name:        "raid-detection",
+	Description: "Configure how raids are detected from member joins",
+	Options: []discord.ApplicationCommandOptionSubCommand{
+		{
+			Name:        "set",
+			Description: "Detect raids and turn on raid mode when too many accounts join at once",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionInt{
+					Name:        "joins",
+					Description: "How many joins count as a raid",
+					Required:    true,
+					MinValue:    json.Ptr(2),
+					MaxValue:    json.Ptr(100),
+				},
+				discord.ApplicationCommandOptionInt{
+					Name:        "seconds",
+					Description: "How many seconds the joins have to be within",
+					Required:    true,
+					MinValue:    json.Ptr(1),
+					MaxValue:    json.Ptr(600),
+				},
+				discord.ApplicationCommandOptionInt{
+					Name:        "account_age_days",
+					Description: "Only count accounts younger than this many days, every account counts if not given",
+					Required:    false,
+					MinValue:    json.Ptr(0),
+					MaxValue:    json.Ptr(365),
+				},
+				discord.ApplicationCommandOptionString{
+					Name:        "action",
+					Description: "What raid mode does to new joins, only alerts if not given",
+					Required:    false,
+					Choices: []discord.ApplicationCommandOptionChoiceString{
+						{Name: "Alert only", Value: mgbot.RaidActionAlert},
+						{Name: "Kick", Value: mgbot.RaidActionKick},
+						{Name: "Timeout", Value: mgbot.RaidActionTimeout},
+					},
+				},
+				discord.ApplicationCommandOptionString{
+					Name:        "timeout",
+					Description: "How long to time new joins out for (e.g., 1h, 1d)",
+					Required:    false,
+				},
+				discord.ApplicationCommandOptionString{
+					Name:        "raid_mode_duration",
+					Description: "How long raid mode stays on (e.g., 10m, 1h), 10 minutes if not given",
+					Required:    false,
+				},
+				discord.ApplicationCommandOptionChannel{
+					Name:        "alert_channel",
+					Description: "Where to alert moderators, the modlogs channel if not given",
+					Required:    false,
+					ChannelTypes: []discord.ChannelType{
+						discord.ChannelTypeGuildText,
+					},
+				},
+			},
+		},
+		{
+			Name:        "disable",
+			Description: "Stop detecting raids",
+		},
+		{
+			Name:        "view",
+			Description: "View the raid detection settings",
+		},
+	},
+}
+
+func handleConfigRaid(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	switch *e.SlashCommandInteractionData().SubCommandName {
+	case "set":
+		return handleSetRaidDetection(b, e)
+	case "disable":
+		return handleDisableRaidDetection(b, e)
+	case "view":
+		return handleViewRaidDetection(b, e)
+	}
+
+	return respondConfigError(e, "Invalid Command", "Unknown subcommand")
+}
+
+func handleSetRaidDetection(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+
+	action := data.String("action")
+	if action == "" {
+		action = mgbot.RaidActionAlert
+	}
+
+	var timeout time.Duration
+	if action == mgbot.RaidActionTimeout {
+		if data.String("timeout") == "" {
+			return respondConfigError(e, "Missing Timeout", "Give how long to time new joins out for.")
+		}
+		var err error
+		timeout, err = parseDuration(data.String("timeout"))
+		if err != nil {
+			return respondConfigError(e, "Invalid Timeout", err.Error())
+		}
+		if timeout > mgbot.MaxMuteDuration {
+			return respondConfigError(e, "Timeout Too Long", "Maximum timeout duration is 28 days")
+		}
+	}
+
+	raidMode := mgbot.DefaultRaidModeDuration
+	if value := data.String("raid_mode_duration"); value != "" {
+		var err error
+		raidMode, err = parseDuration(value)
+		if err != nil {
+			return respondConfigError(e, "Invalid Duration", err.Error())
+		}
+		if raidMode < time.Minute || raidMode > raidModeMaxDuration {
+			return respondConfigError(e, "Invalid Duration", "Raid mode has to last between a minute and a day.")
+		}
+	}
+
+	alertChannel := pgtype.Int8{Valid: false}
+	if channel, ok := data.OptChannel("alert_channel"); ok {
+		alertChannel = pgtype.Int8{Int64: int64(channel.ID), Valid: true}
+	}
+
+	settings := db.RaidSetting{
+		GuildID:           int64(*e.GuildID()),
+		Enabled:           true,
+		JoinThreshold:     int32(data.Int("joins")),
+		JoinWindowSeconds: int32(data.Int("seconds")),
+		NewAccountDays:    int32(data.Int("account_age_days")),
+		Action:            action,
+		TimeoutSeconds:    int32(timeout / time.Second),
+		RaidModeSeconds:   int32(raidMode / time.Second),
+		AlertChannelID:    alertChannel,
+	}
+	err := b.Queries.SetRaidSettings(e.Ctx, db.SetRaidSettingsParams{
+		GuildID:           settings.GuildID,
+		JoinThreshold:     settings.JoinThreshold,
+		JoinWindowSeconds: settings.JoinWindowSeconds,
+		NewAccountDays:    settings.NewAccountDays,
+		Action:            settings.Action,
+		TimeoutSeconds:    settings.TimeoutSeconds,
+		RaidModeSeconds:   settings.RaidModeSeconds,
+		AlertChannelID:    settings.AlertChannelID,
+	})
+	if err != nil {
+		return respondConfigError(e, "Configuration Failed", fmt.Sprintf("Failed to update raid detection: %s", err.Error()))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(raidSettingsEmbed(settings, "Raid Detection Enabled")).
+			Build(),
+	)
+}
+
+func handleDisableRaidDetection(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	disabled, err := b.Queries.DisableRaidDetection(e.Ctx, int64(*e.GuildID()))
+	if err != nil {
+		return respondConfigError(e, "Configuration Failed", fmt.Sprintf("Failed to disable raid detection: %s", err.Error()))
+	}
+	if disabled == 0 {
+		return respondConfigError(e, "Not Enabled", "Raid detection isn't enabled.")
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Raid Detection Disabled",
+				"Raids will no longer be detected. Raid mode can still be turned on with `/moderation raid start`.")).
+			Build(),
+	)
+}
+
+func handleViewRaidDetection(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	settings, err := b.Queries.GetRaidSettings(e.Ctx, int64(*e.GuildID()))
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return respondConfigError(e, "Not Configured", "Raid detection isn't set up. Use `/config raid-detection set` to set it up.")
+	} else if err != nil {
+		return respondConfigError(e, "Error", fmt.Sprintf("Failed to fetch raid detection settings: %s", err.Error()))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(raidSettingsEmbed(settings, "Raid Detection")).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func raidSettingsEmbed(settings db.RaidSetting, title string) discord.Embed {
+	status := "Enabled"
+	color := utils.ColorSuccess
+	if !settings.Enabled {
+		status = "Disabled"
+		color = utils.ColorError
+	}
+
+	accounts := "Every account"
+	if settings.NewAccountDays > 0 {
+		accounts = fmt.Sprintf("Younger than %d days", settings.NewAccountDays)
+	}
+
+	alertChannel := "Modlogs channel"
+	if settings.AlertChannelID.Valid {
+		alertChannel = fmt.Sprintf("<#%d>", settings.AlertChannelID.Int64)
+	}
+
+	return discord.NewEmbedBuilder().
+		SetTitle(title).
+		SetColor(color).
+		AddField("Status", status, true).
+		AddField("Threshold", fmt.Sprintf("%d joins in %ds", settings.JoinThreshold, settings.JoinWindowSeconds), true).
+		AddField("Counted Accounts", accounts, true).
+		AddField("Raid Mode", mgbot.DescribeRaidAction(settings.Action, settings.TimeoutSeconds), true).
+		AddField("Raid Mode Duration", mgbot.FormatDuration(time.Duration(settings.RaidModeSeconds)*time.Second), true).
+		AddField("Alerts", alertChannel, true).
+		Build()
+}
@@ -0,0 +1,163 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/json"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+const warnEscalationMaxCount = 50
+
+var warnEscalationCountOption = discord.ApplicationCommandOptionInt{
+	Name:        "warnings",
+	Description: "The number of active warnings the escalation applies at",
+	Required:    true,
+	MinValue:    json.Ptr(1),
+	MaxValue:    json.Ptr(warnEscalationMaxCount),
+}
+
+var configWarnEscalationGroup = discord.ApplicationCommandOptionSubCommandGroup{
+	Name:        "warn-escalation",
+	Description: "Configure what happens to members who keep getting warned",
+	Options: []discord.ApplicationCommandOptionSubCommand{
+		{
+			Name:        "set",
+			Description: "Mute or ban members when they reach a number of warnings",
+			Options: []discord.ApplicationCommandOption{
+				warnEscalationCountOption,
+				discord.ApplicationCommandOptionString{
+					Name:        "action",
+					Description: "What to do to the member",
+					Required:    true,
+					Choices: []discord.ApplicationCommandOptionChoiceString{
+						{Name: "Mute", Value: mgbot.EscalationMute},
+						{Name: "Temporary ban", Value: mgbot.EscalationTempBan},
+					},
+				},
+				discord.ApplicationCommandOptionString{
+					Name:        "duration",
+					Description: "How long to mute or ban for (e.g., 1h, 1d, 1w)",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "remove",
+			Description: "Remove the escalation at a number of warnings",
+			Options: []discord.ApplicationCommandOption{
+				warnEscalationCountOption,
+			},
+		},
+		{
+			Name:        "list",
+			Description: "List the warning escalations",
+		},
+	},
+}
+
+func handleConfigWarnEscalation(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	switch *e.SlashCommandInteractionData().SubCommandName {
+	case "set":
+		return handleSetWarnEscalation(b, e)
+	case "remove":
+		return handleRemoveWarnEscalation(b, e)
+	case "list":
+		return handleListWarnEscalations(b, e)
+	}
+
+	return respondConfigError(e, "Invalid Command", "Unknown subcommand")
+}
+
+func handleSetWarnEscalation(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	count := data.Int("warnings")
+	action := data.String("action")
+
+	duration, err := parseDuration(data.String("duration"))
+	if err != nil {
+		return respondConfigError(e, "Invalid Duration", err.Error())
+	}
+	if duration < time.Minute {
+		return respondConfigError(e, "Invalid Duration", "Escalations must last at least a minute.")
+	}
+	if action == mgbot.EscalationMute && duration > mgbot.MaxMuteDuration {
+		return respondConfigError(e, "Duration Too Long", "Maximum timeout duration is 28 days")
+	}
+
+	escalation := db.WarnEscalation{
+		GuildID:         int64(*e.GuildID()),
+		WarnCount:       int32(count),
+		Action:          action,
+		DurationSeconds: int32(duration / time.Second),
+	}
+	err = b.Queries.SetWarnEscalation(e.Ctx, db.SetWarnEscalationParams(escalation))
+	if err != nil {
+		return respondConfigError(e, "Configuration Failed", fmt.Sprintf("Failed to update warning escalation: %s", err.Error()))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Warning Escalation Set",
+				fmt.Sprintf("Members reaching %s.", formatWarnEscalation(escalation)))).
+			Build(),
+	)
+}
+
+func handleRemoveWarnEscalation(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	count := e.SlashCommandInteractionData().Int("warnings")
+
+	removed, err := b.Queries.DeleteWarnEscalation(e.Ctx, db.DeleteWarnEscalationParams{
+		GuildID:   int64(*e.GuildID()),
+		WarnCount: int32(count),
+	})
+	if err != nil {
+		return respondConfigError(e, "Configuration Failed", fmt.Sprintf("Failed to remove warning escalation: %s", err.Error()))
+	}
+	if removed == 0 {
+		return respondConfigError(e, "Not Found", fmt.Sprintf("There is no escalation at %d warnings.", count))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Warning Escalation Removed",
+				fmt.Sprintf("Members reaching %d warnings are no longer muted or banned.", count))).
+			Build(),
+	)
+}
+
+func handleListWarnEscalations(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	escalations, err := b.Queries.GetWarnEscalations(e.Ctx, int64(*e.GuildID()))
+	if err != nil {
+		return respondConfigError(e, "Error", fmt.Sprintf("Failed to fetch warning escalations: %s", err.Error()))
+	}
+
+	description := "No warning escalations are set. Use `/config warn-escalation set` to add one."
+	if len(escalations) > 0 {
+		lines := make([]string, len(escalations))
+		for i, escalation := range escalations {
+			lines[i] = "• " + formatWarnEscalation(escalation)
+		}
+		description = strings.Join(lines, "\n")
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle("Warning Escalations").
+		SetDescription(description).
+		SetColor(utils.ColorInfo).
+		SetFooterText("Escalations apply when a member reaches exactly that many active warnings").
+		Build()
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed).
+			SetEphemeral(true).
+			Build(),
+	)
+}
@@ -0,0 +1,363 @@
+package commands
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/json"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+const xpMaxMultiplier = 10.0
+
+var configXpGroup = discord.ApplicationCommandOptionSubCommandGroup{
+	Name:        "xp",
+	Description: "Configure how much XP members earn",
+	Options: []discord.ApplicationCommandOptionSubCommand{
+		{
+			Name:        "multiplier",
+			Description: "Set the server-wide XP multiplier",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionFloat{
+					Name:        "value",
+					Description: "How much all XP is multiplied by",
+					Required:    true,
+					MinValue:    json.Ptr(0.1),
+					MaxValue:    json.Ptr(xpMaxMultiplier),
+				},
+			},
+		},
+		{
+			Name:        "channel",
+			Description: "Set the XP multiplier of a channel, leave it empty to remove it",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionChannel{
+					Name:        "channel",
+					Description: "The channel",
+					Required:    true,
+					ChannelTypes: []discord.ChannelType{
+						discord.ChannelTypeGuildText,
+						discord.ChannelTypeGuildNews,
+						discord.ChannelTypeGuildVoice,
+						discord.ChannelTypeGuildStageVoice,
+						discord.ChannelTypeGuildPublicThread,
+						discord.ChannelTypeGuildForum,
+					},
+				},
+				xpOverrideMultiplierOption,
+			},
+		},
+		{
+			Name:        "role",
+			Description: "Set the XP multiplier of a role, leave it empty to remove it",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionRole{
+					Name:        "role",
+					Description: "The role",
+					Required:    true,
+				},
+				xpOverrideMultiplierOption,
+			},
+		},
+		{
+			Name:        "event-start",
+			Description: "Start a server-wide XP event",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionString{
+					Name:        "name",
+					Description: "The name of the event, e.g. Double XP Weekend",
+					Required:    true,
+					MaxLength:   json.Ptr(100),
+				},
+				discord.ApplicationCommandOptionFloat{
+					Name:        "multiplier",
+					Description: "How much XP is multiplied by during the event",
+					Required:    true,
+					MinValue:    json.Ptr(1.1),
+					MaxValue:    json.Ptr(xpMaxMultiplier),
+				},
+				discord.ApplicationCommandOptionInt{
+					Name:        "hours",
+					Description: "How long the event lasts",
+					Required:    true,
+					MinValue:    json.Ptr(1),
+					MaxValue:    json.Ptr(14 * 24),
+				},
+				discord.ApplicationCommandOptionInt{
+					Name:        "starts_in",
+					Description: "Hours until the event starts, it starts now by default",
+					Required:    false,
+					MinValue:    json.Ptr(0),
+					MaxValue:    json.Ptr(30 * 24),
+				},
+			},
+		},
+		{
+			Name:        "event-end",
+			Description: "End or cancel an XP event",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionInt{
+					Name:        "id",
+					Description: "The ID of the event, shown in /config xp list",
+					Required:    true,
+				},
+			},
+		},
+		{
+			Name:        "list",
+			Description: "View the XP multipliers and events",
+		},
+	},
+}
+
+var xpOverrideMultiplierOption = discord.ApplicationCommandOptionFloat{
+	Name:        "multiplier",
+	Description: "How much XP is multiplied by, 0 gives no XP",
+	Required:    false,
+	MinValue:    json.Ptr(0.0),
+	MaxValue:    json.Ptr(xpMaxMultiplier),
+}
+
+func handleConfigXp(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	switch *e.SlashCommandInteractionData().SubCommandName {
+	case "multiplier":
+		return handleSetXpMultiplier(b, e)
+	case "channel", "role":
+		return handleSetXpOverride(b, e)
+	case "event-start":
+		return handleStartXpEvent(b, e)
+	case "event-end":
+		return handleEndXpEvent(b, e)
+	case "list":
+		return handleListXpConfig(b, e)
+	}
+
+	return respondConfigError(e, "Invalid Command", "Unknown subcommand")
+}
+
+func handleSetXpMultiplier(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	value := e.SlashCommandInteractionData().Float("value")
+
+	err := b.Queries.SetXpMultiplier(e.Ctx, db.SetXpMultiplierParams{
+		GuildID:      int64(*e.GuildID()),
+		XpMultiplier: value,
+	})
+	if err != nil {
+		return respondConfigError(e, "Configuration Failed", fmt.Sprintf("Failed to update XP multiplier: %s", err.Error()))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("XP Multiplier Updated",
+				fmt.Sprintf("All XP in this server is now multiplied by **%s**", formatMultiplier(value)))).
+			Build(),
+	)
+}
+
+func handleSetXpOverride(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	guildID := int64(*e.GuildID())
+
+	var targetType, mention string
+	var targetID int64
+	if *data.SubCommandName == "channel" {
+		channel := data.Channel("channel")
+		targetType, targetID, mention = mgbot.XpOverrideChannel, int64(channel.ID), fmt.Sprintf("<#%d>", channel.ID)
+	} else {
+		role := data.Role("role")
+		targetType, targetID, mention = mgbot.XpOverrideRole, int64(role.ID), fmt.Sprintf("<@&%d>", role.ID)
+	}
+
+	multiplier, ok := data.OptFloat("multiplier")
+	if !ok {
+		removed, err := b.Queries.DeleteXpOverride(e.Ctx, db.DeleteXpOverrideParams{
+			GuildID:    guildID,
+			TargetType: targetType,
+			TargetID:   targetID,
+		})
+		if err != nil {
+			return respondConfigError(e, "Configuration Failed", fmt.Sprintf("Failed to remove XP multiplier: %s", err.Error()))
+		}
+		if removed == 0 {
+			return respondConfigError(e, "Not Found", fmt.Sprintf("%s doesn't have an XP multiplier.", mention))
+		}
+
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.SuccessEmbed("XP Multiplier Removed",
+					fmt.Sprintf("%s uses the server XP multiplier again.", mention))).
+				Build(),
+		)
+	}
+
+	err := b.Queries.SetXpOverride(e.Ctx, db.SetXpOverrideParams{
+		GuildID:    guildID,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Multiplier: multiplier,
+	})
+	if err != nil {
+		return respondConfigError(e, "Configuration Failed", fmt.Sprintf("Failed to update XP multiplier: %s", err.Error()))
+	}
+
+	description := fmt.Sprintf("XP in %s is now multiplied by **%s**", mention, formatMultiplier(multiplier))
+	if targetType == mgbot.XpOverrideRole {
+		description = fmt.Sprintf("XP of members with %s is now multiplied by **%s**", mention, formatMultiplier(multiplier))
+	}
+	if multiplier == 0 {
+		description = fmt.Sprintf("%s no longer earns XP.", mention)
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("XP Multiplier Updated", description)).
+			Build(),
+	)
+}
+
+func handleStartXpEvent(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+
+	name := strings.TrimSpace(data.String("name"))
+	if name == "" {
+		return respondConfigError(e, "Invalid Name", "XP event names can't be empty.")
+	}
+
+	startsAt := time.Now().UTC().Add(time.Duration(data.Int("starts_in")) * time.Hour)
+	endsAt := startsAt.Add(time.Duration(data.Int("hours")) * time.Hour)
+
+	event, err := b.Queries.CreateXpEvent(e.Ctx, db.CreateXpEventParams{
+		GuildID:    int64(*e.GuildID()),
+		Name:       name,
+		Multiplier: data.Float("multiplier"),
+		StartsAt:   pgtype.Timestamp{Time: startsAt, Valid: true},
+		EndsAt:     pgtype.Timestamp{Time: endsAt, Valid: true},
+		CreatedBy:  int64(e.User().ID),
+	})
+	if err != nil {
+		return respondConfigError(e, "Configuration Failed", fmt.Sprintf("Failed to create XP event: %s", err.Error()))
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle(fmt.Sprintf("XP Event: %s", event.Name)).
+		SetDescription(fmt.Sprintf("All XP is multiplied by **%s**", formatMultiplier(event.Multiplier))).
+		AddField("Starts", fmt.Sprintf("<t:%d:R>", startsAt.Unix()), true).
+		AddField("Ends", fmt.Sprintf("<t:%d:R>", endsAt.Unix()), true).
+		SetFooterTextf("Event ID: %d", event.ID).
+		SetColor(utils.ColorSuccess)
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed.Build()).
+			Build(),
+	)
+}
+
+func handleEndXpEvent(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	id := e.SlashCommandInteractionData().Int("id")
+
+	removed, err := b.Queries.DeleteXpEvent(e.Ctx, db.DeleteXpEventParams{
+		GuildID: int64(*e.GuildID()),
+		ID:      int64(id),
+	})
+	if err != nil {
+		return respondConfigError(e, "Configuration Failed", fmt.Sprintf("Failed to end XP event: %s", err.Error()))
+	}
+	if removed == 0 {
+		return respondConfigError(e, "Not Found", fmt.Sprintf("There is no XP event with ID %d.", id))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("XP Event Ended", fmt.Sprintf("XP event %d has been ended.", id))).
+			Build(),
+	)
+}
+
+func handleListXpConfig(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	guildID := int64(*e.GuildID())
+
+	multiplier, err := b.Queries.GetXpMultiplier(e.Ctx, guildID)
+	if err != nil {
+		return respondConfigError(e, "Error", "Failed to fetch server configuration")
+	}
+
+	overrides, err := b.Queries.GetXpOverrides(e.Ctx, guildID)
+	if err != nil {
+		return respondConfigError(e, "Error", "Failed to fetch XP multipliers")
+	}
+
+	events, err := b.Queries.GetXpEvents(e.Ctx, db.GetXpEventsParams{
+		GuildID: guildID,
+		EndsAt:  pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		return respondConfigError(e, "Error", "Failed to fetch XP events")
+	}
+
+	var channels, roles strings.Builder
+	for _, override := range overrides {
+		value := formatMultiplier(override.Multiplier)
+		if override.Multiplier == 0 {
+			value = "No XP"
+		}
+
+		switch override.TargetType {
+		case mgbot.XpOverrideChannel:
+			channels.WriteString(fmt.Sprintf("<#%d>: %s\n", override.TargetID, value))
+		case mgbot.XpOverrideRole:
+			roles.WriteString(fmt.Sprintf("<@&%d>: %s\n", override.TargetID, value))
+		}
+	}
+
+	var eventsText strings.Builder
+	for _, event := range events {
+		eventsText.WriteString(fmt.Sprintf("`%d` **%s** %s, <t:%d:f> to <t:%d:f>\n",
+			event.ID, event.Name, formatMultiplier(event.Multiplier), event.StartsAt.Time.Unix(), event.EndsAt.Time.Unix()))
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle("XP Configuration").
+		SetDescription(fmt.Sprintf("Server multiplier: **%s**", formatMultiplier(multiplier))).
+		AddField("Channels", orNone(utils.CutString(channels.String(), 1024)), false).
+		AddField("Roles", orNone(utils.CutString(roles.String(), 1024)), false).
+		AddField("Events", orNone(utils.CutString(eventsText.String(), 1024)), false).
+		SetFooterText("Role multipliers don't stack, the highest one is used").
+		SetColor(utils.ColorInfo)
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed.Build()).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+// formatMultiplier shows a multiplier like 1.25x, without rounding it or
+// padding it with zeros.
+func formatMultiplier(multiplier float64) string {
+	return strconv.FormatFloat(multiplier, 'f', -1, 64) + "x"
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "None"
+	}
+	return s
+}
+
+func respondConfigError(e *handler.CommandEvent, title, description string) error {
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.FailureEmbed(title, description)).
+			SetEphemeral(true).
+			Build(),
+	)
+}
@@ -86,13 +86,21 @@ func DepositHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
 			amtToDeposit = int64(amt)
 		}
 
-		err = b.Queries.DepositAmount(e.Ctx, db.DepositAmountParams{
-			ID:      int64(e.Member().User.ID),
-			GuildID: int64(*e.GuildID()),
-			InHand: pgtype.Int8{
-				Int64: amtToDeposit,
-				Valid: true,
-			},
+		err = b.UpdateCoins(e.Ctx, func(q *db.Queries) error {
+			return q.DepositAmount(e.Ctx, db.DepositAmountParams{
+				ID:      int64(e.Member().User.ID),
+				GuildID: int64(*e.GuildID()),
+				InHand: pgtype.Int8{
+					Int64: amtToDeposit,
+					Valid: true,
+				},
+			})
+		}, db.CreateCoinTransactionParams{
+			GuildID:      int64(*e.GuildID()),
+			UserID:       int64(e.Member().User.ID),
+			Kind:         mgbot.CoinTxDeposit,
+			InHandChange: -amtToDeposit,
+			SafeChange:   amtToDeposit,
 		})
 		if err != nil {
 			return err
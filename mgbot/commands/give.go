@@ -1,11 +1,11 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/handler"
-	"github.com/jackc/pgx/v5/pgtype"
 	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
 	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
 	"github.com/milindmadhukar/MartinGarrixBot/utils"
@@ -68,6 +68,26 @@ func GiveHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
 			)
 		}
 
+		if member.User.ID == e.Member().User.ID {
+			embed := utils.FailureEmbed("You can't give coins to yourself.", "")
+			return e.Respond(
+				discord.InteractionResponseTypeCreateMessage, discord.NewMessageCreateBuilder().
+					SetEmbeds(embed).
+					SetEphemeral(true).
+					Build(),
+			)
+		}
+
+		if member.User.Bot {
+			embed := utils.FailureEmbed("You can't give coins to a bot.", "")
+			return e.Respond(
+				discord.InteractionResponseTypeCreateMessage, discord.NewMessageCreateBuilder().
+					SetEmbeds(embed).
+					SetEphemeral(true).
+					Build(),
+			)
+		}
+
 		var embed discord.Embed
 		var amtToGive int64
 
@@ -76,7 +96,7 @@ func GiveHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
 			GuildID: int64(*e.GuildID()),
 		})
 
-		if err != nil {
+		if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
 			return err
 		}
 
@@ -85,27 +105,31 @@ func GiveHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
 		} else if isAll {
 			amtToGive = balanceInfo.InHand.Int64
 		} else if amtOk {
-			if int64(amt) > balanceInfo.InHand.Int64 {
-				embed = utils.FailureEmbed("You don't have enough coins in hand to give.", "")
-				return e.Respond(
-					discord.InteractionResponseTypeCreateMessage, discord.NewMessageCreateBuilder().
-						SetEmbeds(embed).
-						SetEphemeral(true).
-						Build(),
-				)
-			}
-
 			amtToGive = int64(amt)
 		}
 
-		err = b.Queries.GiveCoins(e.Ctx, db.GiveCoinsParams{
-			ID:   int64(e.Member().User.ID),
-			ID_2: int64(member.User.ID),
-			InHand: pgtype.Int8{
-				Int64: amtToGive,
-				Valid: true,
-			},
-		})
+		if amtToGive <= 0 {
+			embed = utils.FailureEmbed("You don't have any coins in hand to give.", "")
+			return e.Respond(
+				discord.InteractionResponseTypeCreateMessage, discord.NewMessageCreateBuilder().
+					SetEmbeds(embed).
+					SetEphemeral(true).
+					Build(),
+			)
+		}
+
+		// TransferCoins checks the balance with the sender's row locked, so
+		// concurrent commands can't overdraw it.
+		err = b.TransferCoins(e.Ctx, int64(*e.GuildID()), int64(e.Member().User.ID), int64(member.User.ID), amtToGive)
+		if errors.Is(err, mgbot.ErrInsufficientFunds) {
+			embed = utils.FailureEmbed("You don't have enough coins in hand to give.", "")
+			return e.Respond(
+				discord.InteractionResponseTypeCreateMessage, discord.NewMessageCreateBuilder().
+					SetEmbeds(embed).
+					SetEphemeral(true).
+					Build(),
+			)
+		}
 
 		if err != nil {
 			return err
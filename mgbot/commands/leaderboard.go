@@ -1,17 +1,24 @@
 package commands
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"strconv"
-	"strings"
+	"sync"
+	"time"
 
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/handler"
 	"github.com/disgoorg/snowflake/v2"
+	"github.com/jackc/pgx/v5/pgtype"
 	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
 	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
 	"github.com/milindmadhukar/MartinGarrixBot/utils"
 )
 
+const leaderboardPageSize = 10
+
 var leaderboard = discord.SlashCommandCreate{
 	Name:        "leaderboard",
 	Description: "Get the leaderboard for a specific category.",
@@ -37,157 +44,460 @@ var leaderboard = discord.SlashCommandCreate{
 					Name:  "In Hand Coins",
 					Value: "In Hand Coins",
 				},
+				{
+					Name:  "Voice Time",
+					Value: "Voice Time",
+				},
+			},
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "period",
+			Description: "The period of the leaderboard, all time by default.",
+			Required:    false,
+			Choices: []discord.ApplicationCommandOptionChoiceString{
+				{
+					Name:  "Today",
+					Value: mgbot.PeriodToday,
+				},
+				{
+					Name:  "This Week",
+					Value: mgbot.PeriodWeek,
+				},
+				{
+					Name:  "This Month",
+					Value: mgbot.PeriodMonth,
+				},
+				{
+					Name:  "All Time",
+					Value: mgbot.PeriodAllTime,
+				},
 			},
 		},
 	},
 }
 
+// leaderboardActivity is what a category ranks members by over a period. Coins
+// are balances rather than activity, so they only have all time leaderboards.
+var leaderboardActivity = map[string]string{
+	"Levels":     mgbot.ActivityXp,
+	"Messages":   mgbot.ActivityMessages,
+	"Voice Time": mgbot.ActivityVoice,
+}
+
+var leaderboardPeriodNames = map[string]string{
+	mgbot.PeriodToday: "Today",
+	mgbot.PeriodWeek:  "This Week",
+	mgbot.PeriodMonth: "This Month",
+}
+
 type leaderboardDetails struct {
-	member discord.Member
-	value  string
+	userID  snowflake.ID
+	value   string
+	totalXp int32
 }
 
 func LeaderboardHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
 	return func(e *handler.CommandEvent) error {
+		data := e.SlashCommandInteractionData()
+		category := data.String("category")
+
+		period := data.String("period")
+		if period == "" {
+			period = mgbot.PeriodAllTime
+		}
+		if _, ok := leaderboardActivity[category]; !ok && period != mgbot.PeriodAllTime {
+			return e.Respond(discord.InteractionResponseTypeCreateMessage,
+				discord.NewMessageCreateBuilder().
+					SetEmbeds(utils.FailureEmbed(category+" leaderboards only cover all time.", "")).
+					SetEphemeral(true).
+					Build(),
+			)
+		}
+
 		e.DeferCreateMessage(false)
 
-		category := e.SlashCommandInteractionData().String("category")
-		var leaderboard []leaderboardDetails
+		message, err := leaderboardMessage(e.Ctx, b, *e.GuildID(), e.User().ID, category, period, 0)
+		if err != nil {
+			return err
+		}
 
-		switch category {
-		// TODO: Use offset and pagination to show pages for leaderboards
-		case "Coins":
-			records, err := b.Queries.GetCoinsLeaderboard(e.Ctx, db.GetCoinsLeaderboardParams{
-				GuildID: int64(*e.GuildID()),
-				Offset:  0,
-			})
+		_, err = e.UpdateInteractionResponse(message)
+		return err
+	}
+}
+
+// LeaderboardPageHandler moves a leaderboard to another page. Only the member
+// who ran the command can use the buttons, and "me" jumps to their own page.
+func LeaderboardPageHandler(b *mgbot.MartinGarrixBot) handler.ComponentHandler {
+	return func(e *handler.ComponentEvent) error {
+		if e.Vars["user"] != e.User().ID.String() {
+			return e.CreateMessage(discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.FailureEmbed("You can't use this leaderboard because it's not yours.", "Run `/leaderboard` to get your own.")).
+				SetEphemeral(true).
+				Build(),
+			)
+		}
+
+		page, err := strconv.Atoi(e.Vars["page"])
+		if err != nil {
+			return err
+		}
+
+		guildID := *e.GuildID()
+		category, period := e.Vars["category"], e.Vars["period"]
+
+		switch e.Vars["action"] {
+		case "first":
+			page = 0
+		case "back":
+			page--
+		case "next":
+			page++
+		case "last":
+			_, total, err := leaderboardPage(e.Ctx, b, guildID, category, period, 0)
 			if err != nil {
 				return err
 			}
-
-			for _, record := range records {
-				var discordMember discord.Member
-				var ok bool
-				discordMember, ok = b.Client.Caches().Member(*e.GuildID(), snowflake.ID(record.ID))
-				if !ok {
-					discordMemberPtr, err := b.Client.Rest().GetMember(*e.GuildID(), snowflake.ID(record.ID))
-					if err != nil {
-						return err
-					}
-					discordMember = *discordMemberPtr
-				}
-
-				leaderboard = append(leaderboard, leaderboardDetails{
-					member: discordMember,
-					value:  strconv.Itoa(int(record.GarrixCoins.Int64 + record.InHand.Int64)),
-				})
-			}
-
-		case "Levels":
-			records, err := b.Queries.GetLevelsLeaderboard(e.Ctx, db.GetLevelsLeaderboardParams{
-				GuildID: int64(*e.GuildID()),
-				Offset:  0,
-			})
+			page = utils.CalculateTotalPages(total, leaderboardPageSize) - 1
+		case "me":
+			position, err := leaderboardPosition(e.Ctx, b, guildID, e.User().ID, category, period)
 			if err != nil {
 				return err
 			}
+			page = max(int(position)-1, 0) / leaderboardPageSize
+		}
+
+		if err := e.DeferUpdateMessage(); err != nil {
+			return err
+		}
+
+		message, err := leaderboardMessage(e.Ctx, b, guildID, e.User().ID, category, period, page)
+		if err != nil {
+			return err
+		}
+
+		_, err = e.UpdateInteractionResponse(message)
+		return err
+	}
+}
+
+// leaderboardMessage renders a page of a leaderboard with the buttons to move
+// through it and the viewer's own position in the footer.
+func leaderboardMessage(ctx context.Context, b *mgbot.MartinGarrixBot, guildID, userID snowflake.ID, category, period string, page int) (discord.MessageUpdate, error) {
+	page = max(page, 0)
+
+	leaderboard, total, err := leaderboardPage(ctx, b, guildID, category, period, page)
+	if err != nil {
+		return discord.MessageUpdate{}, err
+	}
+
+	pages := max(utils.CalculateTotalPages(total, leaderboardPageSize), 1)
+	if page >= pages {
+		page = pages - 1
+		leaderboard, total, err = leaderboardPage(ctx, b, guildID, category, period, page)
+		if err != nil {
+			return discord.MessageUpdate{}, err
+		}
+	}
 
-			for _, record := range records {
-				var discordMember discord.Member
-				var ok bool
-				discordMember, ok = b.Client.Caches().Member(*e.GuildID(), snowflake.ID(record.ID))
-				if !ok {
-					discordMemberPtr, err := b.Client.Rest().GetMember(*e.GuildID(), snowflake.ID(record.ID))
-					if err != nil {
-						return err
-					}
-					discordMember = *discordMemberPtr
-				}
-
-				leaderboard = append(leaderboard, leaderboardDetails{
-					member: discordMember,
-					value:  strconv.Itoa(utils.GetUserLevel(record.TotalXp.Int32)),
-				})
+	position, err := leaderboardPosition(ctx, b, guildID, userID, category, period)
+	if err != nil {
+		return discord.MessageUpdate{}, err
+	}
+
+	title := category + " Leaderboard"
+	if name, ok := leaderboardPeriodNames[period]; ok {
+		title += " - " + name
+	}
+
+	// Members who aren't cached are fetched concurrently, like the avatars
+	entries := make([]utils.LeaderboardEntry, len(leaderboard))
+	var wg sync.WaitGroup
+	for idx, leaderboardDetail := range leaderboard {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			name, avatarURL := leaderboardMember(b, guildID, leaderboardDetail.userID)
+			entries[idx] = utils.LeaderboardEntry{
+				Rank:      page*leaderboardPageSize + idx + 1,
+				Name:      name,
+				AvatarURL: avatarURL,
+				Value:     leaderboardDetail.value,
+				TotalXp:   leaderboardDetail.totalXp,
+				Highlight: leaderboardDetail.userID == userID,
 			}
+		}()
+	}
+	wg.Wait()
+
+	picture, err := utils.LeaderboardPicture(title, entries)
+	if err != nil {
+		return discord.MessageUpdate{}, err
+	}
 
-		case "Messages":
-			records, err := b.Queries.GetMessagesSentLeaderboard(e.Ctx, db.GetMessagesSentLeaderboardParams{
-				GuildID: int64(*e.GuildID()),
-				Offset:  0,
+	pictureReader, err := utils.ImageToReader(picture)
+	if err != nil {
+		return discord.MessageUpdate{}, err
+	}
+
+	footer := fmt.Sprintf("You're not on this leaderboard yet | Page %d/%d", page+1, pages)
+	if position > 0 {
+		footer = fmt.Sprintf("Your rank: #%d of %d | Page %d/%d", position, total, page+1, pages)
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle(title).
+		SetImage("attachment://leaderboard.png").
+		SetFooterText(footer).
+		SetColor(utils.ColorSuccess).
+		Build()
+
+	customID := func(action string) string {
+		return fmt.Sprintf("/leaderboard/%s/%s/%s/%s/%d", category, period, userID, action, page)
+	}
+
+	return discord.NewMessageUpdateBuilder().
+		SetEmbeds(embed).
+		SetFiles(discord.NewFile("leaderboard.png", title, pictureReader)).
+		AddActionRow(
+			discord.NewSecondaryButton("◀◀", customID("first")).WithDisabled(page == 0),
+			discord.NewSecondaryButton("◀", customID("back")).WithDisabled(page == 0),
+			discord.NewPrimaryButton("Jump to me", customID("me")).WithDisabled(position == 0),
+			discord.NewSecondaryButton("▶", customID("next")).WithDisabled(page >= pages-1),
+			discord.NewSecondaryButton("▶▶", customID("last")).WithDisabled(page >= pages-1),
+		).
+		Build(), nil
+}
+
+// leaderboardPage returns a page of a leaderboard and how many members are on
+// it.
+func leaderboardPage(ctx context.Context, b *mgbot.MartinGarrixBot, guildID snowflake.ID, category, period string, page int) ([]leaderboardDetails, int, error) {
+	var leaderboard []leaderboardDetails
+	var total int64
+
+	offset := int32(page * leaderboardPageSize)
+
+	switch {
+	case period != mgbot.PeriodAllTime:
+		activity := leaderboardActivity[category]
+		since, until := leaderboardPeriod(ctx, b, guildID, period)
+
+		records, err := b.Queries.GetActivityLeaderboard(ctx, db.GetActivityLeaderboardParams{
+			Metric:    activity,
+			GuildID:   int64(guildID),
+			Since:     since,
+			Until:     until,
+			RowOffset: offset,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
+
+		for _, record := range records {
+			total = record.Total
+			leaderboard = append(leaderboard, leaderboardDetails{
+				userID:  snowflake.ID(record.UserID),
+				value:   formatActivity(activity, record.Amount),
+				totalXp: record.TotalXp.Int32,
 			})
-			if err != nil {
-				return err
-			}
+		}
 
-			for _, record := range records {
-				var discordMember discord.Member
-				var ok bool
-				discordMember, ok = b.Client.Caches().Member(*e.GuildID(), snowflake.ID(record.ID))
-				if !ok {
-					discordMemberPtr, err := b.Client.Rest().GetMember(*e.GuildID(), snowflake.ID(record.ID))
-					if err != nil {
-						return err
-					}
-					discordMember = *discordMemberPtr
-				}
-
-				leaderboard = append(leaderboard, leaderboardDetails{
-					member: discordMember,
-					value:  strconv.Itoa(int(record.MessagesSent.Int32)),
-				})
-			}
+	case category == "Coins":
+		records, err := b.Queries.GetCoinsLeaderboard(ctx, db.GetCoinsLeaderboardParams{
+			GuildID: int64(guildID),
+			Offset:  offset,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
 
-		case "In Hand Coins":
-			records, err := b.Queries.GetInHandLeaderboard(e.Ctx, db.GetInHandLeaderboardParams{
-				GuildID: int64(*e.GuildID()),
-				Offset:  0,
+		for _, record := range records {
+			total = record.Total
+			leaderboard = append(leaderboard, leaderboardDetails{
+				userID:  snowflake.ID(record.ID),
+				value:   strconv.Itoa(int(record.GarrixCoins.Int64 + record.InHand.Int64)),
+				totalXp: record.TotalXp.Int32,
 			})
-			if err != nil {
-				return err
-			}
+		}
 
-			for _, record := range records {
-				var discordMember discord.Member
-				var ok bool
-				discordMember, ok = b.Client.Caches().Member(*e.GuildID(), snowflake.ID(record.ID))
-				if !ok {
-					discordMemberPtr, err := b.Client.Rest().GetMember(*e.GuildID(), snowflake.ID(record.ID))
-					if err != nil {
-						return err
-					}
-					discordMember = *discordMemberPtr
-				}
-
-				leaderboard = append(leaderboard, leaderboardDetails{
-					member: discordMember,
-					value:  strconv.Itoa(int(record.InHand.Int64)),
-				})
-			}
+	case category == "Levels":
+		records, err := b.Queries.GetLevelsLeaderboard(ctx, db.GetLevelsLeaderboardParams{
+			GuildID: int64(guildID),
+			Offset:  offset,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
 
+		for _, record := range records {
+			total = record.Total
+			leaderboard = append(leaderboard, leaderboardDetails{
+				userID:  snowflake.ID(record.ID),
+				value:   strconv.Itoa(utils.GetUserLevel(record.TotalXp.Int32)),
+				totalXp: record.TotalXp.Int32,
+			})
 		}
 
-		var description []string
+	case category == "Messages":
+		records, err := b.Queries.GetMessagesSentLeaderboard(ctx, db.GetMessagesSentLeaderboardParams{
+			GuildID: int64(guildID),
+			Offset:  offset,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
 
-		// TODO: Fix indentations? Make indentation same for all
-		for idx, leaderboardDetail := range leaderboard {
-			description = append(description, strconv.Itoa(idx+1)+". "+leaderboardDetail.member.Mention()+" - "+leaderboardDetail.value)
+		for _, record := range records {
+			total = record.Total
+			leaderboard = append(leaderboard, leaderboardDetails{
+				userID:  snowflake.ID(record.ID),
+				value:   strconv.Itoa(int(record.MessagesSent.Int32)),
+				totalXp: record.TotalXp.Int32,
+			})
 		}
 
-		embed := discord.NewEmbedBuilder().
-			SetTitle(category + " Leaderboard").
-			SetDescription(strings.Join(description, "\n")).
-			SetColor(utils.ColorSuccess).
-			Build()
+	case category == "In Hand Coins":
+		records, err := b.Queries.GetInHandLeaderboard(ctx, db.GetInHandLeaderboardParams{
+			GuildID: int64(guildID),
+			Offset:  offset,
+		})
+		if err != nil {
+			return nil, 0, err
+		}
 
-		_, err := e.UpdateInteractionResponse(
-			discord.NewMessageUpdateBuilder().
-				SetEmbeds(embed).
-				Build(),
-		)
+		for _, record := range records {
+			total = record.Total
+			leaderboard = append(leaderboard, leaderboardDetails{
+				userID:  snowflake.ID(record.ID),
+				value:   strconv.Itoa(int(record.InHand.Int64)),
+				totalXp: record.TotalXp.Int32,
+			})
+		}
+
+	case category == "Voice Time":
+		records, err := b.Queries.GetVoiceTimeLeaderboard(ctx, db.GetVoiceTimeLeaderboardParams{
+			GuildID: int64(guildID),
+			Offset:  offset,
+		})
 		if err != nil {
-			return err
+			return nil, 0, err
 		}
 
-		return nil
+		for _, record := range records {
+			total = record.Total
+			leaderboard = append(leaderboard, leaderboardDetails{
+				userID:  snowflake.ID(record.UserID),
+				value:   formatVoiceTime(record.Seconds),
+				totalXp: record.TotalXp.Int32,
+			})
+		}
+
+	default:
+		return nil, 0, fmt.Errorf("unknown leaderboard category %q", category)
+	}
+
+	// An empty page past the end doesn't know the total, so count from the start
+	if len(leaderboard) == 0 && page > 0 {
+		_, total, err := leaderboardPage(ctx, b, guildID, category, period, 0)
+		return nil, total, err
+	}
+
+	return leaderboard, int(total), nil
+}
+
+// leaderboardPosition returns where a member is on a leaderboard, 0 if they
+// aren't on it.
+func leaderboardPosition(ctx context.Context, b *mgbot.MartinGarrixBot, guildID, userID snowflake.ID, category, period string) (int64, error) {
+	var position int64
+	var err error
+
+	switch {
+	case period != mgbot.PeriodAllTime:
+		since, until := leaderboardPeriod(ctx, b, guildID, period)
+		position, err = b.Queries.GetActivityLeaderboardPosition(ctx, db.GetActivityLeaderboardPositionParams{
+			Metric:  leaderboardActivity[category],
+			GuildID: int64(guildID),
+			Since:   since,
+			Until:   until,
+			UserID:  int64(userID),
+		})
+	case category == "Coins":
+		position, err = b.Queries.GetCoinsLeaderboardPosition(ctx, db.GetCoinsLeaderboardPositionParams{
+			GuildID: int64(guildID),
+			ID:      int64(userID),
+		})
+	case category == "Levels":
+		position, err = b.Queries.GetLevelsLeaderboardPosition(ctx, db.GetLevelsLeaderboardPositionParams{
+			GuildID: int64(guildID),
+			ID:      int64(userID),
+		})
+	case category == "Messages":
+		position, err = b.Queries.GetMessagesSentLeaderboardPosition(ctx, db.GetMessagesSentLeaderboardPositionParams{
+			GuildID: int64(guildID),
+			ID:      int64(userID),
+		})
+	case category == "In Hand Coins":
+		position, err = b.Queries.GetInHandLeaderboardPosition(ctx, db.GetInHandLeaderboardPositionParams{
+			GuildID: int64(guildID),
+			ID:      int64(userID),
+		})
+	case category == "Voice Time":
+		position, err = b.Queries.GetVoiceTimeLeaderboardPosition(ctx, db.GetVoiceTimeLeaderboardPositionParams{
+			GuildID: int64(guildID),
+			UserID:  int64(userID),
+		})
+	default:
+		return 0, fmt.Errorf("unknown leaderboard category %q", category)
+	}
+
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return 0, nil
 	}
-}
\ No newline at end of file
+	return position, err
+}
+
+// leaderboardMember returns the name and avatar of a member on a leaderboard,
+// falling back to their user for members who have left the server.
+func leaderboardMember(b *mgbot.MartinGarrixBot, guildID, userID snowflake.ID) (string, string) {
+	avatarOpts := []discord.CDNOpt{discord.WithFormat(discord.FileFormatPNG), discord.WithSize(128)}
+
+	member, ok := b.Client.Caches().Member(guildID, userID)
+	if !ok {
+		discordMember, err := b.Client.Rest().GetMember(guildID, userID)
+		if err == nil {
+			member, ok = *discordMember, true
+		}
+	}
+	if ok {
+		return member.EffectiveName(), member.EffectiveAvatarURL(avatarOpts...)
+	}
+
+	user, err := b.Client.Rest().GetUser(userID)
+	if err != nil {
+		return "Unknown User", ""
+	}
+	return user.EffectiveName(), user.EffectiveAvatarURL(avatarOpts...)
+}
+
+// leaderboardPeriod returns the range of activity in a period leaderboard,
+// with the period starting in the guild's timezone.
+func leaderboardPeriod(ctx context.Context, b *mgbot.MartinGarrixBot, guildID snowflake.ID, period string) (pgtype.Timestamp, pgtype.Timestamp) {
+	now := time.Now().In(b.GuildLocation(ctx, int64(guildID)))
+	return pgtype.Timestamp{Time: mgbot.PeriodStart(period, now).UTC(), Valid: true}, mgbot.ActivityHour(now.Add(time.Hour))
+}
+
+// formatActivity formats an amount of activity for a period leaderboard.
+func formatActivity(activity string, amount int64) string {
+	switch activity {
+	case mgbot.ActivityXp:
+		return utils.Humanize(int32(amount)) + " XP"
+	case mgbot.ActivityVoice:
+		return formatVoiceTime(amount)
+	}
+	return strconv.FormatInt(amount, 10)
+}
+
+// formatVoiceTime formats seconds spent in voice as hours and minutes.
+func formatVoiceTime(seconds int64) string {
+	return fmt.Sprintf("%dh %dm", seconds/3600, seconds%3600/60)
+}
@@ -1,7 +1,6 @@
 package commands
 
 import (
-	"context"
 	"fmt"
 	"log/slog"
 	"strconv"
@@ -11,8 +10,6 @@ import (
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/handler"
 	"github.com/disgoorg/disgo/rest"
-	"github.com/disgoorg/json"
-	"github.com/disgoorg/snowflake/v2"
 	"github.com/jackc/pgx/v5/pgtype"
 	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
 	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
@@ -171,6 +168,19 @@ var moderation = discord.SlashCommandCreate{
 				},
 			},
 		},
+		moderationWarnCommand,
+		moderationWarningsCommand,
+		moderationClearWarnCommand,
+		moderationCaseGroup,
+		moderationPurgeCommand,
+		moderationMassBanCommand,
+		moderationLockCommand,
+		moderationUnlockCommand,
+		moderationLockdownCommand,
+		moderationSlowmodeCommand,
+		moderationRaidGroup,
+		moderationXpFlagsCommand,
+		moderationXpFlagsClearCommand,
 	},
 }
 
@@ -190,6 +200,14 @@ func ModerationHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
 		data := e.SlashCommandInteractionData()
 		subcommand := data.SubCommandName
 
+		if data.SubCommandGroupName != nil && *data.SubCommandGroupName == "case" {
+			return handleModerationCase(b, e)
+		}
+
+		if data.SubCommandGroupName != nil && *data.SubCommandGroupName == "raid" {
+			return handleModerationRaid(b, e)
+		}
+
 		switch *subcommand {
 		case "kick":
 			return handleKick(b, e)
@@ -207,6 +225,28 @@ func ModerationHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
 			return handleUnmute(b, e)
 		case "logs":
 			return handleLogs(b, e)
+		case "warn":
+			return handleWarn(b, e)
+		case "warnings":
+			return handleWarnings(b, e)
+		case "clearwarn":
+			return handleClearWarn(b, e)
+		case "purge":
+			return handlePurge(b, e)
+		case "massban":
+			return handleMassBan(b, e)
+		case "lock":
+			return handleLock(b, e)
+		case "unlock":
+			return handleUnlock(b, e)
+		case "lockdown":
+			return handleLockdown(b, e)
+		case "slowmode":
+			return handleSlowmode(b, e)
+		case "xp-flags":
+			return handleXpFlags(b, e)
+		case "xp-flags-clear":
+			return handleXpFlagsClear(b, e)
 		default:
 			return e.Respond(discord.InteractionResponseTypeCreateMessage,
 				discord.NewMessageCreateBuilder().
@@ -218,46 +258,6 @@ func ModerationHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
 	}
 }
 
-// Helper function to create a modlog entry
-func createModlog(ctx context.Context, queries *db.Queries, params db.CreateModlogParams) error {
-	_, err := queries.CreateModlog(ctx, params)
-	return err
-}
-
-// Helper function to send modlog to channel
-func sendModlogToChannel(b *mgbot.MartinGarrixBot, guildID, userID, moderatorID snowflake.ID, logType, reason string, expiresAt *time.Time) {
-	config, err := b.Queries.GetGuild(context.Background(), int64(guildID))
-	if err != nil || !config.ModlogsChannel.Valid {
-		return
-	}
-
-	reasonText := reason
-	if reasonText == "" {
-		reasonText = "No reason provided"
-	}
-
-	embed := discord.NewEmbedBuilder().
-		SetTitle(fmt.Sprintf("Moderation Action: %s", strings.ToUpper(logType))).
-		AddField("User", fmt.Sprintf("<@%d>", userID), true).
-		AddField("Moderator", fmt.Sprintf("<@%d>", moderatorID), true).
-		AddField("Reason", reasonText, false).
-		SetTimestamp(time.Now()).
-		SetColor(utils.ColorWarning)
-
-	if expiresAt != nil {
-		embed.AddField("Expires", fmt.Sprintf("<t:%d:R>", expiresAt.Unix()), false)
-	}
-
-	_, err = b.Client.Rest().CreateMessage(snowflake.ID(config.ModlogsChannel.Int64),
-		discord.NewMessageCreateBuilder().
-			SetEmbeds(embed.Build()).
-			Build(),
-	)
-	if err != nil {
-		slog.Error("Failed to send modlog to channel", slog.Any("err", err))
-	}
-}
-
 // parseDuration parses duration strings like "1h", "2d", "1w"
 func parseDuration(durationStr string) (time.Duration, error) {
 	durationStr = strings.TrimSpace(strings.ToLower(durationStr))
@@ -311,9 +311,9 @@ func handleKick(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
 		)
 	}
 
-	// Create modlog entry
+	// Create modlog entry and send it to the modlog channel
 	reasonText := pgtype.Text{String: reason, Valid: reason != ""}
-	err = createModlog(e.Ctx, b.Queries, db.CreateModlogParams{
+	_, err = b.LogModAction(e.Ctx, db.CreateModlogParams{
 		UserID:      int64(targetUser.ID),
 		ModeratorID: int64(moderator.ID),
 		GuildID:     int64(guildID),
@@ -327,9 +327,6 @@ func handleKick(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
 		slog.Error("Failed to create modlog entry", slog.Any("err", err))
 	}
 
-	// Send to modlog channel
-	sendModlogToChannel(b, guildID, targetUser.ID, moderator.ID, "kick", reason, nil)
-
 	return e.Respond(discord.InteractionResponseTypeCreateMessage,
 		discord.NewMessageCreateBuilder().
 			SetEmbeds(utils.SuccessEmbed("User Kicked", fmt.Sprintf("<@%d> has been kicked", targetUser.ID))).
@@ -367,9 +364,12 @@ func handleBan(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
 		)
 	}
 
-	// Create modlog entry
+	// The ban is permanent now, so a tempban expiring mustn't lift it
+	b.DeactivateTempBans(e.Ctx, guildID, targetUser.ID)
+
+	// Create modlog entry and send it to the modlog channel
 	reasonText := pgtype.Text{String: reason, Valid: reason != ""}
-	err = createModlog(e.Ctx, b.Queries, db.CreateModlogParams{
+	_, err = b.LogModAction(e.Ctx, db.CreateModlogParams{
 		UserID:      int64(targetUser.ID),
 		ModeratorID: int64(moderator.ID),
 		GuildID:     int64(guildID),
@@ -383,9 +383,6 @@ func handleBan(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
 		slog.Error("Failed to create modlog entry", slog.Any("err", err))
 	}
 
-	// Send to modlog channel
-	sendModlogToChannel(b, guildID, targetUser.ID, moderator.ID, "ban", reason, nil)
-
 	return e.Respond(discord.InteractionResponseTypeCreateMessage,
 		discord.NewMessageCreateBuilder().
 			SetEmbeds(utils.SuccessEmbed("User Banned", fmt.Sprintf("<@%d> has been banned", targetUser.ID))).
@@ -423,11 +420,9 @@ func handleTempBan(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
 		)
 	}
 
-	expiresAt := time.Now().Add(duration)
-
 	// Ban the user
 	deleteMessageDuration := time.Duration(deleteMessageDays) * 24 * time.Hour
-	err = b.Client.Rest().AddBan(guildID, targetUser.ID, deleteMessageDuration, rest.WithReason(fmt.Sprintf("Tempban (%s): %s", durationStr, reason)))
+	expiresAt, err := b.TempBanMember(e.Ctx, guildID, targetUser.ID, moderator.ID, duration, deleteMessageDuration, reason)
 	if err != nil {
 		return e.Respond(discord.InteractionResponseTypeCreateMessage,
 			discord.NewMessageCreateBuilder().
@@ -437,25 +432,6 @@ func handleTempBan(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
 		)
 	}
 
-	// Create modlog entry
-	reasonText := pgtype.Text{String: reason, Valid: reason != ""}
-	err = createModlog(e.Ctx, b.Queries, db.CreateModlogParams{
-		UserID:      int64(targetUser.ID),
-		ModeratorID: int64(moderator.ID),
-		GuildID:     int64(guildID),
-		LogType:     "tempban",
-		Reason:      reasonText,
-		ExpiresAt:   pgtype.Timestamp{Time: expiresAt, Valid: true},
-		Active:      pgtype.Bool{Bool: true, Valid: true},
-	})
-
-	if err != nil {
-		slog.Error("Failed to create modlog entry", slog.Any("err", err))
-	}
-
-	// Send to modlog channel
-	sendModlogToChannel(b, guildID, targetUser.ID, moderator.ID, "tempban", reason, &expiresAt)
-
 	return e.Respond(discord.InteractionResponseTypeCreateMessage,
 		discord.NewMessageCreateBuilder().
 			SetEmbeds(utils.SuccessEmbed("User Temporarily Banned",
@@ -499,11 +475,13 @@ func handleSoftBan(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
 	if err != nil {
 		slog.Error("Failed to unban after softban", slog.Any("err", err))
 		// Continue anyway, the ban was successful
+	} else {
+		b.DeactivateTempBans(e.Ctx, guildID, targetUser.ID)
 	}
 
-	// Create modlog entry
+	// Create modlog entry and send it to the modlog channel
 	reasonText := pgtype.Text{String: reason, Valid: reason != ""}
-	err = createModlog(e.Ctx, b.Queries, db.CreateModlogParams{
+	_, err = b.LogModAction(e.Ctx, db.CreateModlogParams{
 		UserID:      int64(targetUser.ID),
 		ModeratorID: int64(moderator.ID),
 		GuildID:     int64(guildID),
@@ -517,9 +495,6 @@ func handleSoftBan(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
 		slog.Error("Failed to create modlog entry", slog.Any("err", err))
 	}
 
-	// Send to modlog channel
-	sendModlogToChannel(b, guildID, targetUser.ID, moderator.ID, "softban", reason, nil)
-
 	return e.Respond(discord.InteractionResponseTypeCreateMessage,
 		discord.NewMessageCreateBuilder().
 			SetEmbeds(utils.SuccessEmbed("User Softbanned",
@@ -548,17 +523,11 @@ func handleUnban(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
 	}
 
 	// Deactivate any active tempbans
-	activeTempBan, err := b.Queries.GetActiveTempBanForUser(e.Ctx, db.GetActiveTempBanForUserParams{
-		UserID:  int64(targetUser.ID),
-		GuildID: int64(guildID),
-	})
-	if err == nil {
-		_ = b.Queries.DeactivateModlog(e.Ctx, activeTempBan.ID)
-	}
+	b.DeactivateTempBans(e.Ctx, guildID, targetUser.ID)
 
-	// Create modlog entry
+	// Create modlog entry and send it to the modlog channel
 	reasonText := pgtype.Text{String: reason, Valid: reason != ""}
-	err = createModlog(e.Ctx, b.Queries, db.CreateModlogParams{
+	_, err = b.LogModAction(e.Ctx, db.CreateModlogParams{
 		UserID:      int64(targetUser.ID),
 		ModeratorID: int64(moderator.ID),
 		GuildID:     int64(guildID),
@@ -572,9 +541,6 @@ func handleUnban(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
 		slog.Error("Failed to create modlog entry", slog.Any("err", err))
 	}
 
-	// Send to modlog channel
-	sendModlogToChannel(b, guildID, targetUser.ID, moderator.ID, "unban", reason, nil)
-
 	return e.Respond(discord.InteractionResponseTypeCreateMessage,
 		discord.NewMessageCreateBuilder().
 			SetEmbeds(utils.SuccessEmbed("User Unbanned", fmt.Sprintf("<@%d> has been unbanned", targetUser.ID))).
@@ -603,8 +569,7 @@ func handleMute(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
 	}
 
 	// Discord timeout max is 28 days
-	maxDuration := 28 * 24 * time.Hour
-	if duration > maxDuration {
+	if duration > mgbot.MaxMuteDuration {
 		return e.Respond(discord.InteractionResponseTypeCreateMessage,
 			discord.NewMessageCreateBuilder().
 				SetEmbeds(utils.FailureEmbed("Duration Too Long", "Maximum timeout duration is 28 days")).
@@ -613,16 +578,8 @@ func handleMute(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
 		)
 	}
 
-	expiresAt := time.Now().Add(duration)
-
 	// Timeout the user
-	timeoutUntil := json.NewNullable(expiresAt)
-	_, err = b.Client.Rest().UpdateMember(guildID, targetUser.ID,
-		discord.MemberUpdate{
-			CommunicationDisabledUntil: &timeoutUntil,
-		},
-		rest.WithReason(reason),
-	)
+	expiresAt, err := b.MuteMember(e.Ctx, guildID, targetUser.ID, moderator.ID, duration, reason)
 	if err != nil {
 		return e.Respond(discord.InteractionResponseTypeCreateMessage,
 			discord.NewMessageCreateBuilder().
@@ -632,25 +589,6 @@ func handleMute(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
 		)
 	}
 
-	// Create modlog entry
-	reasonText := pgtype.Text{String: reason, Valid: reason != ""}
-	err = createModlog(e.Ctx, b.Queries, db.CreateModlogParams{
-		UserID:      int64(targetUser.ID),
-		ModeratorID: int64(moderator.ID),
-		GuildID:     int64(guildID),
-		LogType:     "mute",
-		Reason:      reasonText,
-		ExpiresAt:   pgtype.Timestamp{Time: expiresAt, Valid: true},
-		Active:      pgtype.Bool{Bool: true, Valid: true},
-	})
-
-	if err != nil {
-		slog.Error("Failed to create modlog entry", slog.Any("err", err))
-	}
-
-	// Send to modlog channel
-	sendModlogToChannel(b, guildID, targetUser.ID, moderator.ID, "mute", reason, &expiresAt)
-
 	return e.Respond(discord.InteractionResponseTypeCreateMessage,
 		discord.NewMessageCreateBuilder().
 			SetEmbeds(utils.SuccessEmbed("User Muted",
@@ -692,9 +630,9 @@ func handleUnmute(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
 		_ = b.Queries.DeactivateModlog(e.Ctx, activeMute.ID)
 	}
 
-	// Create modlog entry
+	// Create modlog entry and send it to the modlog channel
 	reasonText := pgtype.Text{String: reason, Valid: reason != ""}
-	err = createModlog(e.Ctx, b.Queries, db.CreateModlogParams{
+	_, err = b.LogModAction(e.Ctx, db.CreateModlogParams{
 		UserID:      int64(targetUser.ID),
 		ModeratorID: int64(moderator.ID),
 		GuildID:     int64(guildID),
@@ -708,9 +646,6 @@ func handleUnmute(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
 		slog.Error("Failed to create modlog entry", slog.Any("err", err))
 	}
 
-	// Send to modlog channel
-	sendModlogToChannel(b, guildID, targetUser.ID, moderator.ID, "unmute", reason, nil)
-
 	return e.Respond(discord.InteractionResponseTypeCreateMessage,
 		discord.NewMessageCreateBuilder().
 			SetEmbeds(utils.SuccessEmbed("User Unmuted", fmt.Sprintf("<@%d> has been unmuted", targetUser.ID))).
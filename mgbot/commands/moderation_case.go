@@ -0,0 +1,187 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/json"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+var caseNumberOption = discord.ApplicationCommandOptionInt{
+	Name:        "case",
+	Description: "The case number",
+	Required:    true,
+	MinValue:    json.Ptr(1),
+}
+
+var moderationCaseGroup = discord.ApplicationCommandOptionSubCommandGroup{
+	Name:        "case",
+	Description: "Look up and correct moderation cases",
+	Options: []discord.ApplicationCommandOptionSubCommand{
+		{
+			Name:        "view",
+			Description: "View a case and its edit history",
+			Options: []discord.ApplicationCommandOption{
+				caseNumberOption,
+			},
+		},
+		{
+			Name:        "reason",
+			Description: "Change the reason of a case",
+			Options: []discord.ApplicationCommandOption{
+				caseNumberOption,
+				discord.ApplicationCommandOptionString{
+					Name:        "reason",
+					Description: "The new reason",
+					Required:    true,
+					MaxLength:   json.Ptr(mgbot.ModlogReasonMaxLength),
+				},
+			},
+		},
+		{
+			Name:        "delete",
+			Description: "Void a case logged by mistake, it is kept but no longer counts",
+			Options: []discord.ApplicationCommandOption{
+				caseNumberOption,
+				discord.ApplicationCommandOptionString{
+					Name:        "reason",
+					Description: "Why the case is being voided",
+					Required:    false,
+					MaxLength:   json.Ptr(mgbot.ModlogReasonMaxLength),
+				},
+			},
+		},
+	},
+}
+
+func handleModerationCase(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	switch *e.SlashCommandInteractionData().SubCommandName {
+	case "view":
+		return handleCaseView(b, e)
+	case "reason":
+		return handleCaseReason(b, e)
+	case "delete":
+		return handleCaseDelete(b, e)
+	}
+
+	return respondModerationError(e, "Invalid Command", "Unknown subcommand")
+}
+
+func handleCaseView(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	caseNumber := e.SlashCommandInteractionData().Int("case")
+	guildID := *e.GuildID()
+
+	modlog, err := b.Queries.GetModlogByCase(e.Ctx, db.GetModlogByCaseParams{
+		GuildID:    int64(guildID),
+		CaseNumber: int32(caseNumber),
+	})
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return respondModerationError(e, "Not Found", fmt.Sprintf("There is no case #%d.", caseNumber))
+	} else if err != nil {
+		slog.Error("Failed to get case", slog.Any("err", err))
+		return respondModerationError(e, "Error", "Failed to fetch the case")
+	}
+
+	embeds := []discord.Embed{mgbot.ModlogEmbed(modlog)}
+
+	edits, err := b.Queries.GetModlogEdits(e.Ctx, modlog.ID)
+	if err != nil {
+		slog.Error("Failed to get case edits", slog.Any("err", err))
+	}
+	if len(edits) > 0 {
+		var sb strings.Builder
+		for _, edit := range edits {
+			change := "voided"
+			if edit.EditType == mgbot.ModlogEditReason {
+				change = "changed the reason"
+			}
+			sb.WriteString(fmt.Sprintf("<t:%d:R> <@%d> %s", edit.EditedAt.Time.Unix(), edit.EditorID, change))
+			if edit.NewReason.Valid {
+				sb.WriteString(": " + utils.CutString(edit.NewReason.String, 100))
+			}
+			sb.WriteString("\n")
+		}
+
+		embeds = append(embeds, discord.NewEmbedBuilder().
+			SetTitle("Edit History").
+			SetDescription(sb.String()).
+			SetColor(utils.ColorInfo).
+			Build())
+	}
+
+	messageBuilder := discord.NewMessageCreateBuilder().
+		SetEmbeds(embeds...).
+		SetEphemeral(true)
+
+	if modlog.LogChannelID.Valid && modlog.LogMessageID.Valid {
+		messageBuilder.AddActionRow(discord.NewLinkButton("View in modlogs",
+			fmt.Sprintf("https://discord.com/channels/%d/%d/%d", guildID, modlog.LogChannelID.Int64, modlog.LogMessageID.Int64)))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage, messageBuilder.Build())
+}
+
+func handleCaseReason(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	caseNumber := data.Int("case")
+	reason := strings.TrimSpace(data.String("reason"))
+
+	if reason == "" {
+		return respondModerationError(e, "Invalid Reason", "Reasons can't be empty.")
+	}
+
+	modlog, err := b.EditModlogReason(e.Ctx, *e.GuildID(), int32(caseNumber), e.User().ID, reason)
+	if err != nil {
+		return respondCaseError(e, caseNumber, err)
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Case Updated",
+				fmt.Sprintf("The reason of case #%d (%s of <@%d>) is now: %s", modlog.CaseNumber, modlog.LogType, modlog.UserID, reason))).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func handleCaseDelete(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	caseNumber := data.Int("case")
+	reason := strings.TrimSpace(data.String("reason"))
+
+	modlog, err := b.VoidModlog(e.Ctx, *e.GuildID(), int32(caseNumber), e.User().ID, reason)
+	if err != nil {
+		return respondCaseError(e, caseNumber, err)
+	}
+
+	description := fmt.Sprintf("Case #%d (%s of <@%d>) has been voided.", modlog.CaseNumber, modlog.LogType, modlog.UserID)
+	if modlog.Active.Bool && modlog.ExpiresAt.Valid {
+		description += fmt.Sprintf(" This doesn't lift the %s, use `/moderation un%s` for that.", modlog.LogType, strings.TrimPrefix(modlog.LogType, "temp"))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Case Voided", description)).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func respondCaseError(e *handler.CommandEvent, caseNumber int, err error) error {
+	switch {
+	case errors.Is(err, db.ErrRecordNotFound):
+		return respondModerationError(e, "Not Found", fmt.Sprintf("There is no case #%d.", caseNumber))
+	case errors.Is(err, mgbot.ErrCaseVoided):
+		return respondModerationError(e, "Already Voided", fmt.Sprintf("Case #%d has already been voided.", caseNumber))
+	}
+
+	slog.Error("Failed to edit case", slog.Any("err", err))
+	return respondModerationError(e, "Error", fmt.Sprintf("Failed to update case #%d", caseNumber))
+}
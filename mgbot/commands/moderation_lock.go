@@ -0,0 +1,303 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+var lockDurationOption = discord.ApplicationCommandOptionString{
+	Name:        "duration",
+	Description: "Unlock automatically after this long (e.g., 30m, 1h), stays locked if not given",
+	Required:    false,
+}
+
+var moderationLockCommand = discord.ApplicationCommandOptionSubCommand{
+	Name:        "lock",
+	Description: "Stop @everyone from talking in a channel",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionChannel{
+			Name:         "channel",
+			Description:  "The channel to lock, this channel if not given",
+			Required:     false,
+			ChannelTypes: lockableChannelTypes,
+		},
+		lockDurationOption,
+		discord.ApplicationCommandOptionString{
+			Name:        "reason",
+			Description: "Reason for the lock",
+			Required:    false,
+		},
+	},
+}
+
+var moderationUnlockCommand = discord.ApplicationCommandOptionSubCommand{
+	Name:        "unlock",
+	Description: "Restore a locked channel's permissions",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionChannel{
+			Name:         "channel",
+			Description:  "The channel to unlock, this channel if not given",
+			Required:     false,
+			ChannelTypes: lockableChannelTypes,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "reason",
+			Description: "Reason for the unlock",
+			Required:    false,
+		},
+	},
+}
+
+var moderationLockdownCommand = discord.ApplicationCommandOptionSubCommand{
+	Name:        "lockdown",
+	Description: "Lock or unlock all of the server's lockdown channels",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionString{
+			Name:        "action",
+			Description: "Whether to start or end the lockdown",
+			Required:    true,
+			Choices: []discord.ApplicationCommandOptionChoiceString{
+				{Name: "Start", Value: "start"},
+				{Name: "End", Value: "end"},
+			},
+		},
+		lockDurationOption,
+		discord.ApplicationCommandOptionString{
+			Name:        "reason",
+			Description: "Reason for the lockdown",
+			Required:    false,
+		},
+	},
+}
+
+var moderationSlowmodeCommand = discord.ApplicationCommandOptionSubCommand{
+	Name:        "slowmode",
+	Description: "Set how long members wait between messages in a channel",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionString{
+			Name:        "delay",
+			Description: "The delay (e.g., 5s, 1m, 1h), 0 turns slowmode off",
+			Required:    true,
+		},
+		discord.ApplicationCommandOptionChannel{
+			Name:        "channel",
+			Description: "The channel, this channel if not given",
+			Required:    false,
+			ChannelTypes: []discord.ChannelType{
+				discord.ChannelTypeGuildText,
+				discord.ChannelTypeGuildVoice,
+				discord.ChannelTypeGuildStageVoice,
+				discord.ChannelTypeGuildForum,
+			},
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "reason",
+			Description: "Reason for the slowmode",
+			Required:    false,
+		},
+	},
+}
+
+func handleLock(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	channelID := optChannelID(e, "channel")
+	reason := data.String("reason")
+
+	duration, err := parseLockDuration(data)
+	if err != nil {
+		return respondModerationError(e, "Invalid Duration", err.Error())
+	}
+
+	err = b.LockChannel(e.Ctx, *e.GuildID(), channelID, e.User().ID, duration, reason)
+	if errors.Is(err, mgbot.ErrChannelLocked) {
+		return respondModerationError(e, "Already Locked", fmt.Sprintf("<#%d> is already locked.", channelID))
+	} else if err != nil {
+		slog.Error("Failed to lock channel", slog.Any("err", err))
+		return respondModerationError(e, "Lock Failed", fmt.Sprintf("Failed to lock <#%d>: %s", channelID, err.Error()))
+	}
+
+	description := fmt.Sprintf("<#%d> has been locked.", channelID)
+	if duration > 0 {
+		description = fmt.Sprintf("<#%d> has been locked until <t:%d:t>.", channelID, time.Now().Add(duration).Unix())
+	}
+	if reason != "" {
+		description += "\n**Reason:** " + reason
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(discord.NewEmbedBuilder().
+				SetTitle("Channel Locked").
+				SetDescription(description).
+				SetColor(utils.ColorWarning).
+				Build()).
+			Build(),
+	)
+}
+
+func handleUnlock(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	channelID := optChannelID(e, "channel")
+	reason := e.SlashCommandInteractionData().String("reason")
+
+	err := b.UnlockChannel(e.Ctx, *e.GuildID(), channelID, e.User().ID, reason)
+	if errors.Is(err, mgbot.ErrChannelNotLocked) {
+		return respondModerationError(e, "Not Locked", fmt.Sprintf("<#%d> isn't locked.", channelID))
+	} else if err != nil {
+		slog.Error("Failed to unlock channel", slog.Any("err", err))
+		return respondModerationError(e, "Unlock Failed", fmt.Sprintf("Failed to unlock <#%d>: %s", channelID, err.Error()))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Channel Unlocked", fmt.Sprintf("<#%d> has been unlocked.", channelID))).
+			Build(),
+	)
+}
+
+func handleLockdown(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	action := data.String("action")
+	reason := data.String("reason")
+
+	duration, err := parseLockDuration(data)
+	if err != nil {
+		return respondModerationError(e, "Invalid Duration", err.Error())
+	}
+
+	// Locking every channel can take longer than an interaction allows
+	if err := e.DeferCreateMessage(false); err != nil {
+		return err
+	}
+
+	var result mgbot.LockdownResult
+	if action == "end" {
+		result, err = b.EndLockdown(e.Ctx, *e.GuildID(), e.User().ID, reason)
+	} else {
+		result, err = b.Lockdown(e.Ctx, *e.GuildID(), e.User().ID, duration, reason)
+	}
+
+	var embed discord.Embed
+	switch {
+	case errors.Is(err, mgbot.ErrNoLockdownChannels):
+		embed = utils.FailureEmbed("No Lockdown Channels", "Set the channels to lock with `/config lockdown-channels add` first.")
+	case errors.Is(err, mgbot.ErrNoLockdown):
+		embed = utils.FailureEmbed("No Lockdown", "There is no lockdown to end.")
+	case err != nil:
+		slog.Error("Failed to update lockdown", slog.Any("err", err))
+		embed = utils.FailureEmbed("Lockdown Failed", err.Error())
+	default:
+		embed = lockdownEmbed(action, duration, reason, result)
+	}
+
+	_, err = e.UpdateInteractionResponse(discord.NewMessageUpdateBuilder().
+		SetEmbeds(embed).
+		Build())
+	return err
+}
+
+func handleSlowmode(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	channelID := optChannelID(e, "channel")
+	reason := data.String("reason")
+
+	var delay time.Duration
+	if value := strings.TrimSpace(strings.ToLower(data.String("delay"))); value != "0" && value != "off" {
+		var err error
+		delay, err = parseDuration(value)
+		if err != nil {
+			return respondModerationError(e, "Invalid Delay", err.Error())
+		}
+		if delay > mgbot.MaxSlowmode {
+			return respondModerationError(e, "Delay Too Long", "Slowmode can be at most 6 hours.")
+		}
+	}
+
+	if err := b.SetSlowmode(e.Ctx, *e.GuildID(), channelID, e.User().ID, delay, reason); err != nil {
+		slog.Error("Failed to set slowmode", slog.Any("err", err))
+		return respondModerationError(e, "Slowmode Failed", fmt.Sprintf("Failed to set slowmode in <#%d>: %s", channelID, err.Error()))
+	}
+
+	description := fmt.Sprintf("Slowmode in <#%d> is now off.", channelID)
+	if delay > 0 {
+		description = fmt.Sprintf("Members in <#%d> can now send a message every %s.", channelID, mgbot.FormatDuration(delay))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Slowmode Updated", description)).
+			Build(),
+	)
+}
+
+func lockdownEmbed(action string, duration time.Duration, reason string, result mgbot.LockdownResult) discord.Embed {
+	embed := discord.NewEmbedBuilder()
+	if action == "end" {
+		embed.SetTitle("Lockdown Ended").
+			SetDescription(fmt.Sprintf("Unlocked %d channel(s).", len(result.Channels))).
+			SetColor(utils.ColorSuccess)
+	} else {
+		description := fmt.Sprintf("Locked %d channel(s).", len(result.Channels))
+		if duration > 0 {
+			description += fmt.Sprintf(" The lockdown ends <t:%d:R>.", time.Now().Add(duration).Unix())
+		}
+		embed.SetTitle("Server Locked Down").
+			SetDescription(description).
+			SetColor(utils.ColorDanger)
+	}
+
+	if reason != "" {
+		embed.AddField("Reason", reason, false)
+	}
+	if len(result.Skipped) > 0 {
+		embed.AddField("Already Locked", channelMentions(result.Skipped), false)
+	}
+	if len(result.Failed) > 0 {
+		embed.AddField("Failed", channelMentions(result.Failed)+"\nThe bot may be missing Manage Roles there.", false)
+	}
+
+	return embed.Build()
+}
+
+// optChannelID returns the channel option, or the channel the command was
+// used in if it wasn't given.
+func optChannelID(e *handler.CommandEvent, name string) snowflake.ID {
+	if channel, ok := e.SlashCommandInteractionData().OptChannel(name); ok {
+		return channel.ID
+	}
+	return e.Channel().ID()
+}
+
+// parseLockDuration reads the optional duration of a lock, which is 0 if it
+// wasn't given.
+func parseLockDuration(data discord.SlashCommandInteractionData) (time.Duration, error) {
+	value := data.String("duration")
+	if value == "" {
+		return 0, nil
+	}
+
+	duration, err := parseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if duration < time.Minute {
+		return 0, errors.New("locks must last at least a minute")
+	}
+	return duration, nil
+}
+
+func channelMentions(channelIDs []snowflake.ID) string {
+	mentions := make([]string, len(channelIDs))
+	for i, channelID := range channelIDs {
+		mentions[i] = fmt.Sprintf("<#%d>", channelID)
+	}
+	return utils.CutString(strings.Join(mentions, ", "), 960)
+}
@@ -0,0 +1,260 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/json"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+// massBanMaxFileSize bounds the ID lists /moderation massban downloads.
+const massBanMaxFileSize = 1 << 20
+
+var moderationPurgeCommand = discord.ApplicationCommandOptionSubCommand{
+	Name:        "purge",
+	Description: "Bulk delete recent messages in this channel",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionInt{
+			Name:        "count",
+			Description: "The most messages to delete",
+			Required:    true,
+			MinValue:    json.Ptr(1),
+			MaxValue:    json.Ptr(mgbot.PurgeMaxMessages),
+		},
+		discord.ApplicationCommandOptionUser{
+			Name:        "user",
+			Description: "Only delete messages from this user",
+			Required:    false,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "contains",
+			Description: "Only delete messages containing this text",
+			Required:    false,
+		},
+		discord.ApplicationCommandOptionBool{
+			Name:        "bots",
+			Description: "Only delete messages from bots",
+			Required:    false,
+		},
+		discord.ApplicationCommandOptionBool{
+			Name:        "attachments",
+			Description: "Only delete messages with attachments",
+			Required:    false,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "before",
+			Description: "Only delete messages before this message ID",
+			Required:    false,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "after",
+			Description: "Only delete messages after this message ID",
+			Required:    false,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "reason",
+			Description: "Reason for the purge",
+			Required:    false,
+		},
+	},
+}
+
+var moderationMassBanCommand = discord.ApplicationCommandOptionSubCommand{
+	Name:        "massban",
+	Description: "Ban many users at once, such as the accounts in a raid",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionString{
+			Name:        "ids",
+			Description: "User IDs separated by spaces or commas",
+			Required:    false,
+		},
+		discord.ApplicationCommandOptionAttachment{
+			Name:        "file",
+			Description: "A text file of user IDs",
+			Required:    false,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "reason",
+			Description: "Reason for the bans",
+			Required:    false,
+		},
+		discord.ApplicationCommandOptionInt{
+			Name:        "delete_message_days",
+			Description: "Number of days of messages to delete (0-7)",
+			Required:    false,
+			MinValue:    json.Ptr(0),
+			MaxValue:    json.Ptr(7),
+		},
+	},
+}
+
+func handlePurge(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	reason := data.String("reason")
+
+	filter := mgbot.PurgeFilter{
+		Limit:           data.Int("count"),
+		Contains:        data.String("contains"),
+		BotsOnly:        data.Bool("bots"),
+		AttachmentsOnly: data.Bool("attachments"),
+	}
+	if user, ok := data.OptUser("user"); ok {
+		filter.UserID = user.ID
+	}
+
+	var ok bool
+	if filter.Before, ok = parseMessageIDOption(data, "before"); !ok {
+		return respondModerationError(e, "Invalid Message ID", fmt.Sprintf("`%s` isn't a message ID or link.", data.String("before")))
+	}
+	if filter.After, ok = parseMessageIDOption(data, "after"); !ok {
+		return respondModerationError(e, "Invalid Message ID", fmt.Sprintf("`%s` isn't a message ID or link.", data.String("after")))
+	}
+	if filter.Before != 0 && filter.After != 0 && filter.After >= filter.Before {
+		return respondModerationError(e, "Invalid Range", "The `after` message must be older than the `before` message.")
+	}
+
+	if err := e.DeferCreateMessage(true); err != nil {
+		return err
+	}
+
+	result, err := b.PurgeMessages(e.Ctx, *e.GuildID(), e.Channel().ID(), e.User().ID, filter, reason)
+	if err != nil {
+		_, _ = e.UpdateInteractionResponse(discord.NewMessageUpdateBuilder().
+			SetEmbeds(utils.FailureEmbed("Purge Failed",
+				fmt.Sprintf("Deleted %d messages before failing: %s", result.Deleted, err.Error()))).
+			Build())
+		return nil
+	}
+
+	description := fmt.Sprintf("Deleted %d message(s).", result.Deleted)
+	if result.Deleted == 0 {
+		description = "No messages matched."
+	}
+	if result.ReachedMaxAge {
+		description += " Messages older than two weeks can't be bulk deleted and were left alone."
+	}
+
+	_, err = e.UpdateInteractionResponse(discord.NewMessageUpdateBuilder().
+		SetEmbeds(utils.SuccessEmbed("Messages Purged", description)).
+		Build())
+	return err
+}
+
+func handleMassBan(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	reason := data.String("reason")
+	deleteMessageDays := data.Int("delete_message_days")
+
+	file, hasFile := data.OptAttachment("file")
+	if data.String("ids") == "" && !hasFile {
+		return respondModerationError(e, "No Users", "Give user IDs or a file of them to ban.")
+	}
+	if hasFile && file.Size > massBanMaxFileSize {
+		return respondModerationError(e, "File Too Large",
+			fmt.Sprintf("ID lists can be at most %d KB.", massBanMaxFileSize>>10))
+	}
+
+	if err := e.DeferCreateMessage(true); err != nil {
+		return err
+	}
+
+	update := func(embed discord.Embed) error {
+		_, err := e.UpdateInteractionResponse(discord.NewMessageUpdateBuilder().
+			SetEmbeds(embed).
+			Build())
+		return err
+	}
+
+	text := data.String("ids")
+	if hasFile {
+		content, err := downloadMassBanList(e.Ctx, file.URL)
+		if err != nil {
+			return update(utils.FailureEmbed("Mass Ban Failed", fmt.Sprintf("Couldn't read `%s`: %s", file.Filename, err.Error())))
+		}
+		text += "\n" + content
+	}
+
+	// Never ban the moderator or the bot, however the list was put together
+	var userIDs []snowflake.ID
+	for _, id := range mgbot.ParseUserIDs(text) {
+		if id != e.User().ID && id != b.Client.ID() {
+			userIDs = append(userIDs, id)
+		}
+	}
+
+	if len(userIDs) == 0 {
+		return update(utils.FailureEmbed("No Users", "No user IDs were found."))
+	}
+	if len(userIDs) > mgbot.MassBanMaxUsers {
+		return update(utils.FailureEmbed("Too Many Users",
+			fmt.Sprintf("Found %d user IDs, but at most %d can be banned at once.", len(userIDs), mgbot.MassBanMaxUsers)))
+	}
+
+	deleteMessages := time.Duration(deleteMessageDays) * 24 * time.Hour
+	result, err := b.MassBan(e.Ctx, *e.GuildID(), e.User().ID, userIDs, deleteMessages, reason)
+	if err != nil {
+		return update(utils.FailureEmbed("Mass Ban Failed", fmt.Sprintf("Failed to ban any users: %s", err.Error())))
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle("Mass Ban Complete").
+		SetColor(utils.ColorSuccess).
+		AddField("Banned", fmt.Sprint(len(result.Banned)), true).
+		AddField("Failed", fmt.Sprint(len(result.Failed)), true)
+
+	if len(result.Failed) > 0 {
+		failed := make([]string, len(result.Failed))
+		for i, id := range result.Failed {
+			failed[i] = id.String()
+		}
+		embed.AddField("Couldn't Ban",
+			utils.CutString(strings.Join(failed, ", "), 960)+"\nThey may already be banned or rank above the bot.",
+			false)
+	}
+
+	return update(embed.Build())
+}
+
+// parseMessageIDOption reads an optional message ID or link option, returning
+// 0 if it wasn't given.
+func parseMessageIDOption(data discord.SlashCommandInteractionData, name string) (snowflake.ID, bool) {
+	value := strings.TrimSpace(data.String(name))
+	if value == "" {
+		return 0, true
+	}
+
+	// Message links end with the message ID
+	value = value[strings.LastIndex(value, "/")+1:]
+	id, err := snowflake.Parse(value)
+	return id, err == nil
+}
+
+func downloadMassBanList(ctx context.Context, url string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New(resp.Status)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(resp.Body, massBanMaxFileSize))
+	return string(content), err
+}
@@ -0,0 +1,158 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+var moderationRaidGroup = discord.ApplicationCommandOptionSubCommandGroup{
+	Name:        "raid",
+	Description: "Handle raids on the server",
+	Options: []discord.ApplicationCommandOptionSubCommand{
+		{
+			Name:        "start",
+			Description: "Turn raid mode on, dealing with new joins as raid detection is configured",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionString{
+					Name:        "duration",
+					Description: "How long raid mode stays on (e.g., 10m, 1h), the configured duration if not given",
+					Required:    false,
+				},
+				discord.ApplicationCommandOptionString{
+					Name:        "reason",
+					Description: "Reason for raid mode",
+					Required:    false,
+				},
+			},
+		},
+		{
+			Name:        "end",
+			Description: "Turn raid mode off",
+		},
+		{
+			Name:        "history",
+			Description: "View the server's recent raids",
+		},
+	},
+}
+
+func handleModerationRaid(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	switch *e.SlashCommandInteractionData().SubCommandName {
+	case "start":
+		return handleRaidStart(b, e)
+	case "end":
+		return handleRaidEnd(b, e)
+	case "history":
+		return handleRaidHistory(b, e)
+	}
+
+	return respondModerationError(e, "Invalid Command", "Unknown subcommand")
+}
+
+func handleRaidStart(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+
+	var duration time.Duration
+	if value := data.String("duration"); value != "" {
+		var err error
+		duration, err = parseDuration(value)
+		if err != nil {
+			return respondModerationError(e, "Invalid Duration", err.Error())
+		}
+		if duration < time.Minute || duration > raidModeMaxDuration {
+			return respondModerationError(e, "Invalid Duration", "Raid mode has to last between a minute and a day.")
+		}
+	}
+
+	event, err := b.StartRaidMode(e.Ctx, *e.GuildID(), e.User().ID, duration, data.String("reason"))
+	if errors.Is(err, mgbot.ErrRaidModeActive) {
+		return respondModerationError(e, "Already On", "Raid mode is already on.")
+	} else if err != nil {
+		slog.Error("Failed to start raid mode", slog.Any("err", err))
+		return respondModerationError(e, "Raid Mode Failed", fmt.Sprintf("Failed to turn raid mode on: %s", err.Error()))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Raid Mode On",
+				fmt.Sprintf("%s until <t:%d:t>.", mgbot.DescribeRaidAction(event.Action, event.TimeoutSeconds), event.EndsAt.Time.Unix()))).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func handleRaidEnd(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	counts, err := b.EndRaidMode(e.Ctx, *e.GuildID(), e.User().ID)
+	if errors.Is(err, mgbot.ErrRaidModeInactive) {
+		return respondModerationError(e, "Not On", "Raid mode isn't on.")
+	} else if err != nil {
+		slog.Error("Failed to end raid mode", slog.Any("err", err))
+		return respondModerationError(e, "Raid Mode Failed", fmt.Sprintf("Failed to turn raid mode off: %s", err.Error()))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Raid Mode Off",
+				fmt.Sprintf("%d account(s) joined during raid mode, %d were kicked or timed out.", counts.Joins, counts.Actioned))).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func handleRaidHistory(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	events, err := b.Queries.GetRecentRaidEvents(e.Ctx, int64(*e.GuildID()))
+	if err != nil {
+		slog.Error("Failed to get raids", slog.Any("err", err))
+		return respondModerationError(e, "Error", "Failed to fetch raids")
+	}
+
+	if len(events) == 0 {
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.SuccessEmbed("No Raids", "No raids have been recorded.")).
+				SetEphemeral(true).
+				Build(),
+		)
+	}
+
+	var sb strings.Builder
+	for _, event := range events {
+		startedBy := "Detected"
+		if event.StartedBy.Valid {
+			startedBy = fmt.Sprintf("Started by <@%d>", event.StartedBy.Int64)
+		}
+
+		sb.WriteString(fmt.Sprintf("**#%d** <t:%d:f> | %s\n> %d join(s)", event.ID, event.StartedAt.Time.Unix(), startedBy, event.Joins))
+		switch event.Action {
+		case mgbot.RaidActionKick:
+			sb.WriteString(fmt.Sprintf(", %d kicked", event.Actioned))
+		case mgbot.RaidActionTimeout:
+			sb.WriteString(fmt.Sprintf(", %d timed out", event.Actioned))
+		}
+		if !event.EndedAt.Valid {
+			sb.WriteString(" | **ongoing**")
+		}
+		sb.WriteString("\n")
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle("Recent Raids").
+		SetDescription(sb.String()).
+		SetColor(utils.ColorInfo).
+		Build()
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed).
+			SetEphemeral(true).
+			Build(),
+	)
+}
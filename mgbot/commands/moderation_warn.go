@@ -0,0 +1,245 @@
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/json"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+var moderationWarnCommand = discord.ApplicationCommandOptionSubCommand{
+	Name:        "warn",
+	Description: "Warn a member, muting or banning them if they reach an escalation",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionUser{
+			Name:        "user",
+			Description: "The user to warn",
+			Required:    true,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "reason",
+			Description: "Reason for the warning",
+			Required:    true,
+			MaxLength:   json.Ptr(mgbot.ModlogReasonMaxLength),
+		},
+	},
+}
+
+var moderationWarningsCommand = discord.ApplicationCommandOptionSubCommand{
+	Name:        "warnings",
+	Description: "View a member's active warnings",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionUser{
+			Name:        "user",
+			Description: "The user to view warnings of",
+			Required:    true,
+		},
+	},
+}
+
+var moderationClearWarnCommand = discord.ApplicationCommandOptionSubCommand{
+	Name:        "clearwarn",
+	Description: "Clear one or all of a member's warnings",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionUser{
+			Name:        "user",
+			Description: "The user to clear warnings of",
+			Required:    true,
+		},
+		discord.ApplicationCommandOptionInt{
+			Name:        "case",
+			Description: "The case number of the warning to clear, all are cleared if not given",
+			Required:    false,
+			MinValue:    json.Ptr(1),
+		},
+	},
+}
+
+func handleWarn(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	targetUser := data.User("user")
+	reason := data.String("reason")
+
+	if targetUser.Bot {
+		return respondModerationError(e, "Warn Failed", "Bots can't be warned.")
+	}
+
+	result, err := b.WarnMember(e.Ctx, *e.GuildID(), targetUser.ID, e.User().ID, reason)
+	if err != nil {
+		slog.Error("Failed to warn member", slog.Any("err", err))
+		return respondModerationError(e, "Warn Failed", fmt.Sprintf("Failed to warn user: %s", err.Error()))
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle("User Warned").
+		SetDescription(fmt.Sprintf("<@%d> has been warned: %s", targetUser.ID, reason)).
+		SetColor(utils.ColorWarning).
+		SetFooterText(fmt.Sprintf("Case #%d | Active warnings: %d", result.CaseNumber, result.Warnings))
+
+	if escalation := result.Escalation; escalation != nil {
+		if result.EscalationErr != nil {
+			embed.AddField("Escalation Failed",
+				fmt.Sprintf("Couldn't %s them for reaching %d warnings: %s", escalation.Action, result.Warnings, result.EscalationErr.Error()),
+				false)
+		} else {
+			embed.AddField("Escalated",
+				fmt.Sprintf("%s for reaching %d warnings, until <t:%d:F>", escalationLabel(escalation.Action), result.Warnings, result.ExpiresAt.Unix()),
+				false)
+		}
+	}
+
+	// Warnings are public so the member sees them
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed.Build()).
+			SetAllowedMentions(&discord.AllowedMentions{}).
+			Build(),
+	)
+}
+
+func handleWarnings(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	targetUser := e.SlashCommandInteractionData().User("user")
+	guildID := int64(*e.GuildID())
+
+	warnings, err := b.Queries.GetActiveWarnings(e.Ctx, db.GetActiveWarningsParams{
+		UserID:  int64(targetUser.ID),
+		GuildID: guildID,
+	})
+	if err != nil {
+		slog.Error("Failed to get warnings", slog.Any("err", err))
+		return respondModerationError(e, "Error", "Failed to fetch warnings")
+	}
+
+	if len(warnings) == 0 {
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.SuccessEmbed("No Warnings", fmt.Sprintf("<@%d> has no active warnings.", targetUser.ID))).
+				SetEphemeral(true).
+				Build(),
+		)
+	}
+
+	// Only the latest warnings are listed, so count them all separately
+	count, err := b.Queries.CountActiveWarnings(e.Ctx, db.CountActiveWarningsParams{
+		UserID:  int64(targetUser.ID),
+		GuildID: guildID,
+	})
+	if err != nil {
+		slog.Error("Failed to count warnings", slog.Any("err", err))
+		count = int64(len(warnings))
+	}
+
+	var sb strings.Builder
+	for _, warning := range warnings {
+		reason := "No reason provided"
+		if warning.Reason.Valid {
+			reason = warning.Reason.String
+		}
+		sb.WriteString(fmt.Sprintf("**Case #%d** <t:%d:R> by <@%d>\n> %s\n",
+			warning.CaseNumber, warning.Time.Time.Unix(), warning.ModeratorID, utils.CutString(reason, 200)))
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle(fmt.Sprintf("Warnings for %s", targetUser.Username)).
+		SetDescription(sb.String()).
+		SetColor(utils.ColorWarning).
+		SetFooterText(fmt.Sprintf("%d active warning(s)", count))
+
+	escalations, err := b.Queries.GetWarnEscalations(e.Ctx, guildID)
+	if err != nil {
+		slog.Error("Failed to get warn escalations", slog.Any("err", err))
+	}
+	for _, escalation := range escalations {
+		if int64(escalation.WarnCount) > count {
+			embed.AddField("Next Escalation", formatWarnEscalation(escalation), false)
+			break
+		}
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed.Build()).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func handleClearWarn(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	targetUser := data.User("user")
+	guildID := int64(*e.GuildID())
+
+	var cleared int64
+	var err error
+	caseID, single := data.OptInt("case")
+	if single {
+		cleared, err = b.Queries.ClearWarning(e.Ctx, db.ClearWarningParams{
+			GuildID:    guildID,
+			UserID:     int64(targetUser.ID),
+			CaseNumber: int32(caseID),
+		})
+	} else {
+		cleared, err = b.Queries.ClearWarnings(e.Ctx, db.ClearWarningsParams{
+			UserID:  int64(targetUser.ID),
+			GuildID: guildID,
+		})
+	}
+	if err != nil {
+		slog.Error("Failed to clear warnings", slog.Any("err", err))
+		return respondModerationError(e, "Error", "Failed to clear warnings")
+	}
+
+	if cleared == 0 {
+		if single {
+			return respondModerationError(e, "Not Found", fmt.Sprintf("Case #%d isn't an active warning of <@%d>.", caseID, targetUser.ID))
+		}
+		return respondModerationError(e, "Not Found", fmt.Sprintf("<@%d> has no active warnings.", targetUser.ID))
+	}
+
+	description := fmt.Sprintf("Cleared %d warning(s) of <@%d>.", cleared, targetUser.ID)
+	summary := fmt.Sprintf("Cleared %d warning(s)", cleared)
+	if single {
+		description = fmt.Sprintf("Cleared warning case #%d of <@%d>.", caseID, targetUser.ID)
+		summary = fmt.Sprintf("Cleared warning case #%d", caseID)
+	}
+
+	_, err = b.LogModAction(e.Ctx, db.CreateModlogParams{
+		UserID:      int64(targetUser.ID),
+		ModeratorID: int64(e.User().ID),
+		GuildID:     guildID,
+		LogType:     "clearwarn",
+		Reason:      pgtype.Text{String: summary, Valid: true},
+		ExpiresAt:   pgtype.Timestamp{Valid: false},
+		Active:      pgtype.Bool{Bool: true, Valid: true},
+	})
+	if err != nil {
+		slog.Error("Failed to create modlog entry", slog.Any("err", err))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Warnings Cleared", description)).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func escalationLabel(action string) string {
+	if action == mgbot.EscalationTempBan {
+		return "Banned"
+	}
+	return "Muted"
+}
+
+func formatWarnEscalation(escalation db.WarnEscalation) string {
+	return fmt.Sprintf("%d warnings: %s for %s", escalation.WarnCount, escalation.Action,
+		mgbot.FormatDuration(time.Duration(escalation.DurationSeconds)*time.Second))
+}
@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+var xpSpamReasonLabels = map[string]string{
+	mgbot.XpSpamTooShort:  "Too short",
+	mgbot.XpSpamDuplicate: "Repeated message",
+	mgbot.XpSpamEmojiOnly: "Emoji only",
+}
+
+var moderationXpFlagsCommand = discord.ApplicationCommandOptionSubCommand{
+	Name:        "xp-flags",
+	Description: "Review members whose messages were denied XP as spam",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionUser{
+			Name:        "user",
+			Description: "The member to view flagged messages of",
+			Required:    false,
+		},
+	},
+}
+
+var moderationXpFlagsClearCommand = discord.ApplicationCommandOptionSubCommand{
+	Name:        "xp-flags-clear",
+	Description: "Clear a member's XP spam flags once they have been reviewed",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionUser{
+			Name:        "user",
+			Description: "The member to clear flags of",
+			Required:    true,
+		},
+	},
+}
+
+func handleXpFlags(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	guildID := int64(*e.GuildID())
+
+	if target, ok := e.SlashCommandInteractionData().OptUser("user"); ok {
+		return handleXpFlagsForUser(b, e, target)
+	}
+
+	flagged, err := b.Queries.GetXpSpamFlaggedUsers(e.Ctx, guildID)
+	if err != nil {
+		slog.Error("Failed to get XP spam flags", slog.Any("err", err))
+		return respondModerationError(e, "Error", "Failed to fetch XP spam flags")
+	}
+
+	if len(flagged) == 0 {
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.SuccessEmbed("No Flags", "No one has been flagged for XP spam.")).
+				SetEphemeral(true).
+				Build(),
+		)
+	}
+
+	var sb strings.Builder
+	for i, user := range flagged {
+		sb.WriteString(fmt.Sprintf("**%d.** <@%d> - %d flag(s), last <t:%d:R>\n",
+			i+1, user.UserID, user.Flags, user.LastFlaggedAt.Time.Unix()))
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle("XP Spam Flags").
+		SetDescription(sb.String()).
+		SetColor(utils.ColorWarning).
+		SetFooterText("Use /moderation xp-flags user to see a member's flagged messages").
+		Build()
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func handleXpFlagsForUser(b *mgbot.MartinGarrixBot, e *handler.CommandEvent, target discord.User) error {
+	flags, err := b.Queries.GetXpSpamFlags(e.Ctx, db.GetXpSpamFlagsParams{
+		GuildID: int64(*e.GuildID()),
+		UserID:  int64(target.ID),
+	})
+	if err != nil {
+		slog.Error("Failed to get XP spam flags", slog.Any("err", err))
+		return respondModerationError(e, "Error", "Failed to fetch XP spam flags")
+	}
+
+	if len(flags) == 0 {
+		return e.Respond(discord.InteractionResponseTypeCreateMessage,
+			discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.SuccessEmbed("No Flags", fmt.Sprintf("<@%d> hasn't been flagged for XP spam.", target.ID))).
+				SetEphemeral(true).
+				Build(),
+		)
+	}
+
+	var sb strings.Builder
+	for _, flag := range flags {
+		label, ok := xpSpamReasonLabels[flag.Reason]
+		if !ok {
+			label = flag.Reason
+		}
+		sb.WriteString(fmt.Sprintf("<t:%d:R> **%s** in <#%d>\n> %s\n",
+			flag.FlaggedAt.Time.Unix(), label, flag.ChannelID, utils.CutString(strings.ReplaceAll(flag.Content, "\n", " "), 100)))
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle(fmt.Sprintf("XP Spam Flags for %s", target.Username)).
+		SetDescription(sb.String()).
+		SetColor(utils.ColorWarning).
+		SetFooterText("Showing the 10 most recent flags").
+		Build()
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func handleXpFlagsClear(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	target := e.SlashCommandInteractionData().User("user")
+
+	cleared, err := b.Queries.DeleteXpSpamFlags(e.Ctx, db.DeleteXpSpamFlagsParams{
+		GuildID: int64(*e.GuildID()),
+		UserID:  int64(target.ID),
+	})
+	if err != nil {
+		slog.Error("Failed to clear XP spam flags", slog.Any("err", err))
+		return respondModerationError(e, "Error", "Failed to clear XP spam flags")
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Flags Cleared", fmt.Sprintf("Cleared %d XP spam flag(s) of <@%d>.", cleared, target.ID))).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func respondModerationError(e *handler.CommandEvent, title, description string) error {
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.FailureEmbed(title, description)).
+			SetEphemeral(true).
+			Build(),
+	)
+}
@@ -114,10 +114,18 @@ func QuizHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
 					}
 					earnings := earningsForDifficulty[difficulty]
 
-					err := b.Queries.AddCoins(e.Ctx, db.AddCoinsParams{
-						ID:      int64(e.Member().User.ID),
-						GuildID: int64(*e.GuildID()),
-						InHand:  pgtype.Int8{Int64: int64(earnings), Valid: true},
+					err := b.UpdateCoins(e.Ctx, func(q *db.Queries) error {
+						return q.AddCoins(e.Ctx, db.AddCoinsParams{
+							ID:      int64(e.Member().User.ID),
+							GuildID: int64(*e.GuildID()),
+							InHand:  pgtype.Int8{Int64: int64(earnings), Valid: true},
+						})
+					}, db.CreateCoinTransactionParams{
+						GuildID:      int64(*e.GuildID()),
+						UserID:       int64(e.Member().User.ID),
+						Kind:         mgbot.CoinTxQuizReward,
+						InHandChange: int64(earnings),
+						Note:         pgtype.Text{String: difficulty, Valid: true},
 					})
 
 					if err != nil {
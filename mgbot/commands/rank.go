@@ -1,23 +1,134 @@
 package commands
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/json"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/jackc/pgx/v5/pgtype"
 	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
 	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
 	"github.com/milindmadhukar/MartinGarrixBot/utils"
 )
 
+const (
+	rankThemeNameMaxLength = 50
+	// rankBackgroundRandom picks a random built-in template for every card.
+	rankBackgroundRandom = "random"
+	rankAccentNone       = "none"
+)
+
+var errRankThemeLocked = errors.New("rank theme locked")
+
+var rankThemeNameOption = discord.ApplicationCommandOptionString{
+	Name:         "name",
+	Description:  "The name of the theme",
+	Required:     true,
+	Autocomplete: true,
+	MaxLength:    json.Ptr(rankThemeNameMaxLength),
+}
+
+var rankAccentOption = discord.ApplicationCommandOptionString{
+	Name:        "accent",
+	Description: "The progress bar colour as a hex code, e.g. #1ABC9C",
+	Required:    false,
+	MinLength:   json.Ptr(6),
+	MaxLength:   json.Ptr(7),
+}
+
 var rank = discord.SlashCommandCreate{
 	Name:        "rank",
 	Description: "Get the rank of a member.",
 	Options: []discord.ApplicationCommandOption{
-		discord.ApplicationCommandOptionUser{
-			Name:        "user",
-			Description: "The user to get the rank of.",
-			Required:    false,
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "show",
+			Description: "Get the rank card of a member.",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionUser{
+					Name:        "user",
+					Description: "The user to get the rank of.",
+					Required:    false,
+				},
+			},
+		},
+		discord.ApplicationCommandOptionSubCommandGroup{
+			Name:        "theme",
+			Description: "Customise your rank card",
+			Options: []discord.ApplicationCommandOptionSubCommand{
+				{
+					Name:        "preview",
+					Description: "Preview a rank card theme and save it if you like it",
+					Options: []discord.ApplicationCommandOption{
+						discord.ApplicationCommandOptionString{
+							Name:         "background",
+							Description:  "A built-in template or one of the server's themes",
+							Required:     true,
+							Autocomplete: true,
+							MaxLength:    json.Ptr(rankThemeNameMaxLength),
+						},
+						rankAccentOption,
+						discord.ApplicationCommandOptionString{
+							Name:        "bar",
+							Description: "The style of the progress bar",
+							Required:    false,
+							Choices: []discord.ApplicationCommandOptionChoiceString{
+								{Name: "Solid", Value: utils.RankBarSolid},
+								{Name: "Gradient", Value: utils.RankBarGradient},
+								{Name: "Striped", Value: utils.RankBarStriped},
+							},
+						},
+					},
+				},
+				{
+					Name:        "reset",
+					Description: "Go back to the default rank card",
+				},
+				{
+					Name:        "list",
+					Description: "List the themes you can use and the levels they unlock at",
+				},
+				{
+					Name:        "create",
+					Description: "Add a theme members can unlock",
+					Options: []discord.ApplicationCommandOption{
+						discord.ApplicationCommandOptionString{
+							Name:        "name",
+							Description: "The name of the theme",
+							Required:    true,
+							MaxLength:   json.Ptr(rankThemeNameMaxLength),
+						},
+						discord.ApplicationCommandOptionString{
+							Name:        "background_url",
+							Description: "An https link to a PNG or JPEG background, ideally 1000x300",
+							Required:    true,
+							MaxLength:   json.Ptr(500),
+						},
+						discord.ApplicationCommandOptionInt{
+							Name:        "required_level",
+							Description: "The level members need to use the theme",
+							Required:    true,
+							MinValue:    json.Ptr(0),
+						},
+						rankAccentOption,
+					},
+				},
+				{
+					Name:        "delete",
+					Description: "Remove a theme, members using it go back to the default",
+					Options:     []discord.ApplicationCommandOption{rankThemeNameOption},
+				},
+			},
 		},
 	},
 }
@@ -26,57 +137,502 @@ var rank = discord.SlashCommandCreate{
 
 func RankHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
 	return func(e *handler.CommandEvent) error {
-		member := e.SlashCommandInteractionData().Member("user")
-		// TODO: Check if it can't resolve a member
-		if member.Member.User.ID == 0 {
-			member = *e.Member()
+		data := e.SlashCommandInteractionData()
+
+		if data.SubCommandGroupName != nil && *data.SubCommandGroupName == "theme" {
+			switch *data.SubCommandName {
+			case "preview":
+				return handleRankThemePreview(b, e)
+			case "reset":
+				return handleRankThemeReset(b, e)
+			case "list":
+				return handleRankThemeList(b, e)
+			case "create", "delete":
+				if !b.IsOwner(e.User().ID) && !e.Member().Permissions.Has(discord.PermissionAdministrator) {
+					return respondRankError(e, "Permission Denied", "Only administrators can manage rank card themes.")
+				}
+				if *data.SubCommandName == "create" {
+					return handleRankThemeCreate(b, e)
+				}
+				return handleRankThemeDelete(b, e)
+			}
 		}
 
-		if member.User.Bot {
-			embed := utils.FailureEmbed("You cannot check the rank of a bot", "")
-			return e.Respond(
-				discord.InteractionResponseTypeCreateMessage, discord.NewMessageCreateBuilder().
-					SetEmbeds(embed).
-					SetEphemeral(true).
-					Build(),
-			)
+		if *data.SubCommandName == "show" {
+			return handleRankShow(b, e)
 		}
 
-		e.DeferCreateMessage(false)
+		return respondRankError(e, "Invalid Command", "Unknown subcommand")
+	}
+}
 
-		avatarURL := member.User.AvatarURL(discord.WithFormat(discord.FileFormatPNG), discord.WithSize(256))
+func RankAutocompleteHandler(b *mgbot.MartinGarrixBot) handler.AutocompleteHandler {
+	return func(e *handler.AutocompleteEvent) error {
+		focused := e.Data.Focused()
+		input := strings.TrimSpace(e.Data.String(focused.Name))
 
-		if avatarURL == nil {
-			return errors.New("Failed to get avatar url")
+		var choices []discord.AutocompleteChoice
+		if focused.Name == "background" {
+			for _, name := range append([]string{rankBackgroundRandom}, utils.RankTemplates...) {
+				if strings.Contains(name, strings.ToLower(input)) {
+					choices = append(choices, discord.AutocompleteChoiceString{Name: name, Value: name})
+				}
+			}
 		}
 
-		user, err := b.Queries.GetUserLevelData(e.Ctx, db.GetUserLevelDataParams{
-			ID:      int64(member.User.ID),
+		names, err := b.Queries.GetRankThemeNamesLike(e.Ctx, db.GetRankThemeNamesLikeParams{
 			GuildID: int64(*e.GuildID()),
+			Name:    likePattern(input),
 		})
 		if err != nil {
+			slog.Error("Failed to get rank theme names for autocomplete", slog.Any("err", err))
 			return err
 		}
 
-		picture, err := utils.RankPicture(user, member.User.Username, *avatarURL)
+		for _, name := range names {
+			if len(choices) == 25 {
+				break
+			}
+			choices = append(choices, discord.AutocompleteChoiceString{Name: name, Value: name})
+		}
+		return e.AutocompleteResult(choices)
+	}
+}
+
+// RankThemeSaveHandler saves the theme of a preview. The background is a
+// built-in template or t<ID> of a server theme, and the accent a hex code.
+func RankThemeSaveHandler(b *mgbot.MartinGarrixBot) handler.ComponentHandler {
+	return func(e *handler.ComponentEvent) error {
+		guildID := *e.GuildID()
+
+		saved := db.UserRankTheme{
+			UserID:   int64(e.User().ID),
+			GuildID:  int64(guildID),
+			BarStyle: e.Vars["bar"],
+		}
+
+		background := e.Vars["background"]
+		if id, ok := strings.CutPrefix(background, "t"); ok {
+			themeID, err := strconv.ParseInt(id, 10, 64)
+			if err != nil {
+				return err
+			}
+			saved.ThemeID = pgtype.Int8{Int64: themeID, Valid: true}
+		} else if background != rankBackgroundRandom {
+			saved.Template = background
+		}
+
+		if e.Vars["accent"] != rankAccentNone {
+			accent, err := strconv.ParseUint(e.Vars["accent"], 16, 32)
+			if err != nil {
+				return err
+			}
+			saved.AccentColor = pgtype.Int4{Int32: int32(accent), Valid: true}
+		}
+
+		level, err := memberLevel(e.Ctx, b, guildID, e.User().ID)
 		if err != nil {
 			return err
 		}
 
-		pictureReader, err := utils.ImageToReader(picture)
-		if err != nil {
+		if saved.ThemeID.Valid {
+			theme, err := b.Queries.GetRankTheme(e.Ctx, db.GetRankThemeParams{
+				GuildID: int64(guildID),
+				ID:      saved.ThemeID.Int64,
+			})
+			if errors.Is(err, db.ErrRecordNotFound) {
+				return e.CreateMessage(discord.NewMessageCreateBuilder().
+					SetEmbeds(utils.FailureEmbed("Theme Not Found", "This theme has been deleted.")).
+					SetEphemeral(true).
+					Build(),
+				)
+			} else if err != nil {
+				return err
+			}
+			if int(theme.RequiredLevel) > level {
+				return e.CreateMessage(discord.NewMessageCreateBuilder().
+					SetEmbeds(utils.FailureEmbed("Theme Locked", fmt.Sprintf("You need to reach level %d to use this theme.", theme.RequiredLevel))).
+					SetEphemeral(true).
+					Build(),
+				)
+			}
+		}
+
+		if err := b.Queries.SetUserRankTheme(e.Ctx, db.SetUserRankThemeParams{
+			UserID:      saved.UserID,
+			GuildID:     saved.GuildID,
+			Template:    saved.Template,
+			ThemeID:     saved.ThemeID,
+			AccentColor: saved.AccentColor,
+			BarStyle:    saved.BarStyle,
+		}); err != nil {
+			slog.Error("Failed to save rank theme", slog.Any("err", err))
 			return err
 		}
 
-		_, err = e.UpdateInteractionResponse(
-			discord.NewMessageUpdateBuilder().
-				SetFiles(discord.NewFile("rank.png", "Rank", pictureReader)).
-				Build(),
+		return e.UpdateMessage(discord.NewMessageUpdateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Theme Saved", "Your rank card will use this theme from now on.")).
+			ClearContainerComponents().
+			Build(),
 		)
-		if err != nil {
+	}
+}
+
+func handleRankShow(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	member := e.SlashCommandInteractionData().Member("user")
+	// TODO: Check if it can't resolve a member
+	if member.Member.User.ID == 0 {
+		member = *e.Member()
+	}
+
+	if member.User.Bot {
+		return respondRankError(e, "You cannot check the rank of a bot", "")
+	}
+
+	e.DeferCreateMessage(false)
+
+	saved, err := b.Queries.GetUserRankTheme(e.Ctx, db.GetUserRankThemeParams{
+		UserID:  int64(member.User.ID),
+		GuildID: int64(*e.GuildID()),
+	})
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		return err
+	}
+
+	picture, err := rankCard(e.Ctx, b, member, saved, false)
+	if err != nil {
+		return err
+	}
+
+	pictureReader, err := utils.ImageToReader(picture)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.UpdateInteractionResponse(
+		discord.NewMessageUpdateBuilder().
+			SetFiles(discord.NewFile("rank.png", "Rank", pictureReader)).
+			Build(),
+	)
+	return err
+}
+
+func handleRankThemePreview(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	guildID := *e.GuildID()
+	member := *e.Member()
+
+	preview := db.UserRankTheme{
+		UserID:   int64(member.User.ID),
+		GuildID:  int64(guildID),
+		BarStyle: data.String("bar"),
+	}
+	if preview.BarStyle == "" {
+		preview.BarStyle = utils.RankBarSolid
+	}
+
+	// The custom ID of the save button has to carry the whole theme
+	background := strings.ToLower(strings.TrimSpace(data.String("background")))
+	if background != rankBackgroundRandom && !slices.Contains(utils.RankTemplates, background) {
+		theme, err := b.Queries.GetRankThemeByName(e.Ctx, db.GetRankThemeByNameParams{
+			GuildID: int64(guildID),
+			Name:    strings.TrimSpace(data.String("background")),
+		})
+		if errors.Is(err, db.ErrRecordNotFound) {
+			return respondRankError(e, "Theme Not Found", fmt.Sprintf("There is no theme called `%s`. See `/rank theme list`.", data.String("background")))
+		} else if err != nil {
 			return err
 		}
+		preview.ThemeID = pgtype.Int8{Int64: theme.ID, Valid: true}
+		background = "t" + strconv.FormatInt(theme.ID, 10)
+	} else if background != rankBackgroundRandom {
+		preview.Template = background
+	}
 
-		return nil
+	accent := rankAccentNone
+	if hex, ok := data.OptString("accent"); ok {
+		color, err := parseHexColor(hex)
+		if err != nil {
+			return respondRankError(e, "Invalid Colour", "Give the colour as a hex code like `#1ABC9C`.")
+		}
+		preview.AccentColor = pgtype.Int4{Int32: color, Valid: true}
+		accent = fmt.Sprintf("%06X", color)
 	}
-}
\ No newline at end of file
+
+	e.DeferCreateMessage(true)
+
+	picture, err := rankCard(e.Ctx, b, member, preview, true)
+	if errors.Is(err, errRankThemeLocked) {
+		_, err = e.UpdateInteractionResponse(discord.NewMessageUpdateBuilder().
+			SetEmbeds(utils.FailureEmbed("Theme Locked", "You haven't reached the level this theme unlocks at yet. See `/rank theme list`.")).
+			Build(),
+		)
+		return err
+	} else if err != nil {
+		slog.Error("Failed to render rank theme preview", slog.Any("err", err))
+		_, err = e.UpdateInteractionResponse(discord.NewMessageUpdateBuilder().
+			SetEmbeds(utils.FailureEmbed("Error", "Failed to render the preview.")).
+			Build(),
+		)
+		return err
+	}
+
+	pictureReader, err := utils.ImageToReader(picture)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.UpdateInteractionResponse(
+		discord.NewMessageUpdateBuilder().
+			SetFiles(discord.NewFile("rank.png", "Rank theme preview", pictureReader)).
+			AddActionRow(discord.NewSuccessButton("Save", fmt.Sprintf("/rank-theme/save/%s/%s/%s", background, accent, preview.BarStyle))).
+			Build(),
+	)
+	return err
+}
+
+func handleRankThemeReset(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	if err := b.Queries.DeleteUserRankTheme(e.Ctx, db.DeleteUserRankThemeParams{
+		UserID:  int64(e.User().ID),
+		GuildID: int64(*e.GuildID()),
+	}); err != nil {
+		slog.Error("Failed to reset rank theme", slog.Any("err", err))
+		return respondRankError(e, "Error", "Failed to reset your rank card.")
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Theme Reset", "Your rank card is back to the default.")).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func handleRankThemeList(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	guildID := *e.GuildID()
+
+	themes, err := b.Queries.GetRankThemes(e.Ctx, int64(guildID))
+	if err != nil {
+		slog.Error("Failed to get rank themes", slog.Any("err", err))
+		return respondRankError(e, "Error", "Failed to load the rank card themes.")
+	}
+
+	level, err := memberLevel(e.Ctx, b, guildID, e.User().ID)
+	if err != nil {
+		return err
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle("Rank Card Themes").
+		SetColor(utils.ColorInfo).
+		SetFooterText("Try one with /rank theme preview").
+		AddField("Built-in", strings.Join(append([]string{rankBackgroundRandom}, utils.RankTemplates...), ", "), false)
+
+	if len(themes) == 0 {
+		embed.AddField("Server Themes", "This server has no themes yet.", false)
+	}
+
+	for i, theme := range themes {
+		// Embeds are limited to 25 fields
+		if i == 24 {
+			break
+		}
+		value := "Unlocked"
+		if int(theme.RequiredLevel) > level {
+			value = fmt.Sprintf("🔒 Unlocks at level %d", theme.RequiredLevel)
+		}
+		embed.AddField(theme.Name, value, true)
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed.Build()).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func handleRankThemeCreate(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+
+	params := db.CreateRankThemeParams{
+		GuildID:       int64(*e.GuildID()),
+		Name:          strings.TrimSpace(data.String("name")),
+		BackgroundUrl: strings.TrimSpace(data.String("background_url")),
+		RequiredLevel: int32(data.Int("required_level")),
+		CreatedAt:     pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	}
+
+	if slices.Contains(utils.RankTemplates, strings.ToLower(params.Name)) || strings.EqualFold(params.Name, rankBackgroundRandom) {
+		return respondRankError(e, "Invalid Name", fmt.Sprintf("`%s` is the name of a built-in template.", params.Name))
+	}
+
+	if !strings.HasPrefix(params.BackgroundUrl, "https://") {
+		return respondRankError(e, "Invalid Background", "The background has to be an https link.")
+	}
+
+	if hex, ok := data.OptString("accent"); ok {
+		color, err := parseHexColor(hex)
+		if err != nil {
+			return respondRankError(e, "Invalid Colour", "Give the colour as a hex code like `#1ABC9C`.")
+		}
+		params.AccentColor = pgtype.Int4{Int32: color, Valid: true}
+	}
+
+	e.DeferCreateMessage(true)
+
+	if _, err := utils.FetchImage(params.BackgroundUrl); err != nil {
+		message := "The link has to be a PNG or JPEG image."
+		if errors.Is(err, utils.ErrImageTooLarge) {
+			message = fmt.Sprintf("The image can be at most %d MB and %d×%d pixels.",
+				utils.ImageMaxBytes>>20, utils.ImageMaxSide, utils.ImageMaxSide)
+		}
+		_, err = e.UpdateInteractionResponse(discord.NewMessageUpdateBuilder().
+			SetEmbeds(utils.FailureEmbed("Invalid Background", message)).
+			Build(),
+		)
+		return err
+	}
+
+	theme, err := b.Queries.CreateRankTheme(e.Ctx, params)
+	if db.ErrorCode(err) == db.UniqueViolation {
+		_, err = e.UpdateInteractionResponse(discord.NewMessageUpdateBuilder().
+			SetEmbeds(utils.FailureEmbed("Theme Already Exists", fmt.Sprintf("There is already a theme called `%s`.", params.Name))).
+			Build(),
+		)
+		return err
+	} else if err != nil {
+		slog.Error("Failed to create rank theme", slog.Any("err", err))
+		_, err = e.UpdateInteractionResponse(discord.NewMessageUpdateBuilder().
+			SetEmbeds(utils.FailureEmbed("Error", "Failed to create the theme.")).
+			Build(),
+		)
+		return err
+	}
+
+	_, err = e.UpdateInteractionResponse(discord.NewMessageUpdateBuilder().
+		SetEmbeds(utils.SuccessEmbed("Theme Created", fmt.Sprintf("Members can use `%s` from level %d.", theme.Name, theme.RequiredLevel))).
+		Build(),
+	)
+	return err
+}
+
+func handleRankThemeDelete(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	name := strings.TrimSpace(e.SlashCommandInteractionData().String("name"))
+
+	deleted, err := b.Queries.DeleteRankTheme(e.Ctx, db.DeleteRankThemeParams{
+		GuildID: int64(*e.GuildID()),
+		Name:    name,
+	})
+	if err != nil {
+		slog.Error("Failed to delete rank theme", slog.Any("err", err))
+		return respondRankError(e, "Error", "Failed to delete the theme.")
+	}
+	if deleted == 0 {
+		return respondRankError(e, "Theme Not Found", fmt.Sprintf("There is no theme called `%s`.", name))
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Theme Deleted", fmt.Sprintf("Deleted the theme `%s`.", name))).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+// rankCard draws the rank card of member in a saved theme. A server theme the
+// member hasn't unlocked falls back to the default card, or returns
+// errRankThemeLocked when strict. Without an accent of their own or from the
+// theme, the card uses the rank colour the member bought in the shop.
+func rankCard(ctx context.Context, b *mgbot.MartinGarrixBot, member discord.ResolvedMember, saved db.UserRankTheme, strict bool) (image.Image, error) {
+	guildID := member.GuildID
+
+	avatarURL := member.User.AvatarURL(discord.WithFormat(discord.FileFormatPNG), discord.WithSize(256))
+	if avatarURL == nil {
+		return nil, errors.New("Failed to get avatar url")
+	}
+
+	user, err := b.Queries.GetUserLevelData(ctx, db.GetUserLevelDataParams{
+		ID:      int64(member.User.ID),
+		GuildID: int64(guildID),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	theme := utils.RankTheme{
+		Template: saved.Template,
+		BarStyle: saved.BarStyle,
+	}
+	accent := saved.AccentColor
+
+	if saved.ThemeID.Valid {
+		serverTheme, err := b.Queries.GetRankTheme(ctx, db.GetRankThemeParams{
+			GuildID: int64(guildID),
+			ID:      saved.ThemeID.Int64,
+		})
+		if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+			return nil, err
+		}
+
+		if err == nil && int(serverTheme.RequiredLevel) <= utils.GetUserLevel(user.TotalXp.Int32) {
+			theme.BackgroundURL = serverTheme.BackgroundUrl
+			if !accent.Valid {
+				accent = serverTheme.AccentColor
+			}
+		} else if strict {
+			return nil, errRankThemeLocked
+		} else {
+			theme = utils.RankTheme{}
+			accent = pgtype.Int4{}
+		}
+	}
+
+	if !accent.Valid {
+		accent, err = b.Queries.GetRankColor(ctx, db.GetRankColorParams{
+			GuildID: int64(guildID),
+			UserID:  int64(member.User.ID),
+		})
+		if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+			return nil, err
+		}
+	}
+	if accent.Valid {
+		theme.Accent = &color.RGBA{R: uint8(accent.Int32 >> 16), G: uint8(accent.Int32 >> 8), B: uint8(accent.Int32), A: 255}
+	}
+
+	return utils.RankPicture(user, member.User.Username, *avatarURL, theme)
+}
+
+// memberLevel returns the level of a member, 0 if they have no XP yet.
+func memberLevel(ctx context.Context, b *mgbot.MartinGarrixBot, guildID, userID snowflake.ID) (int, error) {
+	user, err := b.Queries.GetUserLevelData(ctx, db.GetUserLevelDataParams{
+		ID:      int64(userID),
+		GuildID: int64(guildID),
+	})
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return utils.GetUserLevel(user.TotalXp.Int32), nil
+}
+
+// parseHexColor parses a colour like #1ABC9C.
+func parseHexColor(s string) (int32, error) {
+	hex := strings.TrimPrefix(strings.TrimSpace(s), "#")
+	color, err := strconv.ParseUint(hex, 16, 32)
+	if err == nil && len(hex) != 6 {
+		err = fmt.Errorf("invalid hex colour %q", s)
+	}
+	return int32(color), err
+}
+
+func respondRankError(e *handler.CommandEvent, title, description string) error {
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.FailureEmbed(title, description)).
+			SetEphemeral(true).
+			Build(),
+	)
+}
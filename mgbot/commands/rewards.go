@@ -0,0 +1,206 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+// rewardPeriod describes a reward that can be claimed once per period, with a
+// bonus for every consecutive period it is claimed in.
+type rewardPeriod struct {
+	kind string
+	name string
+	unit string
+
+	base        int64
+	streakBonus int64
+	maxBonus    int64
+
+	// start returns the start of the period containing t, in t's location.
+	start func(t time.Time) time.Time
+	// next returns the start of the period after the one starting at start.
+	next func(start time.Time) time.Time
+}
+
+var dailyReward = rewardPeriod{
+	kind:        mgbot.CoinTxDaily,
+	name:        "daily",
+	unit:        "day",
+	base:        100,
+	streakBonus: 10,
+	maxBonus:    200,
+	start:       mgbot.StartOfDay,
+	next: func(start time.Time) time.Time {
+		return start.AddDate(0, 0, 1)
+	},
+}
+
+var weeklyReward = rewardPeriod{
+	kind:        mgbot.CoinTxWeekly,
+	name:        "weekly",
+	unit:        "week",
+	base:        1000,
+	streakBonus: 100,
+	maxBonus:    1000,
+	start:       mgbot.StartOfWeek,
+	next: func(start time.Time) time.Time {
+		return start.AddDate(0, 0, 7)
+	},
+}
+
+var daily = discord.SlashCommandCreate{
+	Name:        "daily",
+	Description: "Claim your daily Garrix coins.",
+}
+
+var weekly = discord.SlashCommandCreate{
+	Name:        "weekly",
+	Description: "Claim your weekly Garrix coins.",
+}
+
+type rewardResult struct {
+	claimed bool
+	amount  int64
+	streak  int32
+	// lostStreak is the streak that was reset because a period was missed.
+	lostStreak int32
+	next       time.Time
+}
+
+func DailyHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
+	return rewardHandler(b, dailyReward)
+}
+
+func WeeklyHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
+	return rewardHandler(b, weeklyReward)
+}
+
+func rewardHandler(b *mgbot.MartinGarrixBot, period rewardPeriod) handler.CommandHandler {
+	return func(e *handler.CommandEvent) error {
+		result, err := claimReward(e.Ctx, b, int64(*e.GuildID()), int64(e.User().ID), period)
+		if err != nil {
+			slog.Error("Failed to claim reward", slog.String("reward", period.name), slog.Any("err", err))
+			return err
+		}
+
+		if !result.claimed {
+			return e.Respond(
+				discord.InteractionResponseTypeCreateMessage, discord.NewMessageCreateBuilder().
+					SetEmbeds(utils.FailureEmbed(
+						fmt.Sprintf("You've already claimed your %s reward.", period.name),
+						fmt.Sprintf("You can claim it again <t:%d:R>.", result.next.Unix()))).
+					SetEphemeral(true).
+					Build(),
+			)
+		}
+
+		description := fmt.Sprintf("You received **%d** coins.\nStreak: **%d %s(s)**", result.amount, result.streak, period.unit)
+		if result.lostStreak > 1 {
+			description += fmt.Sprintf("\nYou missed a %s and lost your %d %s streak.", period.unit, result.lostStreak, period.unit)
+		}
+		description += fmt.Sprintf("\n\nYour next %s reward is available <t:%d:R>.", period.name, result.next.Unix())
+
+		return e.Respond(
+			discord.InteractionResponseTypeCreateMessage, discord.NewMessageCreateBuilder().
+				SetEmbeds(utils.SuccessEmbed(fmt.Sprintf("%s reward claimed!", capitalize(period.name)), description)).
+				Build(),
+		)
+	}
+}
+
+// claimReward credits the reward for the current period if it hasn't been
+// claimed yet. Periods are measured in the guild's configured timezone.
+func claimReward(ctx context.Context, b *mgbot.MartinGarrixBot, guildID, userID int64, period rewardPeriod) (rewardResult, error) {
+	loc := b.GuildLocation(ctx, guildID)
+	now := time.Now().In(loc)
+	current := period.start(now)
+	previous := period.start(current.Add(-time.Second))
+
+	tx, err := b.DB.Begin(ctx)
+	if err != nil {
+		return rewardResult{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	q := b.Queries.WithTx(tx)
+
+	if err := q.EnsureUser(ctx, db.EnsureUserParams{ID: userID, GuildID: guildID}); err != nil {
+		return rewardResult{}, err
+	}
+
+	// Create the claim row first so there is always a row to lock, otherwise
+	// two first-time claims could both go through.
+	key := db.EnsureRewardClaimParams{UserID: userID, GuildID: guildID, Kind: period.kind}
+	if err := q.EnsureRewardClaim(ctx, key); err != nil {
+		return rewardResult{}, err
+	}
+
+	claim, err := q.GetRewardClaimForUpdate(ctx, db.GetRewardClaimForUpdateParams(key))
+	if err != nil {
+		return rewardResult{}, err
+	}
+
+	result := rewardResult{streak: 1, next: period.next(current)}
+	if claim.LastClaimedAt.Valid {
+		last := period.start(claim.LastClaimedAt.Time.In(loc))
+		switch {
+		case !last.Before(current):
+			return rewardResult{next: result.next}, nil
+		case last.Equal(previous):
+			result.streak = claim.Streak + 1
+		default:
+			result.lostStreak = claim.Streak
+		}
+	}
+
+	result.claimed = true
+	result.amount = period.base + min(int64(result.streak-1)*period.streakBonus, period.maxBonus)
+
+	err = q.UpdateRewardClaim(ctx, db.UpdateRewardClaimParams{
+		UserID:        userID,
+		GuildID:       guildID,
+		Kind:          period.kind,
+		Streak:        result.streak,
+		LastClaimedAt: pgtype.Timestamp{Time: now.UTC(), Valid: true},
+	})
+	if err != nil {
+		return rewardResult{}, err
+	}
+
+	if err := q.AddCoins(ctx, db.AddCoinsParams{
+		ID:      userID,
+		GuildID: guildID,
+		InHand:  pgtype.Int8{Int64: result.amount, Valid: true},
+	}); err != nil {
+		return rewardResult{}, err
+	}
+
+	err = mgbot.RecordCoinTransactions(ctx, q, db.CreateCoinTransactionParams{
+		GuildID:      guildID,
+		UserID:       userID,
+		Kind:         period.kind,
+		InHandChange: result.amount,
+		Note:         pgtype.Text{String: fmt.Sprintf("%d %s streak", result.streak, period.unit), Valid: true},
+	})
+	if err != nil {
+		return rewardResult{}, err
+	}
+
+	return result, tx.Commit(ctx)
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return string(s[0]-'a'+'A') + s[1:]
+}
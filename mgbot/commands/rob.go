@@ -1,3 +1,225 @@
 package commands
 
-// TODO: Implement the rob command.
\ No newline at end of file
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+const (
+	robCooldown = time.Hour
+	// robMinBalance is how much a robber needs in hand to attempt a robbery,
+	// so there is always something to pay the victim if they get caught.
+	robMinBalance = 100
+
+	robMinChance = 0.15
+	robMaxChance = 0.65
+
+	robOutcomeSuccess   = "success"
+	robOutcomeFailed    = "failed"
+	robOutcomeProtected = "protected"
+)
+
+var errRobberTooPoor = errors.New("robber does not have enough coins in hand")
+
+// robCooldownError is returned when the robber attempted a robbery too
+// recently.
+type robCooldownError struct {
+	nextAttempt time.Time
+}
+
+func (e robCooldownError) Error() string {
+	return fmt.Sprintf("robber can rob again at %s", e.nextAttempt)
+}
+
+var rob = discord.SlashCommandCreate{
+	Name:        "rob",
+	Description: "Try to steal Garrix coins from a member's hand.",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionUser{
+			Name:        "user",
+			Description: "The user you want to rob.",
+			Required:    true,
+		},
+	},
+}
+
+func RobHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
+	return func(e *handler.CommandEvent) error {
+		victim := e.SlashCommandInteractionData().User("user")
+		robberID := e.User().ID
+		guildID := int64(*e.GuildID())
+
+		if victim.ID == robberID {
+			return respondRobError(e, "You can't rob yourself.", "")
+		}
+		if victim.Bot {
+			return respondRobError(e, "You can't rob a bot.", "")
+		}
+
+		result, err := attemptRob(e.Ctx, b, guildID, int64(robberID), int64(victim.ID), time.Now().UTC())
+		var cooldownErr robCooldownError
+		if errors.As(err, &cooldownErr) {
+			return respondRobError(e, "You're laying low after your last robbery.",
+				fmt.Sprintf("You can rob again <t:%d:R>.", cooldownErr.nextAttempt.Unix()))
+		} else if errors.Is(err, errRobberTooPoor) {
+			return respondRobError(e, fmt.Sprintf("You need at least %d coins in hand to rob someone.", robMinBalance), "")
+		} else if err != nil {
+			slog.Error("Failed to rob user", slog.Any("err", err))
+			return err
+		}
+
+		var embed discord.Embed
+		switch result.Outcome {
+		case robOutcomeSuccess:
+			embed = utils.SuccessEmbed("Robbery successful!",
+				fmt.Sprintf("You stole %d coins from %s.", result.Amount, victim.Mention()))
+		case robOutcomeFailed:
+			embed = utils.FailureEmbed("You got caught!",
+				fmt.Sprintf("%s caught you in the act and you paid them %d coins.", victim.Mention(), result.Amount))
+		case robOutcomeProtected:
+			embed = utils.FailureEmbed("Nothing to steal.",
+				fmt.Sprintf("%s keeps all their coins in the safe.", victim.Mention()))
+		}
+
+		return e.Respond(
+			discord.InteractionResponseTypeCreateMessage, discord.NewMessageCreateBuilder().
+				SetEmbeds(embed).
+				Build(),
+		)
+	}
+}
+
+// attemptRob resolves a robbery and records its outcome in a single
+// transaction, with both balances locked so they can't change underneath it.
+// The cooldown is checked under the same locks, so two robberies started at
+// once can't both get past it.
+func attemptRob(ctx context.Context, b *mgbot.MartinGarrixBot, guildID, robberID, victimID int64, now time.Time) (db.RobLog, error) {
+	tx, err := b.DB.Begin(ctx)
+	if err != nil {
+		return db.RobLog{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := b.Queries.WithTx(tx)
+
+	inHand, err := mgbot.LockBalances(ctx, qtx, guildID, robberID, victimID)
+	if err != nil {
+		return db.RobLog{}, err
+	}
+
+	lastAttempt, err := qtx.GetLastRobAttempt(ctx, db.GetLastRobAttemptParams{
+		GuildID:  guildID,
+		RobberID: robberID,
+	})
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		return db.RobLog{}, err
+	}
+	if lastAttempt.Valid {
+		if nextAttempt := lastAttempt.Time.UTC().Add(robCooldown); now.Before(nextAttempt) {
+			return db.RobLog{}, robCooldownError{nextAttempt: nextAttempt}
+		}
+	}
+
+	robberInHand, victimInHand := inHand[robberID], inHand[victimID]
+	if robberInHand < robMinBalance {
+		return db.RobLog{}, errRobberTooPoor
+	}
+
+	params := db.CreateRobLogParams{
+		GuildID:   guildID,
+		RobberID:  robberID,
+		VictimID:  victimID,
+		Outcome:   robOutcomeProtected,
+		CreatedAt: pgtype.Timestamp{Time: now, Valid: true},
+	}
+
+	// Coins in the safe can't be stolen
+	if victimInHand > 0 {
+		params.SuccessChance = robSuccessChance(robberInHand, victimInHand)
+
+		from, to, kind := robberID, victimID, mgbot.CoinTxRobPenalty
+		if rand.Float64() < params.SuccessChance {
+			params.Outcome = robOutcomeSuccess
+			params.Amount = randomCut(victimInHand, 0.10, 0.40)
+			from, to, kind = victimID, robberID, mgbot.CoinTxRob
+		} else {
+			params.Outcome = robOutcomeFailed
+			params.Amount = randomCut(robberInHand, 0.10, 0.25)
+		}
+
+		if err := qtx.AddCoins(ctx, db.AddCoinsParams{
+			ID:      from,
+			GuildID: guildID,
+			InHand:  pgtype.Int8{Int64: -params.Amount, Valid: true},
+		}); err != nil {
+			return db.RobLog{}, err
+		}
+		if err := qtx.AddCoins(ctx, db.AddCoinsParams{
+			ID:      to,
+			GuildID: guildID,
+			InHand:  pgtype.Int8{Int64: params.Amount, Valid: true},
+		}); err != nil {
+			return db.RobLog{}, err
+		}
+
+		err := mgbot.RecordCoinTransactions(ctx, qtx, db.CreateCoinTransactionParams{
+			GuildID:        guildID,
+			UserID:         from,
+			Kind:           kind,
+			InHandChange:   -params.Amount,
+			CounterpartyID: pgtype.Int8{Int64: to, Valid: true},
+			CreatedAt:      params.CreatedAt,
+		}, db.CreateCoinTransactionParams{
+			GuildID:        guildID,
+			UserID:         to,
+			Kind:           kind,
+			InHandChange:   params.Amount,
+			CounterpartyID: pgtype.Int8{Int64: from, Valid: true},
+			CreatedAt:      params.CreatedAt,
+		})
+		if err != nil {
+			return db.RobLog{}, err
+		}
+	}
+
+	result, err := qtx.CreateRobLog(ctx, params)
+	if err != nil {
+		return db.RobLog{}, err
+	}
+
+	return result, tx.Commit(ctx)
+}
+
+// robSuccessChance makes victims holding a lot more than the robber easier
+// targets, and robbing someone poorer than yourself a long shot.
+func robSuccessChance(robberInHand, victimInHand int64) float64 {
+	ratio := float64(victimInHand) / float64(victimInHand+robberInHand)
+	return robMinChance + (robMaxChance-robMinChance)*ratio
+}
+
+// randomCut returns a random share of amount between minShare and maxShare,
+// always at least one coin.
+func randomCut(amount int64, minShare, maxShare float64) int64 {
+	share := minShare + rand.Float64()*(maxShare-minShare)
+	return max(int64(float64(amount)*share), 1)
+}
+
+func respondRobError(e *handler.CommandEvent, title, description string) error {
+	return e.Respond(
+		discord.InteractionResponseTypeCreateMessage, discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.FailureEmbed(title, description)).
+			SetEphemeral(true).
+			Build(),
+	)
+}
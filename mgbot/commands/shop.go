@@ -0,0 +1,591 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/json"
+	"github.com/disgoorg/paginator"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+const (
+	shopItemsPerPage      = 5
+	shopItemNameMaxLength = 100
+
+	shopKindRole      = "role"
+	shopKindXpBoost   = "xp_boost"
+	shopKindRankColor = "rank_color"
+)
+
+var (
+	errItemOwned       = errors.New("item already owned")
+	errXpBoostActive   = errors.New("xp boost already active")
+	errShopItemUnknown = errors.New("unknown shop item kind")
+)
+
+var shopItemOption = discord.ApplicationCommandOptionString{
+	Name:         "item",
+	Description:  "The shop item",
+	Required:     true,
+	Autocomplete: true,
+	MaxLength:    json.Ptr(shopItemNameMaxLength),
+}
+
+var shopNewItemOptions = []discord.ApplicationCommandOption{
+	discord.ApplicationCommandOptionString{
+		Name:        "name",
+		Description: "The name of the item",
+		Required:    true,
+		MaxLength:   json.Ptr(shopItemNameMaxLength),
+	},
+	discord.ApplicationCommandOptionInt{
+		Name:        "price",
+		Description: "How many Garrix coins the item costs",
+		Required:    true,
+		MinValue:    json.Ptr(1),
+	},
+}
+
+var shopDescriptionOption = discord.ApplicationCommandOptionString{
+	Name:        "description",
+	Description: "What members get with this item",
+	Required:    false,
+	MaxLength:   json.Ptr(400),
+}
+
+var shop = discord.SlashCommandCreate{
+	Name:        "shop",
+	Description: "Spend your Garrix coins on roles and perks",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "view",
+			Description: "Browse the items for sale",
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "buy",
+			Description: "Buy an item with coins from your hand, then your safe",
+			Options:     []discord.ApplicationCommandOption{shopItemOption},
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "inventory",
+			Description: "View the items you or another member own",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionUser{
+					Name:        "user",
+					Description: "The member to view the inventory of",
+					Required:    false,
+				},
+			},
+		},
+		discord.ApplicationCommandOptionSubCommandGroup{
+			Name:        "admin",
+			Description: "Manage the items for sale",
+			Options: []discord.ApplicationCommandOptionSubCommand{
+				{
+					Name:        "add-role",
+					Description: "Sell a role",
+					Options: append(slices.Clone(shopNewItemOptions),
+						discord.ApplicationCommandOptionRole{
+							Name:        "role",
+							Description: "The role members get",
+							Required:    true,
+						},
+						shopDescriptionOption,
+					),
+				},
+				{
+					Name:        "add-xp-boost",
+					Description: "Sell a temporary XP boost",
+					Options: append(slices.Clone(shopNewItemOptions),
+						discord.ApplicationCommandOptionFloat{
+							Name:        "multiplier",
+							Description: "How much XP is multiplied by, e.g. 1.5",
+							Required:    true,
+							MinValue:    json.Ptr(1.1),
+							MaxValue:    json.Ptr(5.0),
+						},
+						discord.ApplicationCommandOptionInt{
+							Name:        "minutes",
+							Description: "How long the boost lasts",
+							Required:    true,
+							MinValue:    json.Ptr(1),
+							MaxValue:    json.Ptr(7 * 24 * 60),
+						},
+						shopDescriptionOption,
+					),
+				},
+				{
+					Name:        "add-rank-color",
+					Description: "Sell a custom rank card colour",
+					Options: append(slices.Clone(shopNewItemOptions),
+						discord.ApplicationCommandOptionString{
+							Name:        "color",
+							Description: "The colour as a hex code, e.g. #1ABC9C",
+							Required:    true,
+							MinLength:   json.Ptr(6),
+							MaxLength:   json.Ptr(7),
+						},
+						shopDescriptionOption,
+					),
+				},
+				{
+					Name:        "remove",
+					Description: "Stop selling an item, members keep the ones they bought",
+					Options:     []discord.ApplicationCommandOption{shopItemOption},
+				},
+			},
+		},
+	},
+}
+
+func ShopHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
+	return func(e *handler.CommandEvent) error {
+		data := e.SlashCommandInteractionData()
+
+		if data.SubCommandGroupName != nil && *data.SubCommandGroupName == "admin" {
+			if !b.IsOwner(e.User().ID) && !e.Member().Permissions.Has(discord.PermissionAdministrator) {
+				return respondShopError(e, "Permission Denied", "Only administrators can manage the shop.")
+			}
+
+			switch *data.SubCommandName {
+			case "add-role":
+				return handleShopAddRole(b, e)
+			case "add-xp-boost":
+				return handleShopAddXpBoost(b, e)
+			case "add-rank-color":
+				return handleShopAddRankColor(b, e)
+			case "remove":
+				return handleShopRemove(b, e)
+			}
+		}
+
+		switch *data.SubCommandName {
+		case "view":
+			return handleShopView(b, e)
+		case "buy":
+			return handleShopBuy(b, e)
+		case "inventory":
+			return handleShopInventory(b, e)
+		}
+
+		return respondShopError(e, "Invalid Command", "Unknown subcommand")
+	}
+}
+
+func ShopAutocompleteHandler(b *mgbot.MartinGarrixBot) handler.AutocompleteHandler {
+	return func(e *handler.AutocompleteEvent) error {
+		names, err := b.Queries.GetShopItemNamesLike(e.Ctx, db.GetShopItemNamesLikeParams{
+			GuildID: int64(*e.GuildID()),
+			Name:    likePattern(strings.TrimSpace(e.Data.String("item"))),
+		})
+		if err != nil {
+			slog.Error("Failed to get shop item names for autocomplete", slog.Any("err", err))
+			return err
+		}
+
+		choices := make([]discord.AutocompleteChoice, len(names))
+		for i, name := range names {
+			choices[i] = discord.AutocompleteChoiceString{Name: name, Value: name}
+		}
+		return e.AutocompleteResult(choices)
+	}
+}
+
+func handleShopView(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	items, err := b.Queries.GetShopItems(e.Ctx, int64(*e.GuildID()))
+	if err != nil {
+		slog.Error("Failed to get shop items", slog.Any("err", err))
+		return respondShopError(e, "Error", "Failed to load the shop.")
+	}
+
+	if len(items) == 0 {
+		return respondShopError(e, "The shop is empty.", "Ask an administrator to add some items with `/shop admin`.")
+	}
+
+	return b.Paginator.Create(e.Respond, paginator.Pages{
+		ID:      e.ID().String(),
+		Creator: e.User().ID,
+		Pages:   (len(items) + shopItemsPerPage - 1) / shopItemsPerPage,
+		PageFunc: func(page int, embed *discord.EmbedBuilder) {
+			start := page * shopItemsPerPage
+			end := min(start+shopItemsPerPage, len(items))
+
+			embed.SetTitle("Garrix Shop").
+				SetColor(utils.ColorInfo).
+				SetFooterTextf("%d items | Buy with /shop buy", len(items))
+
+			for _, item := range items[start:end] {
+				value := describeShopItem(item)
+				if item.Description != "" {
+					value += "\n" + item.Description
+				}
+				embed.AddField(fmt.Sprintf("%s - %d coins", item.Name, item.Price), value, false)
+			}
+		},
+		ExpireMode: paginator.ExpireModeAfterLastUsage,
+	}, false)
+}
+
+func handleShopBuy(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	name := strings.TrimSpace(e.SlashCommandInteractionData().String("item"))
+	guildID := *e.GuildID()
+
+	item, err := b.Queries.GetShopItemByName(e.Ctx, db.GetShopItemByNameParams{
+		GuildID: int64(guildID),
+		Name:    name,
+	})
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return respondShopError(e, "Item Not Found", fmt.Sprintf("There is no item called `%s` in the shop.", name))
+	} else if err != nil {
+		return err
+	}
+
+	if item.Kind == shopKindRole && slices.Contains(e.Member().RoleIDs, snowflake.ID(item.RoleID.Int64)) {
+		return respondShopError(e, "You already have this role.", "")
+	}
+
+	err = purchaseShopItem(e.Ctx, b, guildID, e.User().ID, item)
+	switch {
+	case errors.Is(err, mgbot.ErrInsufficientFunds):
+		return respondShopError(e, "You don't have enough coins.",
+			fmt.Sprintf("**%s** costs %d coins, counting your hand and your safe.", item.Name, item.Price))
+	case errors.Is(err, errItemOwned):
+		return respondShopError(e, "You already own this item.", "")
+	case errors.Is(err, errXpBoostActive):
+		return respondShopError(e, "You already have an active XP boost.", "Wait for it to run out before buying another one.")
+	case err != nil:
+		slog.Error("Failed to buy shop item", slog.Int64("item_id", item.ID), slog.Any("err", err))
+		return respondShopError(e, "Purchase Failed", "Something went wrong and you haven't been charged.")
+	}
+
+	return e.Respond(
+		discord.InteractionResponseTypeCreateMessage, discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed(fmt.Sprintf("You bought %s!", item.Name),
+				fmt.Sprintf("%s\nYou paid %d coins.", describeShopItem(item), item.Price))).
+			Build(),
+	)
+}
+
+// purchaseShopItem charges the member for item and adds it to their inventory
+// in a single transaction. The price is taken from coins in hand first and the
+// safe covers the rest. Role items are granted once the charge is committed,
+// so Discord isn't called while the member's balance is locked, and the charge
+// is refunded if the grant fails.
+func purchaseShopItem(ctx context.Context, b *mgbot.MartinGarrixBot, guildID, userID snowflake.ID, item db.ShopItem) error {
+	now := time.Now().UTC()
+
+	// A role a moderator has taken away can be bought again
+	var hasRole bool
+	if item.Kind == shopKindRole {
+		member, err := utils.GetMember(b.Client, guildID, userID)
+		if err != nil {
+			return err
+		}
+		hasRole = slices.Contains(member.RoleIDs, snowflake.ID(item.RoleID.Int64))
+	}
+
+	tx, err := b.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	q := b.Queries.WithTx(tx)
+
+	balance, err := q.GetBalanceForUpdate(ctx, db.GetBalanceForUpdateParams{
+		ID:      int64(userID),
+		GuildID: int64(guildID),
+	})
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return mgbot.ErrInsufficientFunds
+	} else if err != nil {
+		return err
+	}
+
+	inventoryItem := db.CreateInventoryItemParams{
+		GuildID:     int64(guildID),
+		UserID:      int64(userID),
+		ItemID:      item.ID,
+		PricePaid:   item.Price,
+		PurchasedAt: pgtype.Timestamp{Time: now, Valid: true},
+	}
+
+	switch item.Kind {
+	case shopKindRole, shopKindRankColor:
+		owned, err := q.HasInventoryItem(ctx, db.HasInventoryItemParams{
+			GuildID: int64(guildID),
+			UserID:  int64(userID),
+			ItemID:  item.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if owned && (item.Kind != shopKindRole || hasRole) {
+			return errItemOwned
+		}
+	case shopKindXpBoost:
+		_, err := q.GetActiveXpBoost(ctx, db.GetActiveXpBoostParams{
+			GuildID:   int64(guildID),
+			UserID:    int64(userID),
+			ExpiresAt: inventoryItem.PurchasedAt,
+		})
+		if err == nil {
+			return errXpBoostActive
+		} else if !errors.Is(err, db.ErrRecordNotFound) {
+			return err
+		}
+		inventoryItem.ExpiresAt = pgtype.Timestamp{
+			Time:  now.Add(time.Duration(item.BoostMinutes.Int32) * time.Minute),
+			Valid: true,
+		}
+	default:
+		return errShopItemUnknown
+	}
+
+	inHand, inSafe := max(balance.InHand.Int64, 0), max(balance.GarrixCoins.Int64, 0)
+	if inHand+inSafe < item.Price {
+		return mgbot.ErrInsufficientFunds
+	}
+	fromHand := min(item.Price, inHand)
+	fromSafe := item.Price - fromHand
+
+	if err := q.SpendCoins(ctx, db.SpendCoinsParams{
+		ID:          int64(userID),
+		GuildID:     int64(guildID),
+		InHand:      pgtype.Int8{Int64: fromHand, Valid: true},
+		GarrixCoins: pgtype.Int8{Int64: fromSafe, Valid: true},
+	}); err != nil {
+		return err
+	}
+
+	inventoryID, err := q.CreateInventoryItem(ctx, inventoryItem)
+	if err != nil {
+		return err
+	}
+
+	err = mgbot.RecordCoinTransactions(ctx, q, db.CreateCoinTransactionParams{
+		GuildID:      int64(guildID),
+		UserID:       int64(userID),
+		Kind:         mgbot.CoinTxShopPurchase,
+		InHandChange: -fromHand,
+		SafeChange:   -fromSafe,
+		Note:         pgtype.Text{String: item.Name, Valid: true},
+		CreatedAt:    inventoryItem.PurchasedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if item.Kind != shopKindRole {
+		return nil
+	}
+	err = b.Client.Rest().AddMemberRole(guildID, userID, snowflake.ID(item.RoleID.Int64),
+		rest.WithReason(fmt.Sprintf("Bought %s in the shop", item.Name)))
+	if err == nil {
+		return nil
+	}
+
+	refundErr := b.UpdateCoins(ctx, func(q *db.Queries) error {
+		if err := q.DeleteInventoryItem(ctx, inventoryID); err != nil {
+			return err
+		}
+		// Spending a negative amount puts the coins back where they came from
+		return q.SpendCoins(ctx, db.SpendCoinsParams{
+			ID:          int64(userID),
+			GuildID:     int64(guildID),
+			InHand:      pgtype.Int8{Int64: -fromHand, Valid: true},
+			GarrixCoins: pgtype.Int8{Int64: -fromSafe, Valid: true},
+		})
+	}, db.CreateCoinTransactionParams{
+		GuildID:      int64(guildID),
+		UserID:       int64(userID),
+		Kind:         mgbot.CoinTxShopRefund,
+		InHandChange: fromHand,
+		SafeChange:   fromSafe,
+		Note:         pgtype.Text{String: item.Name, Valid: true},
+	})
+	if refundErr != nil {
+		slog.Error("Failed to refund shop item after the role couldn't be granted",
+			slog.Int64("guild_id", int64(guildID)),
+			slog.Int64("user_id", int64(userID)),
+			slog.Int64("item_id", item.ID),
+			slog.Any("err", refundErr),
+		)
+	}
+	return err
+}
+
+func handleShopInventory(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	user := e.User()
+	if target, ok := e.SlashCommandInteractionData().OptUser("user"); ok {
+		user = target
+	}
+
+	items, err := b.Queries.GetInventory(e.Ctx, db.GetInventoryParams{
+		GuildID: int64(*e.GuildID()),
+		UserID:  int64(user.ID),
+	})
+	if err != nil {
+		slog.Error("Failed to get inventory", slog.Any("err", err))
+		return respondShopError(e, "Error", "Failed to load the inventory.")
+	}
+
+	if len(items) == 0 {
+		return respondShopError(e, fmt.Sprintf("%s doesn't own any items yet.", user.EffectiveName()), "")
+	}
+
+	now := time.Now().UTC()
+	var sb strings.Builder
+	for _, item := range items {
+		sb.WriteString(fmt.Sprintf("**%s** bought <t:%d:R> for %d coins", item.Name, item.PurchasedAt.Time.Unix(), item.PricePaid))
+		if item.ExpiresAt.Valid {
+			if item.ExpiresAt.Time.After(now) {
+				sb.WriteString(fmt.Sprintf(", active until <t:%d:t>", item.ExpiresAt.Time.Unix()))
+			} else {
+				sb.WriteString(", expired")
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle(fmt.Sprintf("%s's Inventory", user.EffectiveName())).
+		SetThumbnail(user.EffectiveAvatarURL()).
+		SetDescription(utils.CutString(sb.String(), 4096)).
+		SetColor(utils.ColorInfo).
+		SetFooterTextf("%d items", len(items))
+
+	return e.Respond(
+		discord.InteractionResponseTypeCreateMessage, discord.NewMessageCreateBuilder().
+			SetEmbeds(embed.Build()).
+			Build(),
+	)
+}
+
+func handleShopAddRole(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	role := e.SlashCommandInteractionData().Role("role")
+	if role.Managed || role.ID == *e.GuildID() {
+		return respondShopError(e, "Invalid Role", "That role can't be given to members.")
+	}
+
+	params := newShopItemParams(e, shopKindRole)
+	params.RoleID = pgtype.Int8{Int64: int64(role.ID), Valid: true}
+	return createShopItem(b, e, params)
+}
+
+func handleShopAddXpBoost(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+
+	params := newShopItemParams(e, shopKindXpBoost)
+	params.BoostMultiplier = pgtype.Float8{Float64: data.Float("multiplier"), Valid: true}
+	params.BoostMinutes = pgtype.Int4{Int32: int32(data.Int("minutes")), Valid: true}
+	return createShopItem(b, e, params)
+}
+
+func handleShopAddRankColor(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	color, err := parseHexColor(e.SlashCommandInteractionData().String("color"))
+	if err != nil {
+		return respondShopError(e, "Invalid Colour", "Give the colour as a hex code like `#1ABC9C`.")
+	}
+
+	params := newShopItemParams(e, shopKindRankColor)
+	params.Color = pgtype.Int4{Int32: color, Valid: true}
+	return createShopItem(b, e, params)
+}
+
+func newShopItemParams(e *handler.CommandEvent, kind string) db.CreateShopItemParams {
+	data := e.SlashCommandInteractionData()
+	return db.CreateShopItemParams{
+		GuildID:     int64(*e.GuildID()),
+		Name:        strings.TrimSpace(data.String("name")),
+		Description: strings.TrimSpace(data.String("description")),
+		Kind:        kind,
+		Price:       int64(data.Int("price")),
+		CreatedAt:   pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	}
+}
+
+func createShopItem(b *mgbot.MartinGarrixBot, e *handler.CommandEvent, params db.CreateShopItemParams) error {
+	if params.Name == "" {
+		return respondShopError(e, "Invalid Name", "Item names can't be empty.")
+	}
+
+	item, err := b.Queries.CreateShopItem(e.Ctx, params)
+	if db.ErrorCode(err) == db.UniqueViolation {
+		return respondShopError(e, "Item Already Exists", fmt.Sprintf("There is already an item called `%s`.", params.Name))
+	} else if err != nil {
+		slog.Error("Failed to create shop item", slog.Any("err", err))
+		return respondShopError(e, "Error", "Failed to add the item.")
+	}
+
+	return e.Respond(
+		discord.InteractionResponseTypeCreateMessage, discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed(fmt.Sprintf("Added %s to the shop.", item.Name),
+				fmt.Sprintf("%s\nPrice: %d coins", describeShopItem(item), item.Price))).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func handleShopRemove(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	name := strings.TrimSpace(e.SlashCommandInteractionData().String("item"))
+
+	removed, err := b.Queries.DisableShopItem(e.Ctx, db.DisableShopItemParams{
+		GuildID: int64(*e.GuildID()),
+		Name:    name,
+	})
+	if err != nil {
+		slog.Error("Failed to remove shop item", slog.Any("err", err))
+		return respondShopError(e, "Error", "Failed to remove the item.")
+	}
+	if removed == 0 {
+		return respondShopError(e, "Item Not Found", fmt.Sprintf("There is no item called `%s` in the shop.", name))
+	}
+
+	return e.Respond(
+		discord.InteractionResponseTypeCreateMessage, discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed(fmt.Sprintf("Removed %s from the shop.", name),
+				"Members who bought it keep it.")).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+// describeShopItem returns a one line summary of what an item gives.
+func describeShopItem(item db.ShopItem) string {
+	switch item.Kind {
+	case shopKindRole:
+		return fmt.Sprintf("Role: <@&%d>", item.RoleID.Int64)
+	case shopKindXpBoost:
+		return fmt.Sprintf("XP boost: %.1fx for %d minutes", item.BoostMultiplier.Float64, item.BoostMinutes.Int32)
+	case shopKindRankColor:
+		return fmt.Sprintf("Rank card colour: #%06X", item.Color.Int32)
+	}
+	return item.Kind
+}
+
+func respondShopError(e *handler.CommandEvent, title, description string) error {
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.FailureEmbed(title, description)).
+			SetEphemeral(true).
+			Build(),
+	)
+}
@@ -0,0 +1,456 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/json"
+	"github.com/disgoorg/paginator"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+const (
+	tagNameMaxLength    = 100
+	tagContentMaxLength = 2000
+	tagsPerPage         = 15
+	tagSearchLimit      = 25
+)
+
+var tagNameOption = discord.ApplicationCommandOptionString{
+	Name:         "name",
+	Description:  "The name of the tag",
+	Required:     true,
+	Autocomplete: true,
+	MaxLength:    json.Ptr(tagNameMaxLength),
+}
+
+var tagContentOption = discord.ApplicationCommandOptionString{
+	Name:        "content",
+	Description: "What the tag should say",
+	Required:    true,
+	MaxLength:   json.Ptr(tagContentMaxLength),
+}
+
+var tag = discord.SlashCommandCreate{
+	Name:        "tag",
+	Description: "Save and recall text snippets for this server",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "show",
+			Description: "Show a tag",
+			Options:     []discord.ApplicationCommandOption{tagNameOption},
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "create",
+			Description: "Create a new tag",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionString{
+					Name:        "name",
+					Description: "The name of the tag",
+					Required:    true,
+					MaxLength:   json.Ptr(tagNameMaxLength),
+				},
+				tagContentOption,
+			},
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "edit",
+			Description: "Edit one of your tags",
+			Options:     []discord.ApplicationCommandOption{tagNameOption, tagContentOption},
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "delete",
+			Description: "Delete one of your tags",
+			Options:     []discord.ApplicationCommandOption{tagNameOption},
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "info",
+			Description: "Show who owns a tag and how often it has been used",
+			Options:     []discord.ApplicationCommandOption{tagNameOption},
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "list",
+			Description: "List the tags in this server",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionUser{
+					Name:        "user",
+					Description: "Only list tags owned by this user",
+					Required:    false,
+				},
+			},
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "search",
+			Description: "Search tags by name and content",
+			Options: []discord.ApplicationCommandOption{
+				discord.ApplicationCommandOptionString{
+					Name:        "query",
+					Description: "What to search for",
+					Required:    true,
+				},
+			},
+		},
+		discord.ApplicationCommandOptionSubCommand{
+			Name:        "transfer",
+			Description: "Give one of your tags to someone else",
+			Options: []discord.ApplicationCommandOption{
+				tagNameOption,
+				discord.ApplicationCommandOptionUser{
+					Name:        "user",
+					Description: "The new owner of the tag",
+					Required:    true,
+				},
+			},
+		},
+	},
+}
+
+func TagHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
+	return func(e *handler.CommandEvent) error {
+		data := e.SlashCommandInteractionData()
+
+		switch *data.SubCommandName {
+		case "show":
+			return handleTagShow(b, e)
+		case "create":
+			return handleTagCreate(b, e)
+		case "edit":
+			return handleTagEdit(b, e)
+		case "delete":
+			return handleTagDelete(b, e)
+		case "info":
+			return handleTagInfo(b, e)
+		case "list":
+			return handleTagList(b, e)
+		case "search":
+			return handleTagSearch(b, e)
+		case "transfer":
+			return handleTagTransfer(b, e)
+		default:
+			return respondTagError(e, "Invalid Command", "Unknown subcommand")
+		}
+	}
+}
+
+func TagAutocompleteHandler(b *mgbot.MartinGarrixBot) handler.AutocompleteHandler {
+	return func(e *handler.AutocompleteEvent) error {
+		names, err := b.Queries.GetTagNamesLike(e.Ctx, db.GetTagNamesLikeParams{
+			GuildID: int64(*e.GuildID()),
+			Name:    likePattern(normalizeTagName(e.Data.String("name"))),
+		})
+		if err != nil {
+			slog.Error("Failed to get tag names for autocomplete", slog.Any("err", err))
+			return err
+		}
+
+		choices := make([]discord.AutocompleteChoice, len(names))
+		for i, name := range names {
+			choices[i] = discord.AutocompleteChoiceString{
+				Name:  name,
+				Value: name,
+			}
+		}
+
+		return e.AutocompleteResult(choices)
+	}
+}
+
+func handleTagShow(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	name := normalizeTagName(e.SlashCommandInteractionData().String("name"))
+
+	t, err := b.Queries.UseTag(e.Ctx, db.UseTagParams{
+		GuildID: int64(*e.GuildID()),
+		Name:    name,
+	})
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return respondTagNotFound(e, name)
+	} else if err != nil {
+		slog.Error("Failed to use tag", slog.Any("err", err))
+		return respondTagError(e, "Error", "Failed to fetch the tag.")
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetContent(t.Content).
+			SetAllowedMentions(&discord.AllowedMentions{}).
+			Build(),
+	)
+}
+
+func handleTagCreate(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	name := normalizeTagName(data.String("name"))
+	content := strings.TrimSpace(data.String("content"))
+
+	if name == "" || content == "" {
+		return respondTagError(e, "Invalid Tag", "Tag name and content cannot be empty.")
+	}
+
+	_, err := b.Queries.CreateTag(e.Ctx, db.CreateTagParams{
+		GuildID:   int64(*e.GuildID()),
+		Name:      name,
+		Content:   content,
+		CreatorID: int64(e.User().ID),
+	})
+	if db.ErrorCode(err) == db.UniqueViolation {
+		return respondTagError(e, "Tag Exists", fmt.Sprintf("A tag called `%s` already exists.", name))
+	} else if err != nil {
+		slog.Error("Failed to create tag", slog.Any("err", err))
+		return respondTagError(e, "Error", "Failed to create the tag.")
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Tag Created", fmt.Sprintf("Created tag `%s`.", name))).
+			Build(),
+	)
+}
+
+func handleTagEdit(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	name := normalizeTagName(data.String("name"))
+	content := strings.TrimSpace(data.String("content"))
+	guildID := int64(*e.GuildID())
+
+	if content == "" {
+		return respondTagError(e, "Invalid Tag", "Tag content cannot be empty.")
+	}
+
+	t, err := b.Queries.GetTag(e.Ctx, db.GetTagParams{GuildID: guildID, Name: name})
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return respondTagNotFound(e, name)
+	} else if err != nil {
+		slog.Error("Failed to get tag", slog.Any("err", err))
+		return respondTagError(e, "Error", "Failed to fetch the tag.")
+	}
+
+	if t.CreatorID != int64(e.User().ID) {
+		return respondTagError(e, "Permission Denied", "You can only edit tags you own.")
+	}
+
+	err = b.Queries.UpdateTagContent(e.Ctx, db.UpdateTagContentParams{
+		GuildID: guildID,
+		Name:    name,
+		Content: content,
+	})
+	if err != nil {
+		slog.Error("Failed to update tag content", slog.Any("err", err))
+		return respondTagError(e, "Error", "Failed to edit the tag.")
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Tag Edited", fmt.Sprintf("Updated tag `%s`.", name))).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func handleTagDelete(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	name := normalizeTagName(e.SlashCommandInteractionData().String("name"))
+	guildID := int64(*e.GuildID())
+
+	t, err := b.Queries.GetTag(e.Ctx, db.GetTagParams{GuildID: guildID, Name: name})
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return respondTagNotFound(e, name)
+	} else if err != nil {
+		slog.Error("Failed to get tag", slog.Any("err", err))
+		return respondTagError(e, "Error", "Failed to fetch the tag.")
+	}
+
+	// Moderators can delete anyone's tag
+	if t.CreatorID != int64(e.User().ID) &&
+		!utils.HasModeratorPermissions(e.Ctx, b.DB, b.Client.Rest(), *e.GuildID(), e.Member()) {
+		return respondTagError(e, "Permission Denied", "You can only delete tags you own.")
+	}
+
+	if err := b.Queries.DeleteTag(e.Ctx, db.DeleteTagParams{GuildID: guildID, Name: name}); err != nil {
+		slog.Error("Failed to delete tag", slog.Any("err", err))
+		return respondTagError(e, "Error", "Failed to delete the tag.")
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Tag Deleted", fmt.Sprintf("Deleted tag `%s`.", name))).
+			SetEphemeral(true).
+			Build(),
+	)
+}
+
+func handleTagInfo(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	name := normalizeTagName(e.SlashCommandInteractionData().String("name"))
+
+	t, err := b.Queries.GetTag(e.Ctx, db.GetTagParams{GuildID: int64(*e.GuildID()), Name: name})
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return respondTagNotFound(e, name)
+	} else if err != nil {
+		slog.Error("Failed to get tag", slog.Any("err", err))
+		return respondTagError(e, "Error", "Failed to fetch the tag.")
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle(fmt.Sprintf("Tag: %s", t.Name)).
+		SetColor(utils.ColorInfo).
+		AddField("Owner", fmt.Sprintf("<@%d>", t.CreatorID), true).
+		AddField("Uses", fmt.Sprintf("%d", t.Uses.Int32), true)
+
+	if t.CreatedAt.Valid {
+		embed.AddField("Created", fmt.Sprintf("<t:%d:R>", t.CreatedAt.Time.Unix()), true)
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed.Build()).
+			Build(),
+	)
+}
+
+func handleTagList(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	guildID := int64(*e.GuildID())
+
+	var (
+		tags  []db.Tag
+		err   error
+		title = "Tags"
+	)
+	if user, ok := data.OptUser("user"); ok {
+		title = fmt.Sprintf("Tags owned by %s", user.EffectiveName())
+		tags, err = b.Queries.GetTagsByCreator(e.Ctx, db.GetTagsByCreatorParams{
+			GuildID:   guildID,
+			CreatorID: int64(user.ID),
+		})
+	} else {
+		tags, err = b.Queries.GetTagsByGuild(e.Ctx, guildID)
+	}
+	if err != nil {
+		slog.Error("Failed to list tags", slog.Any("err", err))
+		return respondTagError(e, "Error", "Failed to fetch tags.")
+	}
+
+	if len(tags) == 0 {
+		return respondTagError(e, "No Tags", "There are no tags to show.")
+	}
+
+	return b.Paginator.Create(e.Respond, paginator.Pages{
+		ID:      e.ID().String(),
+		Creator: e.User().ID,
+		Pages:   (len(tags) + tagsPerPage - 1) / tagsPerPage,
+		PageFunc: func(page int, embed *discord.EmbedBuilder) {
+			start := page * tagsPerPage
+			end := min(start+tagsPerPage, len(tags))
+
+			embed.SetTitle(title).
+				SetDescription(formatTagList(tags[start:end], start)).
+				SetFooterTextf("%d tags", len(tags))
+		},
+		ExpireMode: paginator.ExpireModeAfterLastUsage,
+	}, false)
+}
+
+func handleTagSearch(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	query := strings.ToLower(strings.TrimSpace(e.SlashCommandInteractionData().String("query")))
+
+	tags, err := b.Queries.SearchTags(e.Ctx, db.SearchTagsParams{
+		GuildID: int64(*e.GuildID()),
+		Name:    likePattern(query),
+		Limit:   tagSearchLimit,
+	})
+	if err != nil {
+		slog.Error("Failed to search tags", slog.Any("err", err))
+		return respondTagError(e, "Error", "Failed to search tags.")
+	}
+
+	if len(tags) == 0 {
+		return respondTagError(e, "No Results", fmt.Sprintf("No tags matched `%s`.", query))
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle(fmt.Sprintf("Tags matching \"%s\"", utils.CutString(query, 100))).
+		SetDescription(formatTagList(tags, 0)).
+		SetColor(utils.ColorInfo)
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(embed.Build()).
+			Build(),
+	)
+}
+
+func handleTagTransfer(b *mgbot.MartinGarrixBot, e *handler.CommandEvent) error {
+	data := e.SlashCommandInteractionData()
+	name := normalizeTagName(data.String("name"))
+	newOwner := data.User("user")
+	guildID := int64(*e.GuildID())
+
+	if newOwner.Bot {
+		return respondTagError(e, "Invalid User", "Tags cannot be given to bots.")
+	}
+
+	t, err := b.Queries.GetTag(e.Ctx, db.GetTagParams{GuildID: guildID, Name: name})
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return respondTagNotFound(e, name)
+	} else if err != nil {
+		slog.Error("Failed to get tag", slog.Any("err", err))
+		return respondTagError(e, "Error", "Failed to fetch the tag.")
+	}
+
+	if t.CreatorID != int64(e.User().ID) &&
+		!utils.HasModeratorPermissions(e.Ctx, b.DB, b.Client.Rest(), *e.GuildID(), e.Member()) {
+		return respondTagError(e, "Permission Denied", "You can only transfer tags you own.")
+	}
+
+	err = b.Queries.TransferTag(e.Ctx, db.TransferTagParams{
+		GuildID:   guildID,
+		Name:      name,
+		CreatorID: int64(newOwner.ID),
+	})
+	if err != nil {
+		slog.Error("Failed to transfer tag", slog.Any("err", err))
+		return respondTagError(e, "Error", "Failed to transfer the tag.")
+	}
+
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.SuccessEmbed("Tag Transferred",
+				fmt.Sprintf("`%s` is now owned by <@%d>.", name, newOwner.ID))).
+			Build(),
+	)
+}
+
+func formatTagList(tags []db.Tag, offset int) string {
+	var sb strings.Builder
+	for i, t := range tags {
+		sb.WriteString(fmt.Sprintf("`%d.` **%s** (%d uses)\n", offset+i+1, t.Name, t.Uses.Int32))
+	}
+	return sb.String()
+}
+
+// normalizeTagName lowercases and trims a tag name, tag names are case insensitive.
+func normalizeTagName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// likePattern escapes LIKE wildcards in s and wraps it for a contains match.
+func likePattern(s string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s) + "%"
+}
+
+func respondTagNotFound(e *handler.CommandEvent, name string) error {
+	return respondTagError(e, "Tag Not Found", fmt.Sprintf("There is no tag called `%s`.", name))
+}
+
+func respondTagError(e *handler.CommandEvent, title, description string) error {
+	return e.Respond(discord.InteractionResponseTypeCreateMessage,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(utils.FailureEmbed(title, description)).
+			SetEphemeral(true).
+			Build(),
+	)
+}
@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/paginator"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+const transactionsPerPage = 10
+
+var coinTxLabels = map[string]string{
+	mgbot.CoinTxQuizReward:   "Quiz reward",
+	mgbot.CoinTxGive:         "Transfer",
+	mgbot.CoinTxDeposit:      "Deposit",
+	mgbot.CoinTxWithdraw:     "Withdrawal",
+	mgbot.CoinTxRob:          "Robbery",
+	mgbot.CoinTxRobPenalty:   "Robbery penalty",
+	mgbot.CoinTxAdminGrant:   "Admin adjustment",
+	mgbot.CoinTxAdminReset:   "Admin reset",
+	mgbot.CoinTxDaily:        "Daily reward",
+	mgbot.CoinTxWeekly:       "Weekly reward",
+	mgbot.CoinTxShopPurchase: "Shop purchase",
+	mgbot.CoinTxShopRefund:   "Shop refund",
+}
+
+var transactions = discord.SlashCommandCreate{
+	Name:        "transactions",
+	Description: "View the history of coin transactions.",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionUser{
+			Name:        "user",
+			Description: "The member to view transactions of (moderators only).",
+			Required:    false,
+		},
+	},
+}
+
+func TransactionsHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
+	return func(e *handler.CommandEvent) error {
+		user := e.User()
+		if target, ok := e.SlashCommandInteractionData().OptUser("user"); ok && target.ID != user.ID {
+			if !utils.HasModeratorPermissions(e.Ctx, b.DB, b.Client.Rest(), *e.GuildID(), e.Member()) {
+				return e.Respond(
+					discord.InteractionResponseTypeCreateMessage, discord.NewMessageCreateBuilder().
+						SetEmbeds(utils.FailureEmbed("Only moderators can view other members' transactions.", "")).
+						SetEphemeral(true).
+						Build(),
+				)
+			}
+			user = target
+		}
+
+		guildID := int64(*e.GuildID())
+		total, err := b.Queries.GetCoinTransactionsByUserCount(e.Ctx, db.GetCoinTransactionsByUserCountParams{
+			GuildID: guildID,
+			UserID:  int64(user.ID),
+		})
+		if err != nil {
+			return err
+		}
+
+		if total == 0 {
+			return e.Respond(
+				discord.InteractionResponseTypeCreateMessage, discord.NewMessageCreateBuilder().
+					SetEmbeds(utils.FailureEmbed(fmt.Sprintf("%s has no coin transactions yet.", user.EffectiveName()), "")).
+					SetEphemeral(true).
+					Build(),
+			)
+		}
+
+		return b.Paginator.Create(e.Respond, paginator.Pages{
+			ID:      e.ID().String(),
+			Creator: e.User().ID,
+			Pages:   int((total + transactionsPerPage - 1) / transactionsPerPage),
+			PageFunc: func(page int, embed *discord.EmbedBuilder) {
+				embed.SetTitle(fmt.Sprintf("%s's Transactions", user.EffectiveName())).
+					SetThumbnail(user.EffectiveAvatarURL()).
+					SetFooterTextf("%d transactions", total)
+
+				records, err := b.Queries.GetCoinTransactionsByUser(context.Background(), db.GetCoinTransactionsByUserParams{
+					GuildID: guildID,
+					UserID:  int64(user.ID),
+					Limit:   transactionsPerPage,
+					Offset:  int32(page * transactionsPerPage),
+				})
+				if err != nil {
+					slog.Error("Failed to get coin transactions", slog.Any("err", err))
+					embed.SetDescription("Failed to load this page.")
+					return
+				}
+
+				embed.SetDescription(formatCoinTransactions(records))
+			},
+			ExpireMode: paginator.ExpireModeAfterLastUsage,
+		}, true)
+	}
+}
+
+func formatCoinTransactions(records []db.CoinTransaction) string {
+	var sb strings.Builder
+	for _, record := range records {
+		label, ok := coinTxLabels[record.Kind]
+		if !ok {
+			label = record.Kind
+		}
+
+		var changes []string
+		if record.InHandChange != 0 {
+			changes = append(changes, fmt.Sprintf("%+d in hand", record.InHandChange))
+		}
+		if record.SafeChange != 0 {
+			changes = append(changes, fmt.Sprintf("%+d in safe", record.SafeChange))
+		}
+
+		sb.WriteString(fmt.Sprintf("<t:%d:R> **%s** %s", record.CreatedAt.Time.Unix(), label, strings.Join(changes, ", ")))
+		if record.CounterpartyID.Valid {
+			sb.WriteString(fmt.Sprintf(" with <@%d>", record.CounterpartyID.Int64))
+		}
+		if record.Note.Valid && record.Note.String != "" {
+			sb.WriteString(fmt.Sprintf(" (%s)", record.Note.String))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -89,10 +89,18 @@ func WithdrawHandler(b *mgbot.MartinGarrixBot) handler.CommandHandler {
 			amtToWithdraw = int64(amt)
 		}
 
-		err = b.Queries.WithdrawAmount(e.Ctx, db.WithdrawAmountParams{
-			ID:      int64(e.Member().User.ID),
-			GuildID: int64(*e.GuildID()),
-			InHand:  pgtype.Int8{Int64: amtToWithdraw, Valid: true},
+		err = b.UpdateCoins(e.Ctx, func(q *db.Queries) error {
+			return q.WithdrawAmount(e.Ctx, db.WithdrawAmountParams{
+				ID:      int64(e.Member().User.ID),
+				GuildID: int64(*e.GuildID()),
+				InHand:  pgtype.Int8{Int64: amtToWithdraw, Valid: true},
+			})
+		}, db.CreateCoinTransactionParams{
+			GuildID:      int64(*e.GuildID()),
+			UserID:       int64(e.Member().User.ID),
+			Kind:         mgbot.CoinTxWithdraw,
+			InHandChange: amtToWithdraw,
+			SafeChange:   -amtToWithdraw,
 		})
 
 		if err != nil {
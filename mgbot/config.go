@@ -32,6 +32,7 @@ type Config struct {
 
 type BotConfig struct {
 	DevGuilds          []snowflake.ID `toml:"dev_guilds"`
+	OwnerIDs           []snowflake.ID `toml:"owner_ids"`
 	Token              string         `toml:"token"`
 	YoutubeAPIKey      string         `toml:"youtube_api_key"`
 	GoogleServiceFile  string         `toml:"google_service_file"`
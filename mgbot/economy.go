@@ -0,0 +1,157 @@
+package mgbot
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+)
+
+// Kinds of coin transaction recorded in the coin_transactions ledger.
+const (
+	CoinTxQuizReward   = "quiz_reward"
+	CoinTxGive         = "give"
+	CoinTxDeposit      = "deposit"
+	CoinTxWithdraw     = "withdraw"
+	CoinTxRob          = "rob"
+	CoinTxRobPenalty   = "rob_penalty"
+	CoinTxAdminGrant   = "admin_grant"
+	CoinTxAdminReset   = "admin_reset"
+	CoinTxDaily        = "daily"
+	CoinTxWeekly       = "weekly"
+	CoinTxShopPurchase = "shop_purchase"
+	CoinTxShopRefund   = "shop_refund"
+)
+
+// ErrInsufficientFunds is returned when a member doesn't have enough coins in
+// hand for a transfer.
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+// UpdateCoins runs update and writes entries to the coin ledger in a single
+// database transaction, so a balance never changes without a matching
+// history row. Entries without a CreatedAt are stamped with the current time.
+// The members the entries are for are created first, since updating a member
+// who has never chatted would change nothing but still be recorded.
+func (b *MartinGarrixBot) UpdateCoins(ctx context.Context, update func(q *db.Queries) error, entries ...db.CreateCoinTransactionParams) error {
+	tx, err := b.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	q := b.Queries.WithTx(tx)
+	for _, entry := range entries {
+		if err := q.EnsureUser(ctx, db.EnsureUserParams{ID: entry.UserID, GuildID: entry.GuildID}); err != nil {
+			return err
+		}
+	}
+
+	if err := update(q); err != nil {
+		return err
+	}
+
+	if err := RecordCoinTransactions(ctx, q, entries...); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RecordCoinTransactions writes entries to the coin ledger. q should be bound
+// to the same transaction as the balance change the entries describe.
+func RecordCoinTransactions(ctx context.Context, q *db.Queries, entries ...db.CreateCoinTransactionParams) error {
+	now := time.Now().UTC()
+	for _, entry := range entries {
+		if !entry.CreatedAt.Valid {
+			entry.CreatedAt = pgtype.Timestamp{Time: now, Valid: true}
+		}
+		if err := q.CreateCoinTransaction(ctx, entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TransferCoins moves amount from sender's hand to receiver's hand and records
+// both sides in the ledger. The receiver is created if they have never chatted.
+// It returns ErrInsufficientFunds if the sender doesn't have amount in hand.
+func (b *MartinGarrixBot) TransferCoins(ctx context.Context, guildID, senderID, receiverID, amount int64) error {
+	tx, err := b.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	q := b.Queries.WithTx(tx)
+
+	if err := q.EnsureUser(ctx, db.EnsureUserParams{ID: receiverID, GuildID: guildID}); err != nil {
+		return err
+	}
+
+	inHand, err := LockBalances(ctx, q, guildID, senderID, receiverID)
+	if err != nil {
+		return err
+	}
+	if inHand[senderID] < amount {
+		return ErrInsufficientFunds
+	}
+
+	if err := q.AddCoins(ctx, db.AddCoinsParams{
+		ID:      senderID,
+		GuildID: guildID,
+		InHand:  pgtype.Int8{Int64: -amount, Valid: true},
+	}); err != nil {
+		return err
+	}
+	if err := q.AddCoins(ctx, db.AddCoinsParams{
+		ID:      receiverID,
+		GuildID: guildID,
+		InHand:  pgtype.Int8{Int64: amount, Valid: true},
+	}); err != nil {
+		return err
+	}
+
+	err = RecordCoinTransactions(ctx, q, db.CreateCoinTransactionParams{
+		GuildID:        guildID,
+		UserID:         senderID,
+		Kind:           CoinTxGive,
+		InHandChange:   -amount,
+		CounterpartyID: pgtype.Int8{Int64: receiverID, Valid: true},
+	}, db.CreateCoinTransactionParams{
+		GuildID:        guildID,
+		UserID:         receiverID,
+		Kind:           CoinTxGive,
+		InHandChange:   amount,
+		CounterpartyID: pgtype.Int8{Int64: senderID, Valid: true},
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// LockBalances locks the balance rows of userIDs for the rest of q's
+// transaction and returns their coins in hand. Rows are locked in ID order so
+// two transfers between the same members can't deadlock. Members without a
+// row have nothing in hand.
+func LockBalances(ctx context.Context, q *db.Queries, guildID int64, userIDs ...int64) (map[int64]int64, error) {
+	ids := slices.Clone(userIDs)
+	slices.Sort(ids)
+
+	inHand := make(map[int64]int64, len(ids))
+	for _, id := range slices.Compact(ids) {
+		balance, err := q.GetBalanceForUpdate(ctx, db.GetBalanceForUpdateParams{
+			ID:      id,
+			GuildID: guildID,
+		})
+		if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+			return nil, err
+		}
+		inHand[id] = balance.InHand.Int64
+	}
+	return inHand, nil
+}
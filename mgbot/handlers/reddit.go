@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html"
 	"io"
@@ -66,101 +67,96 @@ func AuthenticateReddit(b *mgbot.MartinGarrixBot) error {
 
 }
 
-func GetRedditPosts(b *mgbot.MartinGarrixBot, ticker *time.Ticker) {
-
+// GetRedditPosts fetches the newest posts from r/Martingarrix and announces any
+// that haven't been seen before. It performs a single run; scheduling is left
+// to the bot's Scheduler.
+func GetRedditPosts(ctx context.Context, b *mgbot.MartinGarrixBot) (int, error) {
 	if b.RedditToken.AccessToken == "" || b.RedditToken.ExpiresAt.Before(time.Now()) {
 		slog.Info("Reddit token expired or not set, authenticating...")
 		if err := AuthenticateReddit(b); err != nil {
-			slog.Error("Failed to authenticate reddit", slog.Any("err", err))
-			return
+			return 0, fmt.Errorf("failed to authenticate reddit: %w", err)
 		}
 	}
 
 	if b.RedditToken.AccessToken == "" {
-		slog.Error("Reddit access token is empty after authentication")
-		return
+		return 0, errors.New("reddit access token is empty after authentication")
 	}
 
 	endpoint := fmt.Sprintf("/r/Martingarrix/new?limit=%d", 5)
 
-	for ; ; <-ticker.C {
-		slog.Info("Running reddit post fetcher")
+	slog.Info("Running reddit post fetcher")
 
-		// Create a batch notifier for this cycle
-		notifier := utils.NewBatchNotifier(b.Queries, b.Client.Rest(), utils.NotificationTypeReddit)
+	// Create a batch notifier for this cycle
+	notifier := utils.NewBatchNotifier(b.Queries, b.Client.Rest(), utils.NotificationTypeReddit)
 
-		req, err := http.NewRequest("GET", "https://oauth.reddit.com"+endpoint, nil)
-		req.Header.Set("User-Agent", "MartinGarrixBot")
-		// Access token
-		req.Header.Set("Authorization", "bearer "+b.RedditToken.AccessToken)
-		if err != nil {
-			slog.Error("Failed to create reddit request", slog.Any("err", err))
-			continue
-		}
-		req.Header.Set("User-Agent", "MartinGarrixBot")
-		resp, err := http.DefaultClient.Do(req)
-		if err != nil {
-			slog.Error("Failed to fetch reddit posts", slog.Any("err", err))
-			continue
-		}
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://oauth.reddit.com"+endpoint, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create reddit request: %w", err)
+	}
+	req.Header.Set("User-Agent", "MartinGarrixBot")
+	// Access token
+	req.Header.Set("Authorization", "bearer "+b.RedditToken.AccessToken)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch reddit posts: %w", err)
+	}
+	defer resp.Body.Close()
 
-		defer resp.Body.Close()
+	// Read the body into a byte slice for potential debugging
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read response body: %w", err)
+	}
 
-		// Read the body into a byte slice for potential debugging
-		bodyBytes, err := io.ReadAll(resp.Body)
-		if err != nil {
-			slog.Error("Failed to read response body", slog.Any("err", err))
-			continue
-		}
+	var data utils.RedditResponse
+	if err = json.Unmarshal(bodyBytes, &data); err != nil {
+		// Log the response body for debugging
+		slog.Error("Failed to decode reddit response",
+			slog.Any("err", err),
+			slog.String("response_body", string(bodyBytes)),
+			slog.Int("status_code", resp.StatusCode))
+		return 0, fmt.Errorf("failed to decode reddit response: %w", err)
+	}
 
-		var data utils.RedditResponse
-		if err = json.Unmarshal(bodyBytes, &data); err != nil {
-			// Log the response body for debugging
-			slog.Error("Failed to decode reddit response",
-				slog.Any("err", err),
-				slog.String("response_body", string(bodyBytes)),
-				slog.Int("status_code", resp.StatusCode))
-			continue
-		}
+	posts := data.Data.Children
+	if len(posts) > 5 {
+		posts = posts[:5]
+	}
+	slices.Reverse(posts)
 
-		posts := data.Data.Children
-		if len(posts) > 5 {
-			posts = posts[:5]
+	for _, post := range posts {
+		err := b.Queries.InsertRedditPost(ctx, post.Data.ID)
+		if err != nil {
+			// Post already exists, skip it
+			continue
 		}
-		slices.Reverse(posts)
 
-		for _, post := range posts {
-			err := b.Queries.InsertRedditPost(context.Background(), post.Data.ID)
-			if err != nil {
-				// Post already exists, skip it
-				continue
+		redditPostEmbed := discord.NewEmbedBuilder().
+			SetTitle(html.UnescapeString(utils.CutString(post.Data.Title, 256))).
+			SetURL("https://www.reddit.com"+post.Data.Permalink).
+			SetTimestamp(time.Unix(int64(post.Data.CreatedUtc), 0)).
+			SetDescription(utils.CutString(html.UnescapeString(post.Data.Selftext), 2048)).
+			SetFooter(fmt.Sprintf("Author u/%s on Subreddit %s", post.Data.Author, post.Data.SubredditNamePrefixed), "").
+			// TODO: Change to reddit orange
+			SetColor(utils.ColorSuccess)
+
+		if imageRegex.MatchString(post.Data.URL) {
+			redditPostEmbed.Image = &discord.EmbedResource{
+				URL: post.Data.URL,
 			}
-
-			redditPostEmbed := discord.NewEmbedBuilder().
-				SetTitle(html.UnescapeString(utils.CutString(post.Data.Title, 256))).
-				SetURL("https://www.reddit.com"+post.Data.Permalink).
-				SetTimestamp(time.Unix(int64(post.Data.CreatedUtc), 0)).
-				SetDescription(utils.CutString(html.UnescapeString(post.Data.Selftext), 2048)).
-				SetFooter(fmt.Sprintf("Author u/%s on Subreddit %s", post.Data.Author, post.Data.SubredditNamePrefixed), "").
-				// TODO: Change to reddit orange
-				SetColor(utils.ColorSuccess)
-
-			if imageRegex.MatchString(post.Data.URL) {
-				redditPostEmbed.Image = &discord.EmbedResource{
-					URL: post.Data.URL,
-				}
-			}
-
-			// Add this post to the batch
-			embed := redditPostEmbed.Build()
-			notifier.AddItem(utils.NotificationItem{
-				Embed: &embed,
-			})
 		}
 
-		// Send all batched notifications once
-		if err := notifier.Send(); err != nil {
-			slog.Error("Failed to send batched reddit notifications", slog.Any("err", err))
-		}
+		// Add this post to the batch
+		embed := redditPostEmbed.Build()
+		notifier.AddItem(utils.NotificationItem{
+			Embed: &embed,
+		})
 	}
-} // TODO: Maybe some logic to restart if it returns / fails? / panics
\ No newline at end of file
+
+	// Send all batched notifications once
+	if err := notifier.Send(); err != nil {
+		return 0, fmt.Errorf("failed to send batched reddit notifications: %w", err)
+	}
+
+	return len(notifier.Items), nil
+}
@@ -10,7 +10,6 @@ import (
 	"slices"
 	"strconv"
 	"strings"
-	"time"
 
 	"github.com/disgoorg/disgo/discord"
 	"github.com/gocolly/colly/v2"
@@ -31,220 +30,227 @@ import (
 
 // TODO: All sets kb, when asked AI can query and send link in chat?
 
-func GetAllStmpdReleases(b *mgbot.MartinGarrixBot, ticker *time.Ticker) {
-	for ; ; <-ticker.C {
-		slog.Info("Running STMPD RCRDS releases fetcher")
+// GetAllStmpdReleases scrapes the STMPD RCRDS archive and announces releases
+// that aren't in the songs table yet. It performs a single run; scheduling is
+// left to the bot's Scheduler.
+func GetAllStmpdReleases(ctx context.Context, b *mgbot.MartinGarrixBot) (int, error) {
+	slog.Info("Running STMPD RCRDS releases fetcher")
 
-		err := b.Collector.Visit("https://stmpdrcrds.com/archive")
-		if err != nil {
-			slog.Error("Failed to visit stmpdrcrds.com", slog.Any("err", err))
-			continue
-		}
+	// Clone per run so callbacks don't pile up on the shared collector every
+	// time the job is scheduled. Callbacks are registered before Visit since
+	// the collector is async.
+	collector := b.Collector.Clone()
 
-		var releases []utils.StmpdRelease
+	var releases []utils.StmpdRelease
 
-		b.Collector.OnHTML(".releases", func(e *colly.HTMLElement) {
-			e.ForEach(".grid__cell", func(_ int, cell *colly.HTMLElement) {
-				var release utils.StmpdRelease
+	collector.OnHTML(".releases", func(e *colly.HTMLElement) {
+		e.ForEach(".grid__cell", func(_ int, cell *colly.HTMLElement) {
+			var release utils.StmpdRelease
 
-				releaseInfoDate, err := strconv.Atoi(cell.ChildText(".release__info__date"))
-				if err == nil {
-					release.ReleaseYear = releaseInfoDate
-				}
+			releaseInfoDate, err := strconv.Atoi(cell.ChildText(".release__info__date"))
+			if err == nil {
+				release.ReleaseYear = releaseInfoDate
+			}
 
-				release.Thumbnail = cell.ChildAttr(".release__figure img", "src")
-				parsedURL, err := url.Parse(release.Thumbnail)
-				if err != nil {
-					panic(err)
-				}
-				urlPath := parsedURL.Path
-				dir, file := path.Split(urlPath)
-				newFile := strings.Replace(file, "small", "big", 1)
-				parsedURL.Path = dir + newFile
-
-				release.Thumbnail = parsedURL.String()
-
-				h3 := cell.DOM.Find(".release__info__h3")
-				if h3.Length() > 0 {
-					htmlContent, _ := h3.Html()
-					parts := strings.Split(htmlContent, "<br/>")
-					if len(parts) >= 2 {
-						release.Artists = html.UnescapeString(strings.TrimSpace(parts[0]))
-						release.Name = html.UnescapeString(strings.TrimSpace(parts[1]))
-					}
+			release.Thumbnail = cell.ChildAttr(".release__figure img", "src")
+			parsedURL, err := url.Parse(release.Thumbnail)
+			if err != nil {
+				// Colly runs callbacks on its own goroutines, where a panic
+				// can't be recovered by the scheduler, so skip the cell instead.
+				slog.Warn("Failed to parse STMPD thumbnail url", slog.String("url", release.Thumbnail), slog.Any("err", err))
+				return
+			}
+			urlPath := parsedURL.Path
+			dir, file := path.Split(urlPath)
+			newFile := strings.Replace(file, "small", "big", 1)
+			parsedURL.Path = dir + newFile
+
+			release.Thumbnail = parsedURL.String()
+
+			h3 := cell.DOM.Find(".release__info__h3")
+			if h3.Length() > 0 {
+				htmlContent, _ := h3.Html()
+				parts := strings.Split(htmlContent, "<br/>")
+				if len(parts) >= 2 {
+					release.Artists = html.UnescapeString(strings.TrimSpace(parts[0]))
+					release.Name = html.UnescapeString(strings.TrimSpace(parts[1]))
 				}
+			}
 
-				cell.ForEach(".links__links__a", func(_ int, link *colly.HTMLElement) {
-					href := link.Attr("href")
-					if strings.Contains(href, "spotify") {
-						release.SpotifyURL = href
-					} else if strings.Contains(href, "apple") {
-						release.AppleMusicUrl = href
-					} else if strings.Contains(href, "youtube") || strings.Contains(href, "youtu.be") {
-						release.YoutubeURL = href
-					}
-				})
-
-				releases = append(releases, release)
+			cell.ForEach(".links__links__a", func(_ int, link *colly.HTMLElement) {
+				href := link.Attr("href")
+				if strings.Contains(href, "spotify") {
+					release.SpotifyURL = href
+				} else if strings.Contains(href, "apple") {
+					release.AppleMusicUrl = href
+				} else if strings.Contains(href, "youtube") || strings.Contains(href, "youtu.be") {
+					release.YoutubeURL = href
+				}
 			})
+
+			releases = append(releases, release)
 		})
+	})
 
-		b.Collector.Wait()
+	if err := collector.Visit("https://stmpdrcrds.com/archive"); err != nil {
+		return 0, fmt.Errorf("failed to visit stmpdrcrds.com: %w", err)
+	}
+	collector.Wait()
 
-		slices.Reverse(releases)
-		if len(releases) > 5 {
-			releases = releases[len(releases)-5:]
-		}
+	slices.Reverse(releases)
+	if len(releases) > 5 {
+		releases = releases[len(releases)-5:]
+	}
+
+	// Load existing songs for similarity matching
+	existingSongs, err := b.Queries.GetAllSongsForMatching(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load existing songs for STMPD matching: %w", err)
+	}
+
+	// Create a batch notifier for this cycle
+	notifier := utils.NewBatchNotifier(b.Queries, b.Client.Rest(), utils.NotificationTypeSTMPD)
+
+	for _, release := range releases {
+		// Convert release year to release_date format
+		releaseDate := fmt.Sprintf("%d-01-01", release.ReleaseYear)
+
+		// First check exact match in DB
+		doesExist, err := b.Queries.DoesSongExist(ctx, db.DoesSongExistParams{
+			Name:        release.Name,
+			Artists:     release.Artists,
+			ReleaseDate: releaseDate,
+		})
 
-		// Load existing songs for similarity matching
-		existingSongs, err := b.Queries.GetAllSongsForMatching(context.Background())
 		if err != nil {
-			slog.Error("Failed to load existing songs for STMPD matching", slog.Any("err", err))
+			slog.Error("Failed to check if song exists", slog.Any("err", err))
 			continue
 		}
 
-		// Create a batch notifier for this cycle
-		notifier := utils.NewBatchNotifier(b.Queries, b.Client.Rest(), utils.NotificationTypeSTMPD)
+		if doesExist {
+			continue
+		}
 
-		for _, release := range releases {
-			// Convert release year to release_date format
-			releaseDate := fmt.Sprintf("%d-01-01", release.ReleaseYear)
+		// Check similarity with existing songs (especially beatport songs)
+		matchedSong := findSimilarExistingSong(existingSongs, release.Name, release.Artists)
 
-			// First check exact match in DB
-			doesExist, err := b.Queries.DoesSongExist(context.Background(), db.DoesSongExistParams{
-				Name:        release.Name,
-				Artists:     release.Artists,
-				ReleaseDate: releaseDate,
-			})
-
-			if err != nil {
-				slog.Error("Failed to check if song exists", slog.Any("err", err))
+		if matchedSong != nil && matchedSong.BeatportID.Valid {
+			// Check if already updated — avoid re-updating every run
+			fullSong, lookupErr := b.Queries.GetSongByID(ctx, matchedSong.ID)
+			if lookupErr == nil && fullSong.BeatportUpdated {
 				continue
 			}
 
-			if doesExist {
-				continue
-			}
-
-			// Check similarity with existing songs (especially beatport songs)
-			matchedSong := findSimilarExistingSong(existingSongs, release.Name, release.Artists)
-
-			if matchedSong != nil && matchedSong.BeatportID.Valid {
-				// Check if already updated — avoid re-updating every run
-				fullSong, lookupErr := b.Queries.GetSongByID(context.Background(), matchedSong.ID)
-				if lookupErr == nil && fullSong.BeatportUpdated {
-					continue
-				}
+			// A similar beatport song exists — update it with STMPD links silently
+			err = b.Queries.UpdateSongWithStmpdLinks(ctx, db.UpdateSongWithStmpdLinksParams{
+				ID: matchedSong.ID,
+				SpotifyUrl: pgtype.Text{
+					String: release.SpotifyURL,
+					Valid:  release.SpotifyURL != "",
+				},
+				AppleMusicUrl: pgtype.Text{
+					String: release.AppleMusicUrl,
+					Valid:  release.AppleMusicUrl != "",
+				},
+				YoutubeUrl: pgtype.Text{
+					String: release.YoutubeURL,
+					Valid:  release.YoutubeURL != "",
+				},
+				ThumbnailUrl: pgtype.Text{
+					String: release.Thumbnail,
+					Valid:  release.Thumbnail != "",
+				},
+			})
 
-				// A similar beatport song exists — update it with STMPD links silently
-				err = b.Queries.UpdateSongWithStmpdLinks(context.Background(), db.UpdateSongWithStmpdLinksParams{
-					ID: matchedSong.ID,
-					SpotifyUrl: pgtype.Text{
-						String: release.SpotifyURL,
-						Valid:  release.SpotifyURL != "",
-					},
-					AppleMusicUrl: pgtype.Text{
-						String: release.AppleMusicUrl,
-						Valid:  release.AppleMusicUrl != "",
-					},
-					YoutubeUrl: pgtype.Text{
-						String: release.YoutubeURL,
-						Valid:  release.YoutubeURL != "",
-					},
-					ThumbnailUrl: pgtype.Text{
-						String: release.Thumbnail,
-						Valid:  release.Thumbnail != "",
-					},
-				})
-
-				if err != nil {
-					slog.Error("Failed to update song with STMPD links",
-						slog.String("name", release.Name), slog.Any("err", err))
-				} else {
-					slog.Debug("Updated beatport song with STMPD links",
-						slog.String("name", release.Name),
-						slog.String("artists", release.Artists),
-						slog.Int64("song_id", matchedSong.ID))
-				}
-				continue
+			if err != nil {
+				slog.Error("Failed to update song with STMPD links",
+					slog.String("name", release.Name), slog.Any("err", err))
+			} else {
+				slog.Debug("Updated beatport song with STMPD links",
+					slog.String("name", release.Name),
+					slog.String("artists", release.Artists),
+					slog.Int64("song_id", matchedSong.ID))
 			}
+			continue
+		}
 
-			// No similar song exists — insert new STMPD song
-			releaseParams := db.InsertReleaseParams{
-				Name:        release.Name,
-				Artists:     release.Artists,
-				ReleaseDate: releaseDate,
-			}
+		// No similar song exists — insert new STMPD song
+		releaseParams := db.InsertReleaseParams{
+			Name:        release.Name,
+			Artists:     release.Artists,
+			ReleaseDate: releaseDate,
+		}
 
-			if release.SpotifyURL != "" {
-				releaseParams.SpotifyUrl = pgtype.Text{
-					String: release.SpotifyURL,
-					Valid:  true,
-				}
+		if release.SpotifyURL != "" {
+			releaseParams.SpotifyUrl = pgtype.Text{
+				String: release.SpotifyURL,
+				Valid:  true,
 			}
+		}
 
-			if release.AppleMusicUrl != "" {
-				releaseParams.AppleMusicUrl = pgtype.Text{
-					String: release.AppleMusicUrl,
-					Valid:  true,
-				}
+		if release.AppleMusicUrl != "" {
+			releaseParams.AppleMusicUrl = pgtype.Text{
+				String: release.AppleMusicUrl,
+				Valid:  true,
 			}
+		}
 
-			if release.YoutubeURL != "" {
-				releaseParams.YoutubeUrl = pgtype.Text{
-					String: release.YoutubeURL,
-					Valid:  true,
-				}
+		if release.YoutubeURL != "" {
+			releaseParams.YoutubeUrl = pgtype.Text{
+				String: release.YoutubeURL,
+				Valid:  true,
 			}
+		}
 
-			if release.Thumbnail != "" {
-				releaseParams.ThumbnailUrl = pgtype.Text{
-					String: release.Thumbnail,
-					Valid:  true,
-				}
+		if release.Thumbnail != "" {
+			releaseParams.ThumbnailUrl = pgtype.Text{
+				String: release.Thumbnail,
+				Valid:  true,
 			}
+		}
 
-			song, err := b.Queries.InsertRelease(
-				context.Background(), releaseParams,
-			)
+		song, err := b.Queries.InsertRelease(
+			ctx, releaseParams,
+		)
 
-			if err != nil {
-				slog.Error("Failed to insert release for "+release.Name, slog.Any("err", err))
-				continue
-			}
+		if err != nil {
+			slog.Error("Failed to insert release for "+release.Name, slog.Any("err", err))
+			continue
+		}
 
-			// Add to existing songs list
-			existingSongs = append(existingSongs, db.GetAllSongsForMatchingRow{
-				ID:      song.ID,
-				Name:    song.Name,
-				Artists: song.Artists,
-				Source:  "stmpd",
-			})
+		// Add to existing songs list
+		existingSongs = append(existingSongs, db.GetAllSongsForMatchingRow{
+			ID:      song.ID,
+			Name:    song.Name,
+			Artists: song.Artists,
+			Source:  "stmpd",
+		})
 
-			announcementEmbed := discord.NewEmbedBuilder().
-				SetTitle(fmt.Sprintf("%s - %s", release.Artists, release.Name)).
-				SetImage(release.Thumbnail).
-				SetFooter(fmt.Sprintf("Release Year: %d", release.ReleaseYear), "").
-				Build()
-
-			// Prepare the components for this song
-			var components []discord.ContainerComponent
-			if song.SpotifyUrl.Valid || song.YoutubeUrl.Valid || song.AppleMusicUrl.Valid {
-				components = []discord.ContainerComponent{
-					discord.NewActionRow(utils.GetSongButtons(song)...),
-				}
+		announcementEmbed := discord.NewEmbedBuilder().
+			SetTitle(fmt.Sprintf("%s - %s", release.Artists, release.Name)).
+			SetImage(release.Thumbnail).
+			SetFooter(fmt.Sprintf("Release Year: %d", release.ReleaseYear), "").
+			Build()
+
+		// Prepare the components for this song
+		var components []discord.ContainerComponent
+		if song.SpotifyUrl.Valid || song.YoutubeUrl.Valid || song.AppleMusicUrl.Valid {
+			components = []discord.ContainerComponent{
+				discord.NewActionRow(utils.GetSongButtons(song)...),
 			}
-
-			// Add this release to the batch
-			notifier.AddItem(utils.NotificationItem{
-				Embed:      &announcementEmbed,
-				Components: components,
-			})
 		}
 
-		// Send all batched notifications once
-		if err := notifier.Send(); err != nil {
-			slog.Error("Failed to send batched STMPD notifications", slog.Any("err", err))
-		}
+		// Add this release to the batch
+		notifier.AddItem(utils.NotificationItem{
+			Embed:      &announcementEmbed,
+			Components: components,
+		})
+	}
+
+	// Send all batched notifications once
+	if err := notifier.Send(); err != nil {
+		return 0, fmt.Errorf("failed to send batched STMPD notifications: %w", err)
 	}
+
+	return len(notifier.Items), nil
 }
@@ -5,59 +5,69 @@ import (
 	"fmt"
 	"log/slog"
 	"slices"
-	"time"
 
 	"github.com/milindmadhukar/MartinGarrixBot/mgbot"
 	"github.com/milindmadhukar/MartinGarrixBot/utils"
 )
 
-func GetYoutubeVideos(b *mgbot.MartinGarrixBot, ticker *time.Ticker) {
+// GetYoutubeVideos checks the tracked playlists for new uploads and announces
+// any that haven't been seen before. It performs a single run; scheduling is
+// left to the bot's Scheduler.
+func GetYoutubeVideos(ctx context.Context, b *mgbot.MartinGarrixBot) (int, error) {
 	playlistIDs := []string{
 		"UU5H_KXkPbEsGs0tFt8R35mA",           // Martin Garrix uploads
 		"PLwPIORXMGwchuy4DTiIAasWRezahNrbUJ", // Martin Garrix custom playlist
 		"UUB-7IEpKGIdXkgGUObE5D5A",           // STMPD RCRDS uploads
 	}
 
-	for ; ; <-ticker.C {
-		slog.Info("Running youtube video fetcher")
+	slog.Info("Running youtube video fetcher")
 
-		// Create a batch notifier for this cycle
-		notifier := utils.NewBatchNotifier(b.Queries, b.Client.Rest(), utils.NotificationTypeYoutube)
+	// Create a batch notifier for this cycle
+	notifier := utils.NewBatchNotifier(b.Queries, b.Client.Rest(), utils.NotificationTypeYoutube)
 
-		for _, playlistID := range playlistIDs {
-			resp, err := b.YoutubeService.PlaylistItems.
-				List([]string{"snippet"}).
-				PlaylistId(playlistID).
-				MaxResults(5).Do()
+	var failed int
+	for _, playlistID := range playlistIDs {
+		resp, err := b.YoutubeService.PlaylistItems.
+			List([]string{"snippet"}).
+			PlaylistId(playlistID).
+			MaxResults(5).
+			Context(ctx).
+			Do()
 
-			if err != nil {
-				slog.Error("Failed to fetch youtube videos", slog.Any("err", err))
-				continue
-			}
-
-			slices.Reverse(resp.Items)
+		if err != nil {
+			slog.Error("Failed to fetch youtube videos", slog.String("playlist_id", playlistID), slog.Any("err", err))
+			failed++
+			continue
+		}
 
-			for _, item := range resp.Items {
-				videoId := item.Snippet.ResourceId.VideoId
-				channelTitle := item.Snippet.ChannelTitle
+		slices.Reverse(resp.Items)
 
-				err := b.Queries.InsertYoutubeVideo(context.Background(), videoId)
-				if err != nil {
-					// Video already exists, skip it
-					continue
-				}
+		for _, item := range resp.Items {
+			videoId := item.Snippet.ResourceId.VideoId
+			channelTitle := item.Snippet.ChannelTitle
 
-				// Add this video to the batch
-				content := fmt.Sprintf("%s just posted a new video. Go check it out!\nhttps://www.youtube.com/watch?v=%s", channelTitle, videoId)
-				notifier.AddItem(utils.NotificationItem{
-					Content: content,
-				})
+			err := b.Queries.InsertYoutubeVideo(ctx, videoId)
+			if err != nil {
+				// Video already exists, skip it
+				continue
 			}
-		}
 
-		// Send all batched notifications once
-		if err := notifier.Send(); err != nil {
-			slog.Error("Failed to send batched youtube notifications", slog.Any("err", err))
+			// Add this video to the batch
+			content := fmt.Sprintf("%s just posted a new video. Go check it out!\nhttps://www.youtube.com/watch?v=%s", channelTitle, videoId)
+			notifier.AddItem(utils.NotificationItem{
+				Content: content,
+			})
 		}
 	}
+
+	// Send all batched notifications once
+	if err := notifier.Send(); err != nil {
+		return 0, fmt.Errorf("failed to send batched youtube notifications: %w", err)
+	}
+
+	if failed == len(playlistIDs) {
+		return 0, fmt.Errorf("failed to fetch all %d youtube playlists", failed)
+	}
+
+	return len(notifier.Items), nil
 }
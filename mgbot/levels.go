@@ -0,0 +1,130 @@
+package mgbot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+// DefaultLevelUpMessage is announced when a guild hasn't set its own message.
+const DefaultLevelUpMessage = "GG {user}, you just reached level **{level}**!"
+
+// HandleLevelUp grants the level roles a member has earned by reaching level
+// and announces it in the guild's bot channel.
+func (b *MartinGarrixBot) HandleLevelUp(ctx context.Context, guildID, userID snowflake.ID, level int) {
+	cfg, err := b.Queries.GetLevelUpConfig(ctx, int64(guildID))
+	if err != nil {
+		slog.Error("Failed to get level up config", slog.Any("err", err))
+		return
+	}
+
+	granted, err := b.syncLevelRoles(ctx, guildID, userID, level, cfg.StackLevelRoles)
+	if err != nil {
+		slog.Error("Failed to update level roles", slog.Any("err", err))
+	}
+
+	if !cfg.LevelUpAnnouncements || !cfg.BotChannel.Valid {
+		return
+	}
+
+	message := DefaultLevelUpMessage
+	if cfg.LevelUpMessage.Valid && cfg.LevelUpMessage.String != "" {
+		message = cfg.LevelUpMessage.String
+	}
+	content := strings.NewReplacer(
+		"{user}", discord.UserMention(userID),
+		"{level}", strconv.Itoa(level),
+	).Replace(message)
+
+	if len(granted) > 0 {
+		mentions := make([]string, len(granted))
+		for i, roleID := range granted {
+			mentions[i] = discord.RoleMention(roleID)
+		}
+		content += fmt.Sprintf("\nYou earned %s.", strings.Join(mentions, ", "))
+	}
+
+	_, err = b.Client.Rest().CreateMessage(snowflake.ID(cfg.BotChannel.Int64), discord.NewMessageCreateBuilder().
+		SetContent(content).
+		SetAllowedMentions(&discord.AllowedMentions{Users: []snowflake.ID{userID}}).
+		Build(),
+	)
+	if err != nil {
+		slog.Error("Failed to send level up message", slog.Any("err", err))
+	}
+}
+
+// SyncLevelRoles gives a member the level roles they have earned at level and
+// takes away the ones above it, without announcing anything, for levels
+// changed by hand.
+func (b *MartinGarrixBot) SyncLevelRoles(ctx context.Context, guildID, userID snowflake.ID, level int) error {
+	cfg, err := b.Queries.GetLevelUpConfig(ctx, int64(guildID))
+	if err != nil {
+		return err
+	}
+
+	_, err = b.syncLevelRoles(ctx, guildID, userID, level, cfg.StackLevelRoles)
+	return err
+}
+
+// syncLevelRoles gives a member the roles of every level they have reached, or
+// only the highest one when stack is false, and returns the roles it added.
+// Any other level roles the member has are removed, so dropping a level takes
+// its roles away.
+func (b *MartinGarrixBot) syncLevelRoles(ctx context.Context, guildID, userID snowflake.ID, level int, stack bool) ([]snowflake.ID, error) {
+	levelRoles, err := b.Queries.GetLevelRoles(ctx, int64(guildID))
+	if err != nil || len(levelRoles) == 0 {
+		return nil, err
+	}
+
+	// Level roles are ordered by level, so the last earned one is the highest
+	var earned []snowflake.ID
+	for _, levelRole := range levelRoles {
+		if int(levelRole.Level) <= level {
+			earned = append(earned, snowflake.ID(levelRole.RoleID))
+		}
+	}
+
+	keep := earned
+	if !stack && len(earned) > 0 {
+		keep = earned[len(earned)-1:]
+	}
+
+	member, err := utils.GetMember(b.Client, guildID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := rest.WithReason(fmt.Sprintf("Now level %d", level))
+
+	var granted []snowflake.ID
+	for _, roleID := range keep {
+		if slices.Contains(member.RoleIDs, roleID) || slices.Contains(granted, roleID) {
+			continue
+		}
+		if err := b.Client.Rest().AddMemberRole(guildID, userID, roleID, reason); err != nil {
+			return granted, err
+		}
+		granted = append(granted, roleID)
+	}
+
+	for _, levelRole := range levelRoles {
+		roleID := snowflake.ID(levelRole.RoleID)
+		if slices.Contains(keep, roleID) || !slices.Contains(member.RoleIDs, roleID) {
+			continue
+		}
+		if err := b.Client.Rest().RemoveMemberRole(guildID, userID, roleID, reason); err != nil {
+			return granted, err
+		}
+	}
+
+	return granted, nil
+}
@@ -0,0 +1,346 @@
+package mgbot
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+// How imported levels are combined with a member's existing levels.
+const (
+	ImportKeepMax   = "max"
+	ImportOverwrite = "overwrite"
+	ImportAdd       = "add"
+)
+
+// levelImportBatchSize is how many members are written in one statement.
+const levelImportBatchSize = 500
+
+// ErrInvalidImportStrategy is returned for a conflict strategy that isn't one
+// of ImportKeepMax, ImportOverwrite or ImportAdd.
+var ErrInvalidImportStrategy = errors.New("invalid import strategy")
+
+// LevelImportRecord is a member's progress read from another bot's export.
+type LevelImportRecord struct {
+	UserID   int64
+	Xp       int32
+	Messages int32
+}
+
+// LevelImportReport counts what an import did, or would do in a dry run.
+type LevelImportReport struct {
+	Total     int
+	Created   int
+	Updated   int
+	Unchanged int
+	// Levels maps each member whose level changed to their new level.
+	Levels map[int64]int
+}
+
+// levelImportJSON is a member in a JSON export. MEE6 uses id and
+// message_count, other bots and hand made files tend to use the rest. IDs may
+// be strings or numbers.
+type levelImportJSON struct {
+	ID           json.Number `json:"id"`
+	UserID       json.Number `json:"user_id"`
+	Xp           json.Number `json:"xp"`
+	TotalXp      json.Number `json:"total_xp"`
+	Messages     json.Number `json:"messages"`
+	MessageCount json.Number `json:"message_count"`
+}
+
+// ParseLevelImport reads an export as JSON if it starts with { or [, and as
+// CSV with a header row otherwise. JSON may be a list of members or a MEE6
+// leaderboard with the members under players.
+func ParseLevelImport(r io.Reader) ([]LevelImportRecord, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 {
+		return nil, errors.New("the export is empty")
+	}
+	if data[0] == '{' || data[0] == '[' {
+		return parseLevelImportJSON(data)
+	}
+	return parseLevelImportCSV(data)
+}
+
+func parseLevelImportJSON(data []byte) ([]LevelImportRecord, error) {
+	var members []levelImportJSON
+	if data[0] == '{' {
+		var leaderboard struct {
+			Players []levelImportJSON `json:"players"`
+		}
+		if err := json.Unmarshal(data, &leaderboard); err != nil {
+			return nil, err
+		}
+		members = leaderboard.Players
+	} else if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+
+	records := make([]LevelImportRecord, len(members))
+	for i, member := range members {
+		record, err := newLevelImportRecord(
+			firstNonEmpty(string(member.ID), string(member.UserID)),
+			firstNonEmpty(string(member.Xp), string(member.TotalXp)),
+			firstNonEmpty(string(member.Messages), string(member.MessageCount)),
+		)
+		if err != nil {
+			return nil, fmt.Errorf("member %d: %w", i+1, err)
+		}
+		records[i] = record
+	}
+	return records, nil
+}
+
+func parseLevelImportCSV(data []byte) ([]LevelImportRecord, error) {
+	rows, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	idColumn, xpColumn, messagesColumn := -1, -1, -1
+	for i, name := range rows[0] {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "id", "user_id", "userid":
+			idColumn = i
+		case "xp", "total_xp":
+			xpColumn = i
+		case "messages", "message_count", "messages_sent":
+			messagesColumn = i
+		}
+	}
+	if idColumn == -1 || xpColumn == -1 {
+		return nil, errors.New("the header row needs a user_id and an xp column")
+	}
+
+	records := make([]LevelImportRecord, 0, len(rows)-1)
+	for i, row := range rows[1:] {
+		messages := ""
+		if messagesColumn != -1 {
+			messages = row[messagesColumn]
+		}
+		record, err := newLevelImportRecord(row[idColumn], row[xpColumn], messages)
+		if err != nil {
+			// Line 1 is the header
+			return nil, fmt.Errorf("line %d: %w", i+2, err)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func newLevelImportRecord(userID, xp, messages string) (LevelImportRecord, error) {
+	id, err := strconv.ParseInt(strings.TrimSpace(userID), 10, 64)
+	if err != nil || id <= 0 {
+		return LevelImportRecord{}, fmt.Errorf("invalid user id %q", userID)
+	}
+
+	record := LevelImportRecord{UserID: id}
+	if record.Xp, err = parseImportCount(xp); err != nil {
+		return LevelImportRecord{}, fmt.Errorf("invalid xp %q", xp)
+	}
+	if record.Messages, err = parseImportCount(messages); err != nil {
+		return LevelImportRecord{}, fmt.Errorf("invalid message count %q", messages)
+	}
+	return record, nil
+}
+
+// parseImportCount parses a non-negative count, treating a missing one as 0.
+func parseImportCount(s string) (int32, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, errors.New("negative count")
+	}
+	return int32(n), nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// ImportLevels writes records into guildID's users, combining them with
+// existing progress according to strategy. Each batch is written with a single
+// statement and committed on its own, so members' rows are never locked for
+// longer than that. If the import fails partway, the batches before it stay
+// written and report counts them. A dry run only reads, taking no locks.
+// progress, if not nil, is called after every batch with the number of members
+// processed so far.
+//
+// Level roles aren't touched. Pass report.Levels to SyncImportedLevelRoles to
+// sync them, otherwise members get theirs on their next level up.
+func (b *MartinGarrixBot) ImportLevels(ctx context.Context, guildID int64, records []LevelImportRecord, strategy string, dryRun bool, progress func(done, total int)) (LevelImportReport, error) {
+	if strategy != ImportKeepMax && strategy != ImportOverwrite && strategy != ImportAdd {
+		return LevelImportReport{}, fmt.Errorf("%w: %q", ErrInvalidImportStrategy, strategy)
+	}
+
+	records = mergeLevelImportRecords(records, strategy)
+	report := LevelImportReport{Total: len(records), Levels: make(map[int64]int)}
+	for start := 0; start < len(records); start += levelImportBatchSize {
+		end := min(start+levelImportBatchSize, len(records))
+		if err := b.importLevelBatch(ctx, guildID, records[start:end], strategy, dryRun, &report); err != nil {
+			return report, err
+		}
+		if progress != nil {
+			progress(end, len(records))
+		}
+	}
+
+	return report, nil
+}
+
+// mergeLevelImportRecords combines members listed more than once according to
+// strategy, keeping the order they first appear in. A single upsert can't
+// write the same row twice.
+func mergeLevelImportRecords(records []LevelImportRecord, strategy string) []LevelImportRecord {
+	index := make(map[int64]int, len(records))
+	merged := make([]LevelImportRecord, 0, len(records))
+	for _, record := range records {
+		i, ok := index[record.UserID]
+		if !ok {
+			index[record.UserID] = len(merged)
+			merged = append(merged, record)
+			continue
+		}
+
+		switch strategy {
+		case ImportOverwrite:
+			merged[i] = record
+		case ImportKeepMax:
+			merged[i].Xp = max(merged[i].Xp, record.Xp)
+			merged[i].Messages = max(merged[i].Messages, record.Messages)
+		case ImportAdd:
+			merged[i].Xp = int32(min(int64(merged[i].Xp)+int64(record.Xp), math.MaxInt32))
+			merged[i].Messages = int32(min(int64(merged[i].Messages)+int64(record.Messages), math.MaxInt32))
+		}
+	}
+	return merged
+}
+
+// importLevelBatch writes records unless this is a dry run, and counts what
+// changed in report. The counts come from reading the members beforehand, so
+// XP earned in between isn't reflected in them.
+func (b *MartinGarrixBot) importLevelBatch(ctx context.Context, guildID int64, records []LevelImportRecord, strategy string, dryRun bool, report *LevelImportReport) error {
+	userIDs := make([]int64, len(records))
+	for i, record := range records {
+		userIDs[i] = record.UserID
+	}
+
+	rows, err := b.Queries.GetUserLevels(ctx, db.GetUserLevelsParams{GuildID: guildID, UserIds: userIDs})
+	if err != nil {
+		return err
+	}
+	existing := make(map[int64]db.GetUserLevelsRow, len(rows))
+	for _, row := range rows {
+		existing[row.ID] = row
+	}
+
+	if !dryRun {
+		totalXps := make([]int32, len(records))
+		messagesSent := make([]int32, len(records))
+		for i, record := range records {
+			totalXps[i], messagesSent[i] = record.Xp, record.Messages
+		}
+		if err := b.Queries.ImportUserLevels(ctx, db.ImportUserLevelsParams{
+			UserIds:      userIDs,
+			GuildID:      guildID,
+			TotalXps:     totalXps,
+			MessagesSent: messagesSent,
+			Strategy:     strategy,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for _, record := range records {
+		xp, messages := int64(record.Xp), int64(record.Messages)
+		oldLevel := 0
+
+		if user, ok := existing[record.UserID]; !ok {
+			report.Created++
+		} else {
+			oldXp, oldMessages := int64(user.TotalXp.Int32), int64(user.MessagesSent.Int32)
+			switch strategy {
+			case ImportKeepMax:
+				xp, messages = max(xp, oldXp), max(messages, oldMessages)
+			case ImportAdd:
+				xp, messages = min(xp+oldXp, math.MaxInt32), min(messages+oldMessages, math.MaxInt32)
+			}
+			if xp == oldXp && messages == oldMessages {
+				report.Unchanged++
+				continue
+			}
+			report.Updated++
+			oldLevel = utils.GetUserLevel(user.TotalXp.Int32)
+		}
+
+		if level := utils.GetUserLevel(int32(xp)); level != oldLevel {
+			report.Levels[record.UserID] = level
+		}
+	}
+
+	return nil
+}
+
+// SyncImportedLevelRoles syncs the level roles of imported members to the new
+// levels in LevelImportReport.Levels. Members who have left the guild are
+// skipped.
+func (b *MartinGarrixBot) SyncImportedLevelRoles(ctx context.Context, guildID snowflake.ID, levels map[int64]int) {
+	if len(levels) == 0 {
+		return
+	}
+
+	levelRoles, err := b.Queries.GetLevelRoles(ctx, int64(guildID))
+	if err != nil {
+		slog.Error("Failed to get level roles", slog.Any("err", err))
+		return
+	}
+	if len(levelRoles) == 0 {
+		return
+	}
+
+	for userID, level := range levels {
+		err := b.SyncLevelRoles(ctx, guildID, snowflake.ID(userID), level)
+		var restErr rest.Error
+		if errors.As(err, &restErr) && restErr.Response != nil && restErr.Response.StatusCode == http.StatusNotFound {
+			continue
+		}
+		if err != nil {
+			slog.Error("Failed to update level roles for imported member",
+				slog.Int64("guild_id", int64(guildID)),
+				slog.Int64("user_id", userID),
+				slog.Any("err", err),
+			)
+		}
+	}
+}
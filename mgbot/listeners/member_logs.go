@@ -32,6 +32,8 @@ func GuildMemberJoinListener(b *mgbot.MartinGarrixBot) bot.EventListener {
 			slog.Error("Failed to log member join", slog.Any("err", err))
 		}
 
+		b.CheckRaidJoin(context.Background(), e.GuildID, e.Member.User)
+
 		// Get guild configuration for log channel
 		config, err := b.Queries.GetLeaveJoinLogsChannel(context.Background(), int64(e.GuildID))
 		if err != nil {
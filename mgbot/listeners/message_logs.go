@@ -94,6 +94,12 @@ func MessageUpdateListener(b *mgbot.MartinGarrixBot) bot.EventListener {
 			return
 		}
 
+		// Edits are checked too so rules can't be dodged by editing a message
+		// after it was sent. The edit is still logged either way.
+		if e.OldMessage.ID == 0 || e.OldMessage.Content != e.Message.Content {
+			checkAutomod(b, e.GuildID, e.Message)
+		}
+
 		// Get guild configuration for log channel
 		config, err := b.Queries.GetEditLogsChannel(context.Background(), int64(e.GuildID))
 		if err != nil {
@@ -4,13 +4,16 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"math"
 	"math/rand/v2"
 	"strings"
 	"time"
 
 	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/events"
 	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
@@ -24,10 +27,7 @@ func MessageCreateListener(b *mgbot.MartinGarrixBot) bot.EventListener {
 			return
 		}
 
-		// TODO: Update message in bots channel for level change
-		// True garrixer role add if crosses level 13 // check from config
-		// Handler to prompt users to do slash commands if they are not using prefix commands
-		// Handle the XP multiplier?
+		// TODO: Handler to prompt users to do slash commands if they are not using prefix commands
 
 		if strings.HasPrefix(strings.ToLower(e.Message.Content), "mg.") {
 			replyMessageContent := "Prefix commands are deprecated. Please use slash commands instead. Type `/` to see available commands."
@@ -36,6 +36,11 @@ func MessageCreateListener(b *mgbot.MartinGarrixBot) bot.EventListener {
 			return
 		}
 
+		// Removed messages don't count towards XP or activity
+		if removed := checkAutomod(b, *e.GuildID, e.Message); removed {
+			return
+		}
+
 		user, err := b.Queries.GetUser(context.Background(), db.GetUserParams{
 			ID:      int64(e.Message.Author.ID),
 			GuildID: int64(*e.GuildID),
@@ -73,18 +78,72 @@ func MessageCreateListener(b *mgbot.MartinGarrixBot) bot.EventListener {
 			LastXpAdded: user.LastXpAdded,
 		}
 
+		// Every message is checked so repeats are caught during the cooldown too
+		spamReason := b.XpSpam.Check(*e.GuildID, e.Message.Author.ID, e.Message.Content, now)
+
 		if !user.LastXpAdded.Valid || now.Sub(user.LastXpAdded.Time.UTC()) >= time.Minute {
-			// Generate random number between 15 and 25
-			xp := 15 + rand.Int32N(11)
-			params.TotalXp.Int32 = user.TotalXp.Int32 + xp
-			params.TotalXp.Valid = true
-			params.LastXpAdded.Time = now
-			params.LastXpAdded.Valid = true
+			var roleIDs []snowflake.ID
+			if e.Message.Member != nil {
+				roleIDs = e.Message.Member.RoleIDs
+			}
+
+			multiplier, err := b.XpMultiplier(context.Background(), *e.GuildID, e.ChannelID, e.Message.Author.ID, roleIDs)
+			if err != nil {
+				slog.Error("Failed to get XP multiplier", slog.Any("err", err))
+				multiplier = 1
+			}
+
+			// No XP channels and roles don't start the cooldown either, and
+			// neither does spam so the member's next real message still counts
+			if multiplier > 0 && spamReason != "" {
+				if err := b.FlagXpSpam(context.Background(), *e.GuildID, e.ChannelID, e.MessageID, e.Message.Author.ID, spamReason, e.Message.Content, now); err != nil {
+					slog.Error("Failed to flag XP spam", slog.Any("err", err))
+				}
+			} else if multiplier > 0 {
+				// Generate random number between 15 and 25
+				xp := 15 + rand.Int32N(11)
+				params.TotalXp.Int32 = user.TotalXp.Int32 + int32(math.Round(float64(xp)*multiplier))
+				params.TotalXp.Valid = true
+				params.LastXpAdded.Time = now
+				params.LastXpAdded.Valid = true
+			}
 		}
 
 		err = b.Queries.MessageSent(context.Background(), params)
 		if err != nil {
 			slog.Error("Failed to log message", slog.Any("err", err))
+			return
+		}
+
+		if err := b.Queries.AddActivity(context.Background(), db.AddActivityParams{
+			UserID:   int64(e.Message.Author.ID),
+			GuildID:  int64(*e.GuildID),
+			Hour:     mgbot.ActivityHour(now),
+			Xp:       params.TotalXp.Int32 - user.TotalXp.Int32,
+			Messages: 1,
+		}); err != nil {
+			slog.Error("Failed to record activity", slog.Any("err", err))
+		}
+
+		if newLevel := utils.GetUserLevel(params.TotalXp.Int32); newLevel > utils.GetUserLevel(user.TotalXp.Int32) {
+			go b.HandleLevelUp(context.Background(), *e.GuildID, e.Message.Author.ID, newLevel)
 		}
 	})
 }
+
+// checkAutomod runs the automod on message and reports whether it was removed.
+func checkAutomod(b *mgbot.MartinGarrixBot, guildID snowflake.ID, message discord.Message) bool {
+	violation, err := b.CheckAutomod(context.Background(), guildID, message)
+	if err != nil {
+		slog.Error("Failed to check automod rules", slog.Any("err", err))
+		return false
+	}
+	if violation == nil {
+		return false
+	}
+
+	if err := b.ApplyAutomod(context.Background(), guildID, message, *violation); err != nil {
+		slog.Error("Failed to apply automod action", slog.Any("err", err))
+	}
+	return true
+}
@@ -10,7 +10,8 @@ import (
 	"github.com/milindmadhukar/MartinGarrixBot/utils"
 )
 
-// VoiceStateUpdateListener forwards voice state updates to Lavalink and handles radio resume
+// VoiceStateUpdateListener forwards voice state updates to Lavalink, tracks voice XP
+// sessions and handles radio resume
 func VoiceStateUpdateListener(b *mgbot.MartinGarrixBot) bot.EventListener {
 	return bot.NewListenerFunc(func(e *events.GuildVoiceStateUpdate) {
 		// Forward bot's own voice state updates to Lavalink
@@ -38,6 +39,10 @@ func VoiceStateUpdateListener(b *mgbot.MartinGarrixBot) bot.EventListener {
 			return
 		}
 
+		if err := b.UpdateVoiceSession(context.Background(), e.VoiceState); err != nil {
+			slog.Error("Failed to update voice session", slog.Any("err", err))
+		}
+
 		// Handle user join/leave for radio resume/pause logic
 		if b.RadioManager == nil {
 			return
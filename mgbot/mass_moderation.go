@@ -0,0 +1,263 @@
+package mgbot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+const (
+	// PurgeMaxMessages is the most messages one purge deletes.
+	PurgeMaxMessages = 500
+	// purgeMaxScan is the most messages a purge looks through for ones
+	// matching its filters.
+	purgeMaxScan = 2000
+	// bulkDeleteMaxAge is how old messages Discord bulk deletes can be, with
+	// some leeway for the time the purge takes.
+	bulkDeleteMaxAge = 14*24*time.Hour - 5*time.Minute
+	// bulkDeleteBatch is the most messages one bulk delete takes.
+	bulkDeleteBatch = 100
+
+	// MassBanMaxUsers is the most users one mass ban bans.
+	MassBanMaxUsers = 1000
+	// bulkBanBatch is the most users one bulk ban takes.
+	bulkBanBatch = 200
+)
+
+var userIDPattern = regexp.MustCompile(`\b\d{17,20}\b`)
+
+// PurgeFilter picks the messages a purge deletes. Zero fields don't filter.
+type PurgeFilter struct {
+	// Limit is the most messages deleted.
+	Limit           int
+	UserID          snowflake.ID
+	Contains        string
+	BotsOnly        bool
+	AttachmentsOnly bool
+	// Before and After bound the messages by ID, exclusive.
+	Before snowflake.ID
+	After  snowflake.ID
+}
+
+func (f PurgeFilter) matches(message discord.Message) bool {
+	switch {
+	case message.Pinned:
+		return false
+	case f.UserID != 0 && message.Author.ID != f.UserID:
+		return false
+	case f.BotsOnly && !message.Author.Bot:
+		return false
+	case f.AttachmentsOnly && len(message.Attachments) == 0:
+		return false
+	case f.Contains != "" && !strings.Contains(strings.ToLower(message.Content), strings.ToLower(f.Contains)):
+		return false
+	}
+	return true
+}
+
+// Describe summarizes the filters for the modlogs.
+func (f PurgeFilter) Describe() string {
+	var filters []string
+	if f.UserID != 0 {
+		filters = append(filters, fmt.Sprintf("from <@%d>", f.UserID))
+	}
+	if f.BotsOnly {
+		filters = append(filters, "from bots")
+	}
+	if f.AttachmentsOnly {
+		filters = append(filters, "with attachments")
+	}
+	if f.Contains != "" {
+		filters = append(filters, fmt.Sprintf("containing %q", f.Contains))
+	}
+	if f.Before != 0 {
+		filters = append(filters, fmt.Sprintf("before %d", f.Before))
+	}
+	if f.After != 0 {
+		filters = append(filters, fmt.Sprintf("after %d", f.After))
+	}
+	return strings.Join(filters, ", ")
+}
+
+// PurgeResult is the outcome of a purge.
+type PurgeResult struct {
+	Deleted int
+	// ReachedMaxAge is set when older messages may have matched but can't be
+	// bulk deleted.
+	ReachedMaxAge bool
+}
+
+// PurgeMessages bulk deletes the newest messages in a channel matching filter
+// and logs it as a single case. Pinned messages are always kept, and messages
+// older than two weeks are left alone since Discord won't bulk delete them.
+func (b *MartinGarrixBot) PurgeMessages(ctx context.Context, guildID, channelID, moderatorID snowflake.ID, filter PurgeFilter, reason string) (PurgeResult, error) {
+	result, err := b.purgeMessages(channelID, filter, reason)
+	if result.Deleted > 0 {
+		summary := fmt.Sprintf("Purged %d messages in <#%d>", result.Deleted, channelID)
+		if filters := filter.Describe(); filters != "" {
+			summary += " " + filters
+		}
+		if reason != "" {
+			summary += ": " + reason
+		}
+		b.logGuildAction(ctx, guildID, moderatorID, "purge", summary)
+	}
+	return result, err
+}
+
+func (b *MartinGarrixBot) purgeMessages(channelID snowflake.ID, filter PurgeFilter, reason string) (PurgeResult, error) {
+	var result PurgeResult
+	var matched []snowflake.ID
+	cutoff := time.Now().Add(-bulkDeleteMaxAge)
+
+	cursor := filter.Before
+	for scanned := 0; scanned < purgeMaxScan && len(matched) < filter.Limit; {
+		messages, err := b.Client.Rest().GetMessages(channelID, 0, cursor, 0, 100)
+		if err != nil {
+			return result, err
+		}
+		if len(messages) == 0 {
+			break
+		}
+		scanned += len(messages)
+
+		done := false
+		for _, message := range messages {
+			if filter.After != 0 && message.ID <= filter.After {
+				done = true
+				break
+			}
+			if message.ID.Time().Before(cutoff) {
+				result.ReachedMaxAge = true
+				done = true
+				break
+			}
+			if filter.matches(message) {
+				matched = append(matched, message.ID)
+				if len(matched) == filter.Limit {
+					done = true
+					break
+				}
+			}
+		}
+		if done {
+			break
+		}
+
+		// Messages come newest first
+		cursor = messages[len(messages)-1].ID
+	}
+
+	for start := 0; start < len(matched); start += bulkDeleteBatch {
+		batch := matched[start:min(start+bulkDeleteBatch, len(matched))]
+
+		var err error
+		if len(batch) == 1 {
+			// Bulk deletes need at least two messages
+			err = b.Client.Rest().DeleteMessage(channelID, batch[0], rest.WithReason(reason))
+		} else {
+			err = b.Client.Rest().BulkDeleteMessages(channelID, batch, rest.WithReason(reason))
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Deleted += len(batch)
+	}
+
+	return result, nil
+}
+
+// ParseUserIDs pulls the unique user IDs out of text, such as a pasted list
+// or an uploaded file, in the order they appear.
+func ParseUserIDs(text string) []snowflake.ID {
+	seen := make(map[snowflake.ID]bool)
+	var ids []snowflake.ID
+	for _, match := range userIDPattern.FindAllString(text, -1) {
+		id, err := snowflake.Parse(match)
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// MassBanResult is the outcome of a mass ban.
+type MassBanResult struct {
+	Banned []snowflake.ID
+	Failed []snowflake.ID
+}
+
+// MassBan bans users in bulk, deleting their messages from the last
+// deleteMessages, and logs it all as a single case. It only fails if nobody
+// could be banned.
+func (b *MartinGarrixBot) MassBan(ctx context.Context, guildID, moderatorID snowflake.ID, userIDs []snowflake.ID, deleteMessages time.Duration, reason string) (MassBanResult, error) {
+	var result MassBanResult
+	auditReason := "Mass ban"
+	if reason != "" {
+		auditReason += ": " + reason
+	}
+
+	var lastErr error
+	for start := 0; start < len(userIDs); start += bulkBanBatch {
+		batch := userIDs[start:min(start+bulkBanBatch, len(userIDs))]
+
+		banned, err := b.Client.Rest().BulkBan(guildID, discord.BulkBan{
+			UserIDs:              batch,
+			DeleteMessageSeconds: int(deleteMessages / time.Second),
+		}, rest.WithReason(auditReason))
+		if err != nil {
+			// Discord fails the whole request when nobody in it could be banned
+			result.Failed = append(result.Failed, batch...)
+			lastErr = err
+			continue
+		}
+
+		result.Banned = append(result.Banned, banned.BannedUsers...)
+		result.Failed = append(result.Failed, banned.FailedUsers...)
+	}
+
+	if len(result.Banned) == 0 && lastErr != nil {
+		return result, lastErr
+	}
+
+	if len(result.Banned) > 0 {
+		b.DeactivateTempBans(ctx, guildID, result.Banned...)
+
+		summary := fmt.Sprintf("Banned %d of %d users", len(result.Banned), len(userIDs))
+		if reason != "" {
+			summary += ": " + reason
+		}
+		b.logGuildAction(ctx, guildID, moderatorID, "massban", summary)
+	}
+
+	return result, nil
+}
+
+// logGuildAction logs an action that isn't against a single member, such as a
+// purge or a channel lock, as one case.
+func (b *MartinGarrixBot) logGuildAction(ctx context.Context, guildID, moderatorID snowflake.ID, logType, summary string) {
+	_, err := b.LogModAction(ctx, db.CreateModlogParams{
+		UserID:      0,
+		ModeratorID: int64(moderatorID),
+		GuildID:     int64(guildID),
+		LogType:     logType,
+		Reason:      pgtype.Text{String: utils.CutString(summary, ModlogReasonMaxLength), Valid: true},
+		ExpiresAt:   pgtype.Timestamp{Valid: false},
+		Active:      pgtype.Bool{Bool: true, Valid: true},
+	})
+	if err != nil {
+		slog.Error("Failed to create modlog entry", slog.Any("err", err))
+	}
+}
@@ -0,0 +1,299 @@
+package mgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/json"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+)
+
+const (
+	// MaxMuteDuration is the longest Discord lets a member be timed out for.
+	MaxMuteDuration = 28 * 24 * time.Hour
+	// TemporaryActionExpiryInterval is how often expired tempbans are lifted.
+	TemporaryActionExpiryInterval = time.Minute
+)
+
+// discordErrUnknownGuild is returned for guilds the bot is no longer in.
+const discordErrUnknownGuild rest.JSONErrorCode = 10004
+
+// What happens to a member when their warnings reach an escalation's count.
+const (
+	EscalationMute    = "mute"
+	EscalationTempBan = "tempban"
+)
+
+// WarnResult is the outcome of warning a member.
+type WarnResult struct {
+	// CaseNumber is the case the warning was logged as.
+	CaseNumber int32
+	// Warnings is how many active warnings the member has, counting the new
+	// one.
+	Warnings int64
+	// Escalation is the escalation the member reached, if any.
+	Escalation *db.WarnEscalation
+	// ExpiresAt is when the escalation's mute or ban ends.
+	ExpiresAt time.Time
+	// EscalationErr is why the escalation couldn't be applied. The warning
+	// is recorded regardless.
+	EscalationErr error
+}
+
+// FormatDuration formats a duration in the largest unit that keeps it whole,
+// in the format moderation commands take durations in.
+func FormatDuration(d time.Duration) string {
+	seconds := int64(d / time.Second)
+	switch {
+	case seconds%(7*24*60*60) == 0:
+		return fmt.Sprintf("%dw", seconds/(7*24*60*60))
+	case seconds%(24*60*60) == 0:
+		return fmt.Sprintf("%dd", seconds/(24*60*60))
+	case seconds%(60*60) == 0:
+		return fmt.Sprintf("%dh", seconds/(60*60))
+	case seconds%60 == 0:
+		return fmt.Sprintf("%dm", seconds/60)
+	}
+	return fmt.Sprintf("%ds", seconds)
+}
+
+// MuteMember times a member out for duration and records it in the modlogs,
+// returning when the timeout ends.
+func (b *MartinGarrixBot) MuteMember(ctx context.Context, guildID, userID, moderatorID snowflake.ID, duration time.Duration, reason string) (time.Time, error) {
+	expiresAt := time.Now().UTC().Add(duration)
+
+	timeoutUntil := json.NewNullable(expiresAt)
+	_, err := b.Client.Rest().UpdateMember(guildID, userID,
+		discord.MemberUpdate{
+			CommunicationDisabledUntil: &timeoutUntil,
+		},
+		rest.WithReason(reason),
+	)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	b.recordModlog(ctx, guildID, userID, moderatorID, "mute", reason, &expiresAt)
+	return expiresAt, nil
+}
+
+// TempBanMember bans a member for duration, deleting their messages from the
+// last deleteMessages, and records it in the modlogs. It returns when the ban
+// ends.
+func (b *MartinGarrixBot) TempBanMember(ctx context.Context, guildID, userID, moderatorID snowflake.ID, duration, deleteMessages time.Duration, reason string) (time.Time, error) {
+	expiresAt := time.Now().UTC().Add(duration)
+
+	err := b.Client.Rest().AddBan(guildID, userID, deleteMessages,
+		rest.WithReason(fmt.Sprintf("Tempban (%s): %s", FormatDuration(duration), reason)))
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Only the newest tempban should lift the ban
+	b.DeactivateTempBans(ctx, guildID, userID)
+
+	b.recordModlog(ctx, guildID, userID, moderatorID, "tempban", reason, &expiresAt)
+	return expiresAt, nil
+}
+
+// WarnMember records a warning against a member, then mutes or bans them if
+// their active warnings reached one of the guild's escalations. Escalations
+// only apply on reaching their exact count, so a member isn't punished again
+// for every warning after it.
+func (b *MartinGarrixBot) WarnMember(ctx context.Context, guildID, userID, moderatorID snowflake.ID, reason string) (WarnResult, error) {
+	tx, err := b.DB.Begin(ctx)
+	if err != nil {
+		return WarnResult{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	q := b.Queries.WithTx(tx)
+
+	// Creating the case locks the guild's case counter until the transaction
+	// ends, so concurrent warnings are counted one after another and each sees
+	// a different count
+	warning, err := q.CreateModlog(ctx, db.CreateModlogParams{
+		UserID:      int64(userID),
+		ModeratorID: int64(moderatorID),
+		GuildID:     int64(guildID),
+		LogType:     "warn",
+		Reason:      pgtype.Text{String: reason, Valid: reason != ""},
+		ExpiresAt:   pgtype.Timestamp{Valid: false},
+		Active:      pgtype.Bool{Bool: true, Valid: true},
+	})
+	if err != nil {
+		return WarnResult{}, err
+	}
+
+	result := WarnResult{CaseNumber: warning.CaseNumber}
+	result.Warnings, err = q.CountActiveWarnings(ctx, db.CountActiveWarningsParams{
+		UserID:  int64(userID),
+		GuildID: int64(guildID),
+	})
+	if err != nil {
+		return WarnResult{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return WarnResult{}, err
+	}
+	b.PostModlog(ctx, warning)
+
+	escalation, err := b.Queries.GetWarnEscalation(ctx, db.GetWarnEscalationParams{
+		GuildID:   int64(guildID),
+		WarnCount: int32(result.Warnings),
+	})
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return result, nil
+	} else if err != nil {
+		result.EscalationErr = err
+		return result, nil
+	}
+	result.Escalation = &escalation
+
+	duration := time.Duration(escalation.DurationSeconds) * time.Second
+	escalationReason := fmt.Sprintf("Reached %d warnings", result.Warnings)
+	botID := b.Client.ID()
+
+	switch escalation.Action {
+	case EscalationMute:
+		result.ExpiresAt, result.EscalationErr = b.MuteMember(ctx, guildID, userID, botID, duration, escalationReason)
+	case EscalationTempBan:
+		result.ExpiresAt, result.EscalationErr = b.TempBanMember(ctx, guildID, userID, botID, duration, 0, escalationReason)
+	default:
+		result.EscalationErr = fmt.Errorf("unknown escalation action %q", escalation.Action)
+	}
+
+	if result.EscalationErr != nil {
+		slog.Error("Failed to escalate warnings",
+			slog.Int64("guild_id", int64(guildID)),
+			slog.Int64("user_id", int64(userID)),
+			slog.Any("err", result.EscalationErr),
+		)
+	}
+	return result, nil
+}
+
+// recordModlog logs a timed action as a case. The action has already
+// happened, so failing to record it is only logged.
+func (b *MartinGarrixBot) recordModlog(ctx context.Context, guildID, userID, moderatorID snowflake.ID, logType, reason string, expiresAt *time.Time) {
+	_, err := b.LogModAction(ctx, db.CreateModlogParams{
+		UserID:      int64(userID),
+		ModeratorID: int64(moderatorID),
+		GuildID:     int64(guildID),
+		LogType:     logType,
+		Reason:      pgtype.Text{String: reason, Valid: reason != ""},
+		ExpiresAt:   pgtype.Timestamp{Time: *expiresAt, Valid: true},
+		Active:      pgtype.Bool{Bool: true, Valid: true},
+	})
+	if err != nil {
+		slog.Error("Failed to create modlog entry", slog.Any("err", err))
+	}
+}
+
+// ExpireTemporaryActions unbans members whose tempbans are up and deactivates
+// expired mutes, which Discord lifts by itself. It works from the modlogs, so
+// anything that expired while the bot was down is caught up on the first run.
+func (b *MartinGarrixBot) ExpireTemporaryActions(ctx context.Context) (int, error) {
+	guildIDs, err := b.Queries.GetGuildsWithExpiredActions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	unbanned := 0
+	for _, guildID := range guildIDs {
+		expired, err := b.Queries.GetExpiredTemporaryActions(ctx, guildID)
+		if err != nil {
+			return unbanned, err
+		}
+
+		for _, action := range expired {
+			if action.LogType == "tempban" {
+				if err := b.liftTempBan(ctx, action); err != nil {
+					// Left active so it is retried on the next run
+					slog.Error("Failed to lift expired tempban",
+						slog.Int64("guild_id", action.GuildID),
+						slog.Int64("user_id", action.UserID),
+						slog.Any("err", err),
+					)
+					continue
+				}
+				unbanned++
+			}
+
+			if err := b.Queries.DeactivateModlog(ctx, action.ID); err != nil {
+				return unbanned, err
+			}
+		}
+	}
+
+	return unbanned, nil
+}
+
+// DeactivateTempBans stops the expiry job from lifting the users' active
+// tempbans. It is called whenever they are banned or unbanned again, so an old
+// tempban expiring doesn't lift a newer or permanent ban.
+func (b *MartinGarrixBot) DeactivateTempBans(ctx context.Context, guildID snowflake.ID, userIDs ...snowflake.ID) {
+	ids := make([]int64, len(userIDs))
+	for i, userID := range userIDs {
+		ids[i] = int64(userID)
+	}
+
+	err := b.Queries.DeactivateTempBans(ctx, db.DeactivateTempBansParams{
+		GuildID: int64(guildID),
+		UserIds: ids,
+	})
+	if err != nil {
+		slog.Error("Failed to deactivate tempbans", slog.Any("err", err))
+	}
+}
+
+func (b *MartinGarrixBot) liftTempBan(ctx context.Context, tempban db.Modlog) error {
+	guildID := snowflake.ID(tempban.GuildID)
+	userID := snowflake.ID(tempban.UserID)
+	reason := "Tempban expired"
+
+	err := b.Client.Rest().DeleteBan(guildID, userID, rest.WithReason(reason))
+	var restErr rest.Error
+	if errors.As(err, &restErr) && restErr.Response != nil {
+		switch {
+		case restErr.Response.StatusCode == http.StatusNotFound,
+			restErr.Response.StatusCode == http.StatusForbidden,
+			restErr.Code == discordErrUnknownGuild:
+			// Already unbanned by hand, or the bot has left the guild or lost
+			// the permission to unban, so retrying won't help
+			slog.Warn("Dropping expired tempban that can't be lifted",
+				slog.Int64("guild_id", tempban.GuildID),
+				slog.Int64("user_id", tempban.UserID),
+				slog.Any("err", err),
+			)
+			return nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	botID := b.Client.ID()
+	_, err = b.LogModAction(ctx, db.CreateModlogParams{
+		UserID:      int64(userID),
+		ModeratorID: int64(botID),
+		GuildID:     int64(guildID),
+		LogType:     "auto-unban",
+		Reason:      pgtype.Text{String: reason, Valid: true},
+		ExpiresAt:   pgtype.Timestamp{Valid: false},
+		Active:      pgtype.Bool{Bool: true, Valid: true},
+	})
+	if err != nil {
+		slog.Error("Failed to create modlog entry", slog.Any("err", err))
+	}
+	return nil
+}
@@ -0,0 +1,237 @@
+package mgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+// Kinds of change made to a case after it was logged.
+const (
+	ModlogEditReason = "reason"
+	ModlogEditVoid   = "void"
+)
+
+// ModlogReasonMaxLength is the longest reason the modlogs table stores.
+const ModlogReasonMaxLength = 400
+
+// colorVoided greys out voided cases in the modlogs channel.
+const colorVoided = 0x95a5a6
+
+var ErrCaseVoided = errors.New("case has already been voided")
+
+// LogModAction records a moderation action in the modlogs and posts it to the
+// guild's modlogs channel, returning the new case.
+func (b *MartinGarrixBot) LogModAction(ctx context.Context, params db.CreateModlogParams) (db.Modlog, error) {
+	modlog, err := b.Queries.CreateModlog(ctx, params)
+	if err != nil {
+		return modlog, err
+	}
+
+	b.PostModlog(ctx, modlog)
+	return modlog, nil
+}
+
+// PostModlog posts a case to the guild's modlogs channel, if it has one, and
+// remembers the message so later edits to the case can update it.
+func (b *MartinGarrixBot) PostModlog(ctx context.Context, modlog db.Modlog) {
+	config, err := b.Queries.GetGuild(ctx, modlog.GuildID)
+	if err != nil || !config.ModlogsChannel.Valid {
+		return
+	}
+
+	channelID := snowflake.ID(config.ModlogsChannel.Int64)
+	message, err := b.Client.Rest().CreateMessage(channelID,
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(ModlogEmbed(modlog)).
+			Build(),
+	)
+	if err != nil {
+		slog.Error("Failed to send modlog to channel", slog.Any("err", err))
+		return
+	}
+
+	err = b.Queries.SetModlogMessage(ctx, db.SetModlogMessageParams{
+		ID:           modlog.ID,
+		LogChannelID: pgtype.Int8{Int64: int64(channelID), Valid: true},
+		LogMessageID: pgtype.Int8{Int64: int64(message.ID), Valid: true},
+	})
+	if err != nil {
+		slog.Error("Failed to save modlog message", slog.Any("err", err))
+	}
+}
+
+// ModlogEmbed shows a case the way it is posted in the modlogs channel.
+func ModlogEmbed(modlog db.Modlog) discord.Embed {
+	reason := "No reason provided"
+	if modlog.Reason.Valid && modlog.Reason.String != "" {
+		reason = modlog.Reason.String
+	}
+
+	title := fmt.Sprintf("Case #%d | %s", modlog.CaseNumber, strings.ToUpper(modlog.LogType))
+	color := utils.ColorWarning
+	if modlog.Voided {
+		title += " (voided)"
+		color = colorVoided
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle(title).
+		SetColor(color)
+
+	// Purges, mass bans and locks aren't against a single member
+	if modlog.UserID != 0 {
+		embed.AddField("User", fmt.Sprintf("<@%d>", modlog.UserID), true)
+	}
+	embed.AddField("Moderator", fmt.Sprintf("<@%d>", modlog.ModeratorID), true).
+		AddField("Reason", reason, false)
+
+	if modlog.ExpiresAt.Valid {
+		embed.AddField("Expires", fmt.Sprintf("<t:%d:R>", modlog.ExpiresAt.Time.Unix()), false)
+	}
+	if modlog.Time.Valid {
+		embed.SetTimestamp(modlog.Time.Time)
+	}
+
+	return embed.Build()
+}
+
+// EditModlogReason replaces the reason of a case, records the edit and updates
+// the case in the modlogs channel.
+func (b *MartinGarrixBot) EditModlogReason(ctx context.Context, guildID snowflake.ID, caseNumber int32, editorID snowflake.ID, reason string) (db.Modlog, error) {
+	return b.editModlog(ctx, guildID, caseNumber, editorID, ModlogEditReason, reason,
+		func(q *db.Queries, modlog *db.Modlog) error {
+			modlog.Reason = pgtype.Text{String: reason, Valid: true}
+			return q.UpdateModlogReason(ctx, db.UpdateModlogReasonParams{ID: modlog.ID, Reason: modlog.Reason})
+		})
+}
+
+// VoidModlog marks a case as logged by mistake, records why and updates the
+// case in the modlogs channel. Voided cases are kept, but voided warnings no
+// longer count towards escalations. Voiding doesn't lift a ban or mute.
+func (b *MartinGarrixBot) VoidModlog(ctx context.Context, guildID snowflake.ID, caseNumber int32, editorID snowflake.ID, reason string) (db.Modlog, error) {
+	return b.editModlog(ctx, guildID, caseNumber, editorID, ModlogEditVoid, reason,
+		func(q *db.Queries, modlog *db.Modlog) error {
+			if modlog.Voided {
+				return ErrCaseVoided
+			}
+			modlog.Voided = true
+			return q.VoidModlog(ctx, modlog.ID)
+		})
+}
+
+func (b *MartinGarrixBot) editModlog(ctx context.Context, guildID snowflake.ID, caseNumber int32, editorID snowflake.ID, editType, newReason string, edit func(q *db.Queries, modlog *db.Modlog) error) (db.Modlog, error) {
+	tx, err := b.DB.Begin(ctx)
+	if err != nil {
+		return db.Modlog{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	q := b.Queries.WithTx(tx)
+
+	modlog, err := q.GetModlogByCaseForUpdate(ctx, db.GetModlogByCaseForUpdateParams{
+		GuildID:    int64(guildID),
+		CaseNumber: caseNumber,
+	})
+	if err != nil {
+		return db.Modlog{}, err
+	}
+
+	oldReason := modlog.Reason
+	if err := edit(q, &modlog); err != nil {
+		return db.Modlog{}, err
+	}
+
+	audit := db.ModlogEdit{
+		ModlogID:  modlog.ID,
+		EditorID:  int64(editorID),
+		EditType:  editType,
+		OldReason: oldReason,
+		NewReason: pgtype.Text{String: newReason, Valid: newReason != ""},
+		EditedAt:  pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	}
+	err = q.CreateModlogEdit(ctx, db.CreateModlogEditParams{
+		ModlogID:  audit.ModlogID,
+		EditorID:  audit.EditorID,
+		EditType:  audit.EditType,
+		OldReason: audit.OldReason,
+		NewReason: audit.NewReason,
+		EditedAt:  audit.EditedAt,
+	})
+	if err != nil {
+		return db.Modlog{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return db.Modlog{}, err
+	}
+
+	b.postModlogEdit(ctx, modlog, audit)
+	return modlog, nil
+}
+
+// postModlogEdit updates a case's message in the modlogs channel and posts
+// who changed it and how underneath.
+func (b *MartinGarrixBot) postModlogEdit(ctx context.Context, modlog db.Modlog, edit db.ModlogEdit) {
+	if modlog.LogChannelID.Valid && modlog.LogMessageID.Valid {
+		_, err := b.Client.Rest().UpdateMessage(
+			snowflake.ID(modlog.LogChannelID.Int64),
+			snowflake.ID(modlog.LogMessageID.Int64),
+			discord.NewMessageUpdateBuilder().
+				SetEmbeds(ModlogEmbed(modlog)).
+				Build(),
+		)
+		if err != nil {
+			slog.Error("Failed to update modlog message", slog.Any("err", err))
+		}
+	}
+
+	config, err := b.Queries.GetGuild(ctx, modlog.GuildID)
+	if err != nil || !config.ModlogsChannel.Valid {
+		return
+	}
+
+	_, err = b.Client.Rest().CreateMessage(snowflake.ID(config.ModlogsChannel.Int64),
+		discord.NewMessageCreateBuilder().
+			SetEmbeds(ModlogEditEmbed(modlog, edit)).
+			Build(),
+	)
+	if err != nil {
+		slog.Error("Failed to send modlog edit to channel", slog.Any("err", err))
+	}
+}
+
+// ModlogEditEmbed shows a change made to a case.
+func ModlogEditEmbed(modlog db.Modlog, edit db.ModlogEdit) discord.Embed {
+	embed := discord.NewEmbedBuilder().
+		SetTitle(fmt.Sprintf("Case #%d Reason Edited", modlog.CaseNumber)).
+		AddField("Edited By", fmt.Sprintf("<@%d>", edit.EditorID), true).
+		SetTimestamp(edit.EditedAt.Time).
+		SetColor(utils.ColorInfo)
+
+	if edit.EditType == ModlogEditVoid {
+		embed.SetTitle(fmt.Sprintf("Case #%d Voided", modlog.CaseNumber)).
+			SetColor(colorVoided)
+		if edit.NewReason.Valid {
+			embed.AddField("Why", edit.NewReason.String, false)
+		}
+		return embed.Build()
+	}
+
+	oldReason := "No reason provided"
+	if edit.OldReason.Valid && edit.OldReason.String != "" {
+		oldReason = edit.OldReason.String
+	}
+	embed.AddField("Before", oldReason, false).
+		AddField("After", edit.NewReason.String, false)
+	return embed.Build()
+}
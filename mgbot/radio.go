@@ -64,6 +64,34 @@ func (b *MartinGarrixBot) ReconnectRadio(guildID snowflake.ID) {
 	}
 }
 
+// AutoStartRadio starts the radio in every guild with a radio channel
+// configured. It is a no-op when Lavalink isn't connected.
+func (b *MartinGarrixBot) AutoStartRadio() {
+	time.Sleep(5 * time.Second) // Wait for everything to be ready
+
+	// Only auto-start if Lavalink is connected
+	if b.RadioManager == nil || !b.RadioManager.IsLavalinkConnected() {
+		slog.Info("Lavalink not connected - skipping auto-start of radio")
+		return
+	}
+
+	radioConfigs, err := b.Queries.GetRadioVoiceChannels(context.Background())
+	if err != nil {
+		slog.Error("Failed to get radio configurations", slog.Any("err", err))
+		return
+	}
+
+	for _, config := range radioConfigs {
+		if config.RadioVoiceChannel.Valid {
+			guildID := snowflake.ID(config.GuildID)
+			slog.Info("Auto-starting radio", slog.String("guild_id", guildID.String()))
+			if err := b.StartRadioInGuild(context.Background(), guildID); err != nil {
+				slog.Error("Failed to start radio", slog.Any("err", err), slog.String("guild_id", guildID.String()))
+			}
+		}
+	}
+}
+
 // StartRadioInGuild starts the 24/7 radio in a specific guild
 func (b *MartinGarrixBot) StartRadioInGuild(ctx context.Context, guildID snowflake.ID) error {
 	// Ensure Lavalink is connected, if not try to connect
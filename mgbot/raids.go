@@ -0,0 +1,424 @@
+package mgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/rest"
+	"github.com/disgoorg/json"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+// What raid mode does to members joining while it is on.
+const (
+	RaidActionAlert   = "alert"
+	RaidActionKick    = "kick"
+	RaidActionTimeout = "timeout"
+	// raidActionFailed records a member raid mode couldn't kick or time out.
+	raidActionFailed = "failed"
+)
+
+const (
+	// RaidModeExpiryInterval is how often raid modes that are up are ended.
+	RaidModeExpiryInterval = time.Minute
+	// DefaultRaidModeDuration is how long raid mode stays on if the guild
+	// hasn't configured it.
+	DefaultRaidModeDuration = 10 * time.Minute
+)
+
+var (
+	ErrRaidModeActive   = errors.New("raid mode is already on")
+	ErrRaidModeInactive = errors.New("raid mode isn't on")
+)
+
+type raidJoin struct {
+	userID   snowflake.ID
+	joinedAt time.Time
+}
+
+// RaidTracker remembers each guild's recent joins to detect raids.
+type RaidTracker struct {
+	mu     sync.Mutex
+	guilds map[snowflake.ID][]raidJoin
+}
+
+func NewRaidTracker() *RaidTracker {
+	return &RaidTracker{
+		guilds: make(map[snowflake.ID][]raidJoin),
+	}
+}
+
+// record adds a join and, if the guild has had threshold joins within window,
+// returns them and forgets them so the raid is only detected once.
+func (t *RaidTracker) record(guildID, userID snowflake.ID, now time.Time, window time.Duration, threshold int) []raidJoin {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	joins := t.guilds[guildID][:0]
+	for _, join := range t.guilds[guildID] {
+		if now.Sub(join.joinedAt) <= window {
+			joins = append(joins, join)
+		}
+	}
+	joins = append(joins, raidJoin{userID: userID, joinedAt: now})
+
+	if len(joins) < threshold {
+		t.guilds[guildID] = joins
+		return nil
+	}
+
+	delete(t.guilds, guildID)
+	return joins
+}
+
+// IsNewAccount reports whether an account is young enough to count towards a
+// raid under settings.
+func IsNewAccount(settings db.RaidSetting, userID snowflake.ID, now time.Time) bool {
+	if settings.NewAccountDays <= 0 {
+		return true
+	}
+	return now.Sub(userID.Time()) < time.Duration(settings.NewAccountDays)*24*time.Hour
+}
+
+// CheckRaidJoin tracks a member joining. While raid mode is on the member is
+// dealt with and recorded in the raid, otherwise the join counts towards the
+// guild's raid threshold and starts raid mode when it is reached.
+func (b *MartinGarrixBot) CheckRaidJoin(ctx context.Context, guildID snowflake.ID, user discord.User) {
+	if user.Bot {
+		return
+	}
+
+	now := time.Now().UTC()
+	settings, err := b.Queries.GetRaidSettings(ctx, int64(guildID))
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		slog.Error("Failed to get raid settings", slog.Any("err", err))
+		return
+	}
+	if !IsNewAccount(settings, user.ID, now) {
+		return
+	}
+
+	event, err := b.Queries.GetActiveRaidEvent(ctx, int64(guildID))
+	if err == nil {
+		go b.handleRaidJoin(context.Background(), event, user.ID, now)
+		return
+	} else if !errors.Is(err, db.ErrRecordNotFound) {
+		slog.Error("Failed to get active raid", slog.Any("err", err))
+		return
+	}
+
+	if !settings.Enabled {
+		return
+	}
+
+	window := time.Duration(settings.JoinWindowSeconds) * time.Second
+	joins := b.Raids.record(guildID, user.ID, now, window, int(settings.JoinThreshold))
+	if joins == nil {
+		return
+	}
+
+	event, err = b.startRaid(ctx, guildID, settings, pgtype.Int8{Valid: false}, 0)
+	if errors.Is(err, ErrRaidModeActive) {
+		// Raid mode was turned on at the same time, so these joins are dealt
+		// with like any other during it
+		if event, err = b.Queries.GetActiveRaidEvent(ctx, int64(guildID)); err == nil {
+			go b.handleRaidJoins(context.Background(), event, joins)
+			return
+		}
+	}
+	if err != nil {
+		slog.Error("Failed to start raid mode", slog.Int64("guild_id", int64(guildID)), slog.Any("err", err))
+		return
+	}
+
+	mentions := make([]string, len(joins))
+	for i, join := range joins {
+		mentions[i] = fmt.Sprintf("<@%d>", join.userID)
+	}
+
+	// Alerting and dealing with the joins take REST calls, which shouldn't
+	// hold up the gateway listener
+	go func() {
+		ctx := context.Background()
+
+		summary := fmt.Sprintf("Raid detected: %d joins within %ds", len(joins), settings.JoinWindowSeconds)
+		b.logGuildAction(ctx, guildID, b.Client.ID(), "raidmode", summary)
+
+		embed := raidEventEmbed(event).
+			SetTitle("Raid Detected").
+			SetDescription(fmt.Sprintf("%d accounts joined within %d seconds.", len(joins), settings.JoinWindowSeconds)).
+			AddField("Joined", utils.CutString(strings.Join(mentions, " "), 1024), false).
+			Build()
+		b.postRaidAlert(ctx, guildID, settings, embed, true)
+
+		b.handleRaidJoins(ctx, event, joins)
+	}()
+}
+
+// StartRaidMode turns raid mode on by hand for duration, or the guild's
+// configured raid mode duration if it is 0. New joins get the guild's
+// configured action, or are only recorded if it has none.
+func (b *MartinGarrixBot) StartRaidMode(ctx context.Context, guildID, moderatorID snowflake.ID, duration time.Duration, reason string) (db.RaidEvent, error) {
+	settings, err := b.Queries.GetRaidSettings(ctx, int64(guildID))
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		return db.RaidEvent{}, err
+	}
+
+	event, err := b.startRaid(ctx, guildID, settings, pgtype.Int8{Int64: int64(moderatorID), Valid: true}, duration)
+	if err != nil {
+		return db.RaidEvent{}, err
+	}
+
+	b.logGuildAction(ctx, guildID, moderatorID, "raidmode", withReason("Raid mode started for "+FormatDuration(event.EndsAt.Time.Sub(event.StartedAt.Time)), reason))
+
+	embed := raidEventEmbed(event).
+		SetTitle("Raid Mode Started").
+		SetDescription(fmt.Sprintf("<@%d> turned raid mode on.", moderatorID))
+	if reason != "" {
+		embed.AddField("Reason", reason, false)
+	}
+	b.postRaidAlert(ctx, guildID, settings, embed.Build(), false)
+
+	return event, nil
+}
+
+// EndRaidMode turns raid mode off before it is up and returns how many members
+// joined during it and how many of them were kicked or timed out.
+func (b *MartinGarrixBot) EndRaidMode(ctx context.Context, guildID, moderatorID snowflake.ID) (db.CountRaidEventMembersRow, error) {
+	event, err := b.Queries.GetActiveRaidEvent(ctx, int64(guildID))
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return db.CountRaidEventMembersRow{}, ErrRaidModeInactive
+	} else if err != nil {
+		return db.CountRaidEventMembersRow{}, err
+	}
+
+	return b.endRaid(ctx, event, moderatorID)
+}
+
+// EndExpiredRaidModes turns off raid modes that are up, like tempbans catching
+// up on any that expired while the bot was down.
+func (b *MartinGarrixBot) EndExpiredRaidModes(ctx context.Context) (int, error) {
+	events, err := b.Queries.GetExpiredRaidEvents(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	ended := 0
+	for _, event := range events {
+		_, err := b.endRaid(ctx, event, b.Client.ID())
+		if errors.Is(err, ErrRaidModeInactive) {
+			continue
+		} else if err != nil {
+			return ended, err
+		}
+		ended++
+	}
+
+	return ended, nil
+}
+
+// startRaid turns raid mode on, returning ErrRaidModeActive if it already is.
+// Only one raid mode can be open per guild, which the database enforces so two
+// raid modes started at once can't both go through.
+func (b *MartinGarrixBot) startRaid(ctx context.Context, guildID snowflake.ID, settings db.RaidSetting, startedBy pgtype.Int8, duration time.Duration) (db.RaidEvent, error) {
+	action := settings.Action
+	if action == "" {
+		action = RaidActionAlert
+	}
+	if duration <= 0 {
+		duration = time.Duration(settings.RaidModeSeconds) * time.Second
+	}
+	if duration <= 0 {
+		duration = DefaultRaidModeDuration
+	}
+
+	now := time.Now().UTC()
+
+	open, err := b.Queries.GetOpenRaidEvent(ctx, int64(guildID))
+	if err == nil {
+		if open.EndsAt.Time.After(now) {
+			return db.RaidEvent{}, ErrRaidModeActive
+		}
+		// Up, but the expiry job hasn't ended it yet
+		if _, err := b.endRaid(ctx, open, b.Client.ID()); err != nil && !errors.Is(err, ErrRaidModeInactive) {
+			return db.RaidEvent{}, err
+		}
+	} else if !errors.Is(err, db.ErrRecordNotFound) {
+		return db.RaidEvent{}, err
+	}
+
+	event, err := b.Queries.CreateRaidEvent(ctx, db.CreateRaidEventParams{
+		GuildID:        int64(guildID),
+		StartedBy:      startedBy,
+		Action:         action,
+		TimeoutSeconds: settings.TimeoutSeconds,
+		StartedAt:      pgtype.Timestamp{Time: now, Valid: true},
+		EndsAt:         pgtype.Timestamp{Time: now.Add(duration), Valid: true},
+	})
+	if db.ErrorCode(err) == db.UniqueViolation {
+		return db.RaidEvent{}, ErrRaidModeActive
+	}
+	return event, err
+}
+
+func (b *MartinGarrixBot) endRaid(ctx context.Context, event db.RaidEvent, moderatorID snowflake.ID) (db.CountRaidEventMembersRow, error) {
+	guildID := snowflake.ID(event.GuildID)
+
+	ended, err := b.Queries.EndRaidEvent(ctx, db.EndRaidEventParams{
+		ID:      event.ID,
+		EndedAt: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		return db.CountRaidEventMembersRow{}, err
+	}
+	if ended == 0 {
+		// Ended by a moderator and the expiry job at once
+		return db.CountRaidEventMembersRow{}, ErrRaidModeInactive
+	}
+
+	counts, err := b.Queries.CountRaidEventMembers(ctx, event.ID)
+	if err != nil {
+		slog.Error("Failed to count raid members", slog.Any("err", err))
+	}
+
+	summary := fmt.Sprintf("Raid mode ended: %d joins", counts.Joins)
+	if event.Action != RaidActionAlert {
+		summary += fmt.Sprintf(", %d %s", counts.Actioned, strings.ToLower(raidActionLabel(event.Action)))
+	}
+	b.logGuildAction(ctx, guildID, moderatorID, "raidmode-end", summary)
+
+	settings, err := b.Queries.GetRaidSettings(ctx, event.GuildID)
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		slog.Error("Failed to get raid settings", slog.Any("err", err))
+		return counts, nil
+	}
+
+	embed := discord.NewEmbedBuilder().
+		SetTitle("Raid Mode Ended").
+		SetColor(utils.ColorSuccess).
+		AddField("Joins", fmt.Sprint(counts.Joins), true).
+		SetTimestamp(time.Now())
+	if event.Action != RaidActionAlert {
+		embed.AddField(raidActionLabel(event.Action), fmt.Sprint(counts.Actioned), true)
+	}
+	b.postRaidAlert(ctx, guildID, settings, embed.Build(), false)
+
+	return counts, nil
+}
+
+// handleRaidJoins deals with members who joined during a raid one after
+// another.
+func (b *MartinGarrixBot) handleRaidJoins(ctx context.Context, event db.RaidEvent, joins []raidJoin) {
+	for _, join := range joins {
+		b.handleRaidJoin(ctx, event, join.userID, join.joinedAt)
+	}
+}
+
+// handleRaidJoin kicks or times out a member who joined during a raid, as the
+// raid's action says, and records them in it.
+func (b *MartinGarrixBot) handleRaidJoin(ctx context.Context, event db.RaidEvent, userID snowflake.ID, joinedAt time.Time) {
+	guildID := snowflake.ID(event.GuildID)
+	reason := "Raid mode"
+
+	action := event.Action
+	var err error
+	switch action {
+	case RaidActionKick:
+		err = b.Client.Rest().RemoveMember(guildID, userID, rest.WithReason(reason))
+	case RaidActionTimeout:
+		timeoutUntil := json.NewNullable(time.Now().UTC().Add(time.Duration(event.TimeoutSeconds) * time.Second))
+		_, err = b.Client.Rest().UpdateMember(guildID, userID,
+			discord.MemberUpdate{
+				CommunicationDisabledUntil: &timeoutUntil,
+			},
+			rest.WithReason(reason),
+		)
+	}
+	if err != nil {
+		slog.Error("Failed to apply raid mode to member",
+			slog.Int64("guild_id", event.GuildID),
+			slog.Int64("user_id", int64(userID)),
+			slog.Any("err", err),
+		)
+		action = raidActionFailed
+	}
+
+	err = b.Queries.AddRaidEventMember(ctx, db.AddRaidEventMemberParams{
+		RaidEventID: event.ID,
+		UserID:      int64(userID),
+		JoinedAt:    pgtype.Timestamp{Time: joinedAt, Valid: true},
+		Action:      action,
+	})
+	if err != nil {
+		slog.Error("Failed to record raid member", slog.Any("err", err))
+	}
+}
+
+// postRaidAlert sends an alert to the guild's raid alert channel, or its
+// modlogs channel if it has none, pinging the moderator role if ping is set.
+func (b *MartinGarrixBot) postRaidAlert(ctx context.Context, guildID snowflake.ID, settings db.RaidSetting, embed discord.Embed, ping bool) {
+	config, err := b.Queries.GetGuild(ctx, int64(guildID))
+	if err != nil {
+		slog.Error("Failed to get guild config", slog.Any("err", err))
+		return
+	}
+
+	channel := settings.AlertChannelID
+	if !channel.Valid {
+		channel = config.ModlogsChannel
+	}
+	if !channel.Valid {
+		return
+	}
+
+	message := discord.NewMessageCreateBuilder().
+		SetEmbeds(embed)
+	if ping && config.ModeratorRole.Valid {
+		message.SetContent(fmt.Sprintf("<@&%d>", config.ModeratorRole.Int64)).
+			SetAllowedMentions(&discord.AllowedMentions{
+				Roles: []snowflake.ID{snowflake.ID(config.ModeratorRole.Int64)},
+			})
+	}
+
+	_, err = b.Client.Rest().CreateMessage(snowflake.ID(channel.Int64), message.Build())
+	if err != nil {
+		slog.Error("Failed to send raid alert", slog.Any("err", err))
+	}
+}
+
+// raidEventEmbed starts an alert about raid mode turning on.
+func raidEventEmbed(event db.RaidEvent) *discord.EmbedBuilder {
+	return discord.NewEmbedBuilder().
+		SetColor(utils.ColorDanger).
+		AddField("Raid Mode", DescribeRaidAction(event.Action, event.TimeoutSeconds), true).
+		AddField("Ends", fmt.Sprintf("<t:%d:R>", event.EndsAt.Time.Unix()), true).
+		SetFooterText("End it early with /moderation raid end").
+		SetTimestamp(event.StartedAt.Time)
+}
+
+// DescribeRaidAction says what raid mode does to new joins.
+func DescribeRaidAction(action string, timeoutSeconds int32) string {
+	switch action {
+	case RaidActionKick:
+		return "Kicking new joins"
+	case RaidActionTimeout:
+		return "Timing out new joins for " + FormatDuration(time.Duration(timeoutSeconds)*time.Second)
+	}
+	return "Alerting only"
+}
+
+func raidActionLabel(action string) string {
+	if action == RaidActionKick {
+		return "Kicked"
+	}
+	return "Timed Out"
+}
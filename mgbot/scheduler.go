@@ -0,0 +1,321 @@
+package mgbot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+const (
+	// jobInitialBackoff is how long a job waits before being retried after its
+	// first consecutive failure. Each further failure doubles the wait.
+	jobInitialBackoff = 5 * time.Second
+	// jobMaxBackoff caps the retry delay so a job that keeps failing is still
+	// retried regularly once whatever it depends on comes back.
+	jobMaxBackoff = 5 * time.Minute
+)
+
+var (
+	ErrJobNotFound         = errors.New("job not found")
+	ErrJobAlreadyQueued    = errors.New("job already has a manual run queued")
+	ErrSchedulerNotStarted = errors.New("scheduler has not been started")
+)
+
+// JobFunc performs a single run of a background job and reports how many new
+// items it found. It should return once the run is complete; the scheduler is
+// responsible for calling it again. Returning an error (or panicking)
+// schedules a retry with backoff.
+type JobFunc func(ctx context.Context) (int, error)
+
+// JobStatus is a point-in-time snapshot of a registered job.
+type JobStatus struct {
+	Name     string
+	Interval time.Duration
+	Running  bool
+	// Paused jobs skip their scheduled runs but can still be run manually.
+	Paused  bool
+	LastRun time.Time
+	NextRun time.Time
+	// LastItems is the number of new items found by the last successful run.
+	LastItems int
+	// LastError is kept after later successful runs so an intermittent failure
+	// is still visible; LastErrorAt says when it happened.
+	LastError   string
+	LastErrorAt time.Time
+	// Failures is the number of consecutive failed runs, reset on success.
+	Failures int
+}
+
+type job struct {
+	run    JobFunc
+	status JobStatus
+	// trigger receives manual run requests. The loop replies on the given
+	// channel once the run finishes.
+	trigger chan chan error
+}
+
+// Scheduler runs named background jobs at fixed intervals. Each job gets its
+// own goroutine; a run that returns an error or panics is logged, recorded in
+// the job's status and retried with exponential backoff instead of taking the
+// job down until the next restart.
+type Scheduler struct {
+	mu      sync.RWMutex
+	jobs    []*job
+	started bool
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewScheduler() *Scheduler {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Register adds a job to the scheduler. Jobs registered after Start are
+// started immediately.
+func (s *Scheduler) Register(name string, interval time.Duration, fn JobFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, j := range s.jobs {
+		if j.status.Name == name {
+			panic(fmt.Sprintf("scheduler: job %q registered twice", name))
+		}
+	}
+
+	j := &job{
+		run: fn,
+		status: JobStatus{
+			Name:     name,
+			Interval: interval,
+		},
+		trigger: make(chan chan error, 1),
+	}
+	s.jobs = append(s.jobs, j)
+
+	if s.started {
+		s.wg.Add(1)
+		go s.loop(j)
+	}
+}
+
+// Start launches every registered job. Each job runs once straight away and
+// then on its interval. Calling Start more than once is a no-op, so it is safe
+// to call from a Ready handler that may fire again after a reconnect.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(j)
+	}
+	slog.Info("Scheduler started", slog.Int("jobs", len(s.jobs)))
+}
+
+// Stop cancels the context passed to running jobs and waits for them to
+// return, or for ctx to expire, whichever happens first.
+func (s *Scheduler) Stop(ctx context.Context) {
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		slog.Info("Scheduler stopped")
+	case <-ctx.Done():
+		slog.Warn("Timed out waiting for background jobs to stop")
+	}
+}
+
+// Status returns a snapshot of every registered job in registration order.
+func (s *Scheduler) Status() []JobStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		statuses = append(statuses, j.status)
+	}
+	return statuses
+}
+
+// RunNow runs the named job immediately, outside its schedule, and waits for
+// the run to finish or for ctx to expire. If the job is already running the
+// manual run starts as soon as the current one returns. It works on paused
+// jobs too.
+func (s *Scheduler) RunNow(ctx context.Context, name string) (JobStatus, error) {
+	s.mu.RLock()
+	j := s.find(name)
+	started := s.started
+	s.mu.RUnlock()
+
+	if j == nil {
+		return JobStatus{}, ErrJobNotFound
+	}
+	if !started {
+		return s.status(j), ErrSchedulerNotStarted
+	}
+
+	reply := make(chan error, 1)
+	select {
+	case j.trigger <- reply:
+	default:
+		return s.status(j), ErrJobAlreadyQueued
+	}
+
+	select {
+	case err := <-reply:
+		return s.status(j), err
+	case <-ctx.Done():
+		return s.status(j), ctx.Err()
+	}
+}
+
+// Pause stops the named job from running on its schedule until Resume is
+// called. A run that is already in progress is not interrupted.
+func (s *Scheduler) Pause(name string) error {
+	return s.setPaused(name, true)
+}
+
+// Resume lets a paused job run on its schedule again. The next run happens
+// at the job's already scheduled time.
+func (s *Scheduler) Resume(name string) error {
+	return s.setPaused(name, false)
+}
+
+func (s *Scheduler) setPaused(name string, paused bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j := s.find(name)
+	if j == nil {
+		return ErrJobNotFound
+	}
+	j.status.Paused = paused
+	return nil
+}
+
+// find looks up a job by name. The caller must hold s.mu.
+func (s *Scheduler) find(name string) *job {
+	for _, j := range s.jobs {
+		if j.status.Name == name {
+			return j
+		}
+	}
+	return nil
+}
+
+func (s *Scheduler) status(j *job) JobStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return j.status
+}
+
+func (s *Scheduler) loop(j *job) {
+	defer s.wg.Done()
+
+	var reply chan error
+	for {
+		s.mu.RLock()
+		paused := j.status.Paused
+		s.mu.RUnlock()
+
+		ran := false
+		var err error
+		if reply != nil || !paused {
+			err = s.runOnce(j)
+			ran = true
+		}
+		if reply != nil {
+			reply <- err
+			reply = nil
+		}
+		if s.ctx.Err() != nil {
+			return
+		}
+
+		s.mu.Lock()
+		delay := j.status.Interval
+		if ran && err != nil {
+			j.status.Failures++
+			delay = min(backoff(j.status.Failures), j.status.Interval)
+		} else if ran {
+			j.status.Failures = 0
+		}
+		j.status.NextRun = time.Now().Add(delay)
+		s.mu.Unlock()
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		case reply = <-j.trigger:
+			timer.Stop()
+		}
+	}
+}
+
+// runOnce runs the job a single time, converting a panic into an error so the
+// loop can back off and retry.
+func (s *Scheduler) runOnce(j *job) (err error) {
+	var items int
+	s.mu.Lock()
+	j.status.Running = true
+	j.status.LastRun = time.Now()
+	name := j.status.Name
+	s.mu.Unlock()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+			slog.Error("Background job panicked",
+				slog.String("job", name),
+				slog.Any("panic", r),
+				slog.String("stack", string(debug.Stack())))
+		} else if err != nil && s.ctx.Err() == nil {
+			slog.Error("Background job failed", slog.String("job", name), slog.Any("err", err))
+		}
+
+		s.mu.Lock()
+		j.status.Running = false
+		if err != nil {
+			j.status.LastError = err.Error()
+			j.status.LastErrorAt = time.Now()
+		} else {
+			j.status.LastItems = items
+		}
+		s.mu.Unlock()
+	}()
+
+	items, err = j.run(s.ctx)
+	return err
+}
+
+func backoff(failures int) time.Duration {
+	delay := jobInitialBackoff
+	for i := 1; i < failures && delay < jobMaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, jobMaxBackoff)
+}
@@ -0,0 +1,310 @@
+package mgbot
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+const (
+	// VoiceXpInterval is how often members in voice are credited with voice
+	// time and XP.
+	VoiceXpInterval  = time.Minute
+	voiceXpPerMinute = 10
+)
+
+// MarkVoiceOnline records that the bot is connected to the gateway again. Time
+// sessions spent before then isn't credited, since the bot couldn't see
+// whether members stayed in voice while it was offline.
+func (b *MartinGarrixBot) MarkVoiceOnline(now time.Time) {
+	b.voiceMu.Lock()
+	defer b.voiceMu.Unlock()
+
+	b.voiceOnlineSince = now
+}
+
+// lockVoiceSession serialises changes to a member's voice session between the
+// voice state listener and the voice_xp job, and returns the unlock function.
+// Other members' sessions aren't held up.
+func (b *MartinGarrixBot) lockVoiceSession(guildID, userID snowflake.ID) func() {
+	key := [2]snowflake.ID{guildID, userID}
+
+	b.voiceMu.Lock()
+	lock, ok := b.voiceLocks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		b.voiceLocks[key] = lock
+	}
+	b.voiceMu.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
+func (b *MartinGarrixBot) onlineSince() time.Time {
+	b.voiceMu.Lock()
+	defer b.voiceMu.Unlock()
+
+	return b.voiceOnlineSince
+}
+
+// UpdateVoiceSession credits the member's running voice session and then
+// starts, moves or ends it to match their new voice state.
+func (b *MartinGarrixBot) UpdateVoiceSession(ctx context.Context, state discord.VoiceState) error {
+	defer b.lockVoiceSession(state.GuildID, state.UserID)()
+
+	now := time.Now().UTC()
+
+	session, err := b.Queries.GetVoiceSession(ctx, db.GetVoiceSessionParams{
+		UserID:  int64(state.UserID),
+		GuildID: int64(state.GuildID),
+	})
+	found := err == nil
+	if err != nil && !errors.Is(err, db.ErrRecordNotFound) {
+		return err
+	}
+
+	if found {
+		if err := b.creditVoiceSession(ctx, session, now); err != nil {
+			return err
+		}
+	}
+
+	if !b.earnsVoiceXp(state) {
+		if !found {
+			return nil
+		}
+		return b.Queries.DeleteVoiceSession(ctx, db.DeleteVoiceSessionParams{
+			UserID:  session.UserID,
+			GuildID: session.GuildID,
+		})
+	}
+
+	if found && session.ChannelID == int64(*state.ChannelID) {
+		return nil
+	}
+	return b.startVoiceSession(ctx, state, now)
+}
+
+// CreditVoiceSessions credits every running voice session, ends the ones whose
+// member is no longer earning voice XP and starts sessions for members already
+// in voice, like after a restart. It returns the number of sessions credited.
+func (b *MartinGarrixBot) CreditVoiceSessions(ctx context.Context) (int, error) {
+	sessions, err := b.Queries.GetVoiceSessions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	type sessionKey struct{ guildID, userID snowflake.ID }
+	running := make(map[sessionKey]bool, len(sessions))
+
+	credited := 0
+	for _, session := range sessions {
+		guildID, userID := snowflake.ID(session.GuildID), snowflake.ID(session.UserID)
+
+		// Until the guild arrives on the gateway its voice states aren't
+		// cached, so a missing state doesn't mean the member left
+		if _, ok := b.Client.Caches().Guild(guildID); !ok || b.Client.Caches().IsGuildUnavailable(guildID) {
+			continue
+		}
+
+		ok, err := b.creditRunningVoiceSession(ctx, guildID, userID)
+		if err != nil {
+			return credited, err
+		}
+		if ok {
+			running[sessionKey{guildID, userID}] = true
+			credited++
+		}
+	}
+
+	var states []discord.VoiceState
+	b.Client.Caches().GuildsForEach(func(guild discord.Guild) {
+		b.Client.Caches().VoiceStatesForEach(guild.ID, func(state discord.VoiceState) {
+			if !running[sessionKey{state.GuildID, state.UserID}] && b.earnsVoiceXp(state) {
+				states = append(states, state)
+			}
+		})
+	})
+	for _, state := range states {
+		if err := b.startMissingVoiceSession(ctx, state); err != nil {
+			return credited, err
+		}
+	}
+
+	return credited, nil
+}
+
+// creditRunningVoiceSession credits a member's session if they are still in
+// its channel earning voice XP and ends it otherwise, reporting whether it was
+// credited. The session is read again under the member's lock, since the
+// listener may have changed it since the job listed it.
+func (b *MartinGarrixBot) creditRunningVoiceSession(ctx context.Context, guildID, userID snowflake.ID) (bool, error) {
+	defer b.lockVoiceSession(guildID, userID)()
+
+	session, err := b.Queries.GetVoiceSession(ctx, db.GetVoiceSessionParams{
+		UserID:  int64(userID),
+		GuildID: int64(guildID),
+	})
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	state, ok := b.Client.Caches().VoiceState(guildID, userID)
+	if !ok || !b.earnsVoiceXp(state) || int64(*state.ChannelID) != session.ChannelID {
+		// The listener credits members as they leave, so whatever is left
+		// happened while the bot couldn't see it.
+		return false, b.Queries.DeleteVoiceSession(ctx, db.DeleteVoiceSessionParams{
+			UserID:  session.UserID,
+			GuildID: session.GuildID,
+		})
+	}
+
+	return true, b.creditVoiceSession(ctx, session, time.Now().UTC())
+}
+
+// startMissingVoiceSession starts a session for a member in voice unless the
+// listener already started one.
+func (b *MartinGarrixBot) startMissingVoiceSession(ctx context.Context, state discord.VoiceState) error {
+	defer b.lockVoiceSession(state.GuildID, state.UserID)()
+
+	_, err := b.Queries.GetVoiceSession(ctx, db.GetVoiceSessionParams{
+		UserID:  int64(state.UserID),
+		GuildID: int64(state.GuildID),
+	})
+	if err == nil {
+		return nil
+	} else if !errors.Is(err, db.ErrRecordNotFound) {
+		return err
+	}
+
+	return b.startVoiceSession(ctx, state, time.Now().UTC())
+}
+
+func (b *MartinGarrixBot) startVoiceSession(ctx context.Context, state discord.VoiceState, now time.Time) error {
+	return b.Queries.UpsertVoiceSession(ctx, db.UpsertVoiceSessionParams{
+		UserID:     int64(state.UserID),
+		GuildID:    int64(state.GuildID),
+		ChannelID:  int64(*state.ChannelID),
+		StartedAt:  pgtype.Timestamp{Time: now, Valid: true},
+		CreditedAt: pgtype.Timestamp{Time: now, Valid: true},
+	})
+}
+
+// creditVoiceSession adds the time since the session was last credited to the
+// member's voice time and gives them XP for it. Time from before the bot last
+// came online isn't credited. The caller must hold the member's session lock.
+func (b *MartinGarrixBot) creditVoiceSession(ctx context.Context, session db.VoiceSession, now time.Time) error {
+	from := session.CreditedAt.Time
+	if onlineSince := b.onlineSince(); onlineSince.After(from) {
+		from = onlineSince
+	}
+	elapsed := now.Sub(from)
+	if elapsed < time.Second {
+		return nil
+	}
+
+	guildID, userID := snowflake.ID(session.GuildID), snowflake.ID(session.UserID)
+
+	var roleIDs []snowflake.ID
+	if member, ok := b.Client.Caches().Member(guildID, userID); ok {
+		roleIDs = member.RoleIDs
+	}
+
+	multiplier, err := b.XpMultiplier(ctx, guildID, snowflake.ID(session.ChannelID), userID, roleIDs)
+	if err != nil {
+		slog.Error("Failed to get XP multiplier", slog.Any("err", err))
+		multiplier = 1
+	}
+	xp := int32(math.Round(elapsed.Minutes() * voiceXpPerMinute * multiplier))
+
+	tx, err := b.DB.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	q := b.Queries.WithTx(tx)
+
+	if err := q.EnsureUser(ctx, db.EnsureUserParams{ID: session.UserID, GuildID: session.GuildID}); err != nil {
+		return err
+	}
+
+	if err := q.AddVoiceTime(ctx, db.AddVoiceTimeParams{
+		UserID:  session.UserID,
+		GuildID: session.GuildID,
+		Seconds: int64(elapsed.Seconds()),
+	}); err != nil {
+		return err
+	}
+
+	if err := q.AddActivity(ctx, db.AddActivityParams{
+		UserID:       session.UserID,
+		GuildID:      session.GuildID,
+		Hour:         ActivityHour(now),
+		Xp:           xp,
+		VoiceSeconds: int64(elapsed.Seconds()),
+	}); err != nil {
+		return err
+	}
+
+	var totalXp pgtype.Int4
+	if xp > 0 {
+		totalXp, err = q.AddXp(ctx, db.AddXpParams{
+			ID:      session.UserID,
+			GuildID: session.GuildID,
+			TotalXp: pgtype.Int4{Int32: xp, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := q.SetVoiceSessionCreditedAt(ctx, db.SetVoiceSessionCreditedAtParams{
+		UserID:     session.UserID,
+		GuildID:    session.GuildID,
+		CreditedAt: pgtype.Timestamp{Time: now, Valid: true},
+	}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	if xp > 0 {
+		if newLevel := utils.GetUserLevel(totalXp.Int32); newLevel > utils.GetUserLevel(totalXp.Int32-xp) {
+			go b.HandleLevelUp(context.Background(), guildID, userID, newLevel)
+		}
+	}
+	return nil
+}
+
+// earnsVoiceXp reports whether a member in state should be earning voice XP.
+// Bots, muted or deafened members and members in the AFK channel don't.
+func (b *MartinGarrixBot) earnsVoiceXp(state discord.VoiceState) bool {
+	if state.ChannelID == nil || state.SelfMute || state.SelfDeaf || state.GuildMute || state.GuildDeaf {
+		return false
+	}
+
+	if guild, ok := b.Client.Caches().Guild(state.GuildID); ok && guild.AfkChannelID != nil && *guild.AfkChannelID == *state.ChannelID {
+		return false
+	}
+
+	if member, ok := b.Client.Caches().Member(state.GuildID, state.UserID); ok && member.User.Bot {
+		return false
+	}
+
+	return state.UserID != b.Client.ApplicationID()
+}
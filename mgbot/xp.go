@@ -0,0 +1,98 @@
+package mgbot
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+)
+
+// Kinds of target an XP override applies to.
+const (
+	XpOverrideChannel = "channel"
+	XpOverrideRole    = "role"
+)
+
+// XpMultiplier returns how much the XP a member earns in channelID is
+// multiplied by. It combines the guild's multiplier, the channel override, the
+// highest override of the member's roles, the biggest running XP event and the
+// member's XP boost from the shop. It returns 0 if the channel or any of the
+// member's roles gives no XP.
+func (b *MartinGarrixBot) XpMultiplier(ctx context.Context, guildID, channelID, userID snowflake.ID, roleIDs []snowflake.ID) (float64, error) {
+	now := pgtype.Timestamp{Time: time.Now().UTC(), Valid: true}
+
+	multiplier, err := b.Queries.GetXpMultiplier(ctx, int64(guildID))
+	if errors.Is(err, db.ErrRecordNotFound) {
+		multiplier = 1
+	} else if err != nil {
+		return 0, err
+	}
+
+	overrides, err := b.Queries.GetXpOverrides(ctx, int64(guildID))
+	if err != nil {
+		return 0, err
+	}
+
+	// Threads follow the rules of their channel unless they have their own
+	channelIDs := []snowflake.ID{channelID}
+	if channel, ok := b.Client.Caches().Channel(channelID); ok {
+		if thread, ok := channel.(discord.GuildThread); ok && thread.ParentID() != nil {
+			channelIDs = append(channelIDs, *thread.ParentID())
+		}
+	}
+
+	channelMatch, channelMultiplier := len(channelIDs), 1.0
+	roleMultiplier := 0.0
+	for _, override := range overrides {
+		targetID := snowflake.ID(override.TargetID)
+		switch override.TargetType {
+		case XpOverrideChannel:
+			if i := slices.Index(channelIDs, targetID); i != -1 && i < channelMatch {
+				channelMatch, channelMultiplier = i, override.Multiplier
+			}
+		case XpOverrideRole:
+			if !slices.Contains(roleIDs, targetID) {
+				continue
+			}
+			if override.Multiplier == 0 {
+				return 0, nil
+			}
+			roleMultiplier = max(roleMultiplier, override.Multiplier)
+		}
+	}
+
+	multiplier *= channelMultiplier
+	if roleMultiplier > 0 {
+		multiplier *= roleMultiplier
+	}
+	if multiplier == 0 {
+		return 0, nil
+	}
+
+	eventMultiplier, err := b.Queries.GetActiveXpEventMultiplier(ctx, db.GetActiveXpEventMultiplierParams{
+		GuildID:  int64(guildID),
+		StartsAt: now,
+	})
+	if err != nil {
+		return 0, err
+	}
+	multiplier *= eventMultiplier
+
+	boost, err := b.Queries.GetActiveXpBoost(ctx, db.GetActiveXpBoostParams{
+		GuildID:   int64(guildID),
+		UserID:    int64(userID),
+		ExpiresAt: now,
+	})
+	if err == nil {
+		multiplier *= boost.BoostMultiplier.Float64
+	} else if !errors.Is(err, db.ErrRecordNotFound) {
+		return 0, err
+	}
+
+	return multiplier, nil
+}
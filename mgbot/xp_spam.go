@@ -0,0 +1,208 @@
+package mgbot
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/disgoorg/snowflake/v2"
+	"github.com/jackc/pgx/v5/pgtype"
+	db "github.com/milindmadhukar/MartinGarrixBot/db/sqlc"
+	"github.com/milindmadhukar/MartinGarrixBot/utils"
+)
+
+// Reasons a message is denied XP by the anti-spam heuristics.
+const (
+	XpSpamTooShort  = "too_short"
+	XpSpamDuplicate = "duplicate"
+	XpSpamEmojiOnly = "emoji_only"
+)
+
+const (
+	// xpSpamMinLength is the fewest characters, not counting whitespace,
+	// mentions and emoji, a message needs to earn XP.
+	xpSpamMinLength = 5
+	// xpSpamDuplicateWindow is how long a member's messages are remembered to
+	// catch them being repeated.
+	xpSpamDuplicateWindow = 10 * time.Minute
+	// xpSpamHistorySize is how many recent messages are remembered per member.
+	xpSpamHistorySize = 5
+	// xpSpamFlagCooldown is the least time between two flags of a member, so
+	// spamming doesn't flood the review list.
+	xpSpamFlagCooldown = time.Minute
+	// XpSpamFlagPruneInterval is how often old flags are deleted.
+	XpSpamFlagPruneInterval = 24 * time.Hour
+	// XpSpamTrackerPruneInterval is how often members who have gone quiet are
+	// forgotten, so the tracker only holds recently active members.
+	XpSpamTrackerPruneInterval = xpSpamDuplicateWindow
+	// xpSpamFlagRetention is how long flags are kept for review.
+	xpSpamFlagRetention = 30 * 24 * time.Hour
+	// xpSpamFlagContentMaxLength is the longest content xp_spam_flags stores.
+	xpSpamFlagContentMaxLength = 2000
+)
+
+var (
+	customEmojiPattern = regexp.MustCompile(`<a?:\w+:\d+>`)
+	mentionPattern     = regexp.MustCompile(`<(@[!&]?|#)\d+>`)
+)
+
+type recentMessage struct {
+	content string
+	sentAt  time.Time
+}
+
+type xpSpamMember struct {
+	recent      []recentMessage
+	lastFlagged time.Time
+}
+
+// XpSpamTracker remembers members' recent messages to detect them farming XP
+// by repeating themselves.
+type XpSpamTracker struct {
+	mu      sync.Mutex
+	members map[[2]snowflake.ID]*xpSpamMember
+}
+
+func NewXpSpamTracker() *XpSpamTracker {
+	return &XpSpamTracker{
+		members: make(map[[2]snowflake.ID]*xpSpamMember),
+	}
+}
+
+// Check records a message and returns why it shouldn't earn XP, or "" if it
+// should. Messages without text, like attachments and stickers, are never
+// treated as spam.
+func (t *XpSpamTracker) Check(guildID, userID snowflake.ID, content string, now time.Time) string {
+	normalized := strings.Join(strings.Fields(strings.ToLower(content)), " ")
+	if normalized == "" {
+		return ""
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := [2]snowflake.ID{guildID, userID}
+	member, ok := t.members[key]
+	if !ok {
+		member = &xpSpamMember{}
+		t.members[key] = member
+	}
+
+	duplicate := false
+	recent := member.recent[:0]
+	for _, message := range member.recent {
+		if now.Sub(message.sentAt) > xpSpamDuplicateWindow {
+			continue
+		}
+		if message.content == normalized {
+			duplicate = true
+		}
+		recent = append(recent, message)
+	}
+	recent = append(recent, recentMessage{content: normalized, sentAt: now})
+	if len(recent) > xpSpamHistorySize {
+		recent = recent[len(recent)-xpSpamHistorySize:]
+	}
+	member.recent = recent
+
+	switch {
+	case duplicate:
+		return XpSpamDuplicate
+	case isEmojiOnly(content):
+		return XpSpamEmojiOnly
+	case textLength(content) < xpSpamMinLength:
+		return XpSpamTooShort
+	}
+	return ""
+}
+
+// shouldFlag reports whether a member denied XP should be flagged for review,
+// starting the flag cooldown if so.
+func (t *XpSpamTracker) shouldFlag(guildID, userID snowflake.ID, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	member, ok := t.members[[2]snowflake.ID{guildID, userID}]
+	if !ok || now.Sub(member.lastFlagged) < xpSpamFlagCooldown {
+		return false
+	}
+	member.lastFlagged = now
+	return true
+}
+
+// prune forgets members who haven't sent a message within the duplicate
+// window and returns how many were forgotten.
+func (t *XpSpamTracker) prune(now time.Time) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	pruned := 0
+	for key, member := range t.members {
+		last := member.recent[len(member.recent)-1].sentAt
+		if now.Sub(last) > xpSpamDuplicateWindow && now.Sub(member.lastFlagged) > xpSpamFlagCooldown {
+			delete(t.members, key)
+			pruned++
+		}
+	}
+	return pruned
+}
+
+func isEmojiOnly(content string) bool {
+	content = customEmojiPattern.ReplaceAllString(content, "")
+	for _, r := range content {
+		// Emoji are symbols, joined and modified by zero width joiners and
+		// variation selectors
+		if unicode.IsSpace(r) || unicode.Is(unicode.So, r) || unicode.Is(unicode.Sk, r) ||
+			r == '\u200d' || unicode.Is(unicode.Variation_Selector, r) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+func textLength(content string) int {
+	content = customEmojiPattern.ReplaceAllString(content, "")
+	content = mentionPattern.ReplaceAllString(content, "")
+
+	length := 0
+	for _, r := range content {
+		if !unicode.IsSpace(r) && !unicode.Is(unicode.So, r) {
+			length++
+		}
+	}
+	return length
+}
+
+// FlagXpSpam records a message denied XP for moderators to review, at most
+// once per member per flag cooldown.
+func (b *MartinGarrixBot) FlagXpSpam(ctx context.Context, guildID, channelID, messageID, userID snowflake.ID, reason, content string, now time.Time) error {
+	if !b.XpSpam.shouldFlag(guildID, userID, now) {
+		return nil
+	}
+
+	return b.Queries.CreateXpSpamFlag(ctx, db.CreateXpSpamFlagParams{
+		GuildID:   int64(guildID),
+		UserID:    int64(userID),
+		ChannelID: int64(channelID),
+		MessageID: int64(messageID),
+		Reason:    reason,
+		Content:   utils.CutString(content, xpSpamFlagContentMaxLength),
+		FlaggedAt: pgtype.Timestamp{Time: now, Valid: true},
+	})
+}
+
+// PruneXpSpamFlags deletes flags older than the review window.
+func (b *MartinGarrixBot) PruneXpSpamFlags(ctx context.Context) (int, error) {
+	cutoff := time.Now().UTC().Add(-xpSpamFlagRetention)
+	return 0, b.Queries.DeleteXpSpamFlagsBefore(ctx, pgtype.Timestamp{Time: cutoff, Valid: true})
+}
+
+// PruneXpSpamTracker forgets members who have gone quiet and returns how many
+// were forgotten.
+func (b *MartinGarrixBot) PruneXpSpamTracker(_ context.Context) (int, error) {
+	return b.XpSpam.prune(time.Now().UTC()), nil
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"image"
+	"sync"
+	"time"
+)
+
+const (
+	avatarCacheTTL      = time.Hour
+	avatarCacheSize     = 500
+	backgroundCacheTTL  = time.Hour
+	backgroundCacheSize = 50
+)
+
+// avatarCache keeps the avatars of rank and leaderboard cards. Avatar URLs
+// contain the avatar hash, so a member changing theirs gets a new entry.
+var avatarCache = NewImageCache(avatarCacheTTL, avatarCacheSize)
+
+// backgroundCache keeps the backgrounds of custom rank themes, which are
+// shared by every member using the theme.
+var backgroundCache = NewImageCache(backgroundCacheTTL, backgroundCacheSize)
+
+type cachedImage struct {
+	img       image.Image
+	expiresAt time.Time
+}
+
+// ImageCache is an in-memory cache of images fetched by URL.
+type ImageCache struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	size   int
+	images map[string]cachedImage
+}
+
+func NewImageCache(ttl time.Duration, size int) *ImageCache {
+	return &ImageCache{
+		ttl:    ttl,
+		size:   size,
+		images: make(map[string]cachedImage),
+	}
+}
+
+// Get returns the image at url, fetching it if it isn't cached or expired.
+func (c *ImageCache) Get(url string) (image.Image, error) {
+	c.mu.Lock()
+	cached, ok := c.images[url]
+	c.mu.Unlock()
+	if ok && time.Now().Before(cached.expiresAt) {
+		return cached.img, nil
+	}
+
+	img, err := FetchImage(url)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.images) >= c.size {
+		c.evict()
+	}
+	c.images[url] = cachedImage{img: img, expiresAt: time.Now().Add(c.ttl)}
+	return img, nil
+}
+
+// GetAll fetches the images at urls concurrently. Empty URLs and images that
+// fail to load are nil, with the first error returned.
+func (c *ImageCache) GetAll(urls []string) ([]image.Image, error) {
+	images := make([]image.Image, len(urls))
+	errs := make([]error, len(urls))
+
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		if url == "" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			images[i], errs[i] = c.Get(url)
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return images, err
+		}
+	}
+	return images, nil
+}
+
+// evict drops the expired images, or the one closest to expiring if none are.
+func (c *ImageCache) evict() {
+	now := time.Now()
+	oldest := ""
+	for url, cached := range c.images {
+		if now.After(cached.expiresAt) {
+			delete(c.images, url)
+		} else if oldest == "" || cached.expiresAt.Before(c.images[oldest].expiresAt) {
+			oldest = url
+		}
+	}
+	if len(c.images) >= c.size {
+		delete(c.images, oldest)
+	}
+}
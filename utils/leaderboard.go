@@ -0,0 +1,124 @@
+package utils
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"log/slog"
+	"strconv"
+)
+
+const (
+	leaderboardWidth        = 1000
+	leaderboardHeaderHeight = 110
+	leaderboardRowHeight    = 88
+	leaderboardAvatarSize   = 64
+	leaderboardBarWidth     = 505
+	leaderboardNameWidth    = 480
+)
+
+var (
+	leaderboardBackground = color.RGBA{30, 31, 34, 255}
+	leaderboardRow        = color.RGBA{43, 45, 49, 255}
+	leaderboardHighlight  = color.RGBA{64, 66, 92, 255}
+	leaderboardTrack      = color.RGBA{70, 72, 77, 255}
+	leaderboardBar        = color.RGBA{ColorSuccess >> 16 & 0xFF, ColorSuccess >> 8 & 0xFF, ColorSuccess & 0xFF, 255}
+	// Gold, silver and bronze for the top three
+	leaderboardPodium = []color.RGBA{{255, 196, 0, 255}, {192, 192, 192, 255}, {205, 127, 50, 255}}
+)
+
+// LeaderboardEntry is a row of a leaderboard card.
+type LeaderboardEntry struct {
+	Rank      int
+	Name      string
+	AvatarURL string
+	// Value is the member's score in the leaderboard's category.
+	Value   string
+	TotalXp int32
+	// Highlight marks the row of the member viewing the leaderboard.
+	Highlight bool
+}
+
+// LeaderboardPicture draws a leaderboard card with a row for each entry
+// showing the member's avatar, name, score, level and level progress.
+func LeaderboardPicture(title string, entries []LeaderboardEntry) (image.Image, error) {
+	height := leaderboardHeaderHeight + len(entries)*leaderboardRowHeight + 20
+	base := image.NewRGBA(image.Rect(0, 0, leaderboardWidth, height))
+	draw.Draw(base, base.Bounds(), image.NewUniform(leaderboardBackground), image.Point{0, 0}, draw.Src)
+
+	urls := make([]string, len(entries))
+	for i, entry := range entries {
+		urls[i] = entry.AvatarURL
+	}
+	// Missing avatars are left out rather than failing the whole card
+	avatars, err := avatarCache.GetAll(urls)
+	if err != nil {
+		slog.Error("Failed to get leaderboard avatars", slog.Any("err", err))
+	}
+
+	fontBytes, err := readFont()
+	if err != nil {
+		return nil, err
+	}
+
+	textDrawer, err := NewTextDrawer(base, fontBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := textDrawer.drawText(title, 40, 30, 44); err != nil {
+		return nil, err
+	}
+
+	nameFace, err := textDrawer.createFace(28)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, entry := range entries {
+		y := leaderboardHeaderHeight + i*leaderboardRowHeight
+
+		rowColour := leaderboardRow
+		if entry.Highlight {
+			rowColour = leaderboardHighlight
+		}
+		draw.Draw(base, image.Rect(20, y, leaderboardWidth-20, y+80), image.NewUniform(rowColour), image.Point{0, 0}, draw.Src)
+		if entry.Rank >= 1 && entry.Rank <= len(leaderboardPodium) {
+			draw.Draw(base, image.Rect(20, y, 28, y+80), image.NewUniform(leaderboardPodium[entry.Rank-1]), image.Point{0, 0}, draw.Src)
+		}
+
+		if err := textDrawer.drawText("#"+strconv.Itoa(entry.Rank), 40, y+20, 30); err != nil {
+			return nil, err
+		}
+
+		if avatars[i] != nil {
+			avatar := circleImage(avatars[i], leaderboardAvatarSize)
+			draw.Draw(base, avatar.Bounds().Add(image.Point{115, y + 8}), avatar, image.Point{0, 0}, draw.Over)
+		}
+
+		name := []rune(entry.Name)
+		for len(name) > 1 && measureString(nameFace, string(name)).Ceil() > leaderboardNameWidth {
+			name = name[:len(name)-1]
+		}
+		if len(name) < len([]rune(entry.Name)) {
+			name = append(name[:max(len(name)-3, 0)], []rune("...")...)
+		}
+		if err := textDrawer.drawText(string(name), 195, y+10, 28); err != nil {
+			return nil, err
+		}
+
+		lvlData := GetUserLevelData(entry.TotalXp)
+		percentage := float64(lvlData.CurrentXp) / float64(lvlData.XpForNextLvl)
+		draw.Draw(base, image.Rect(195, y+54, 195+leaderboardBarWidth, y+66), image.NewUniform(leaderboardTrack), image.Point{0, 0}, draw.Src)
+		draw.Draw(base, image.Rect(195, y+54, 195+int(leaderboardBarWidth*percentage), y+66), image.NewUniform(leaderboardBar), image.Point{0, 0}, draw.Src)
+
+		if err := textDrawer.drawTextRightAligned(entry.Value, leaderboardWidth-40, y+10, 30); err != nil {
+			return nil, err
+		}
+		if err := textDrawer.drawTextRightAligned("LEVEL "+strconv.Itoa(lvlData.Lvl), leaderboardWidth-40, y+48, 20); err != nil {
+			return nil, err
+		}
+	}
+
+	return base, nil
+}
@@ -1,10 +1,13 @@
 package utils
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"image"
 	"image/color"
 	"image/draw"
+	_ "image/jpeg"
 	"image/png"
 	"io"
 	"math"
@@ -12,6 +15,7 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/nfnt/resize"
 
@@ -63,12 +67,49 @@ var (
 	}
 )
 
+// closestColour returns the name of the colour in names nearest to c, used to
+// pick the template that goes best with a custom accent.
+func closestColour(c color.RGBA, names []string) string {
+	closest, minDist := names[0], math.MaxFloat64
+	for _, name := range names {
+		t := colors[name]
+		dR, dG, dB := float64(c.R)-float64(t.R), float64(c.G)-float64(t.G), float64(c.B)-float64(t.B)
+		if dist := dR*dR + dG*dG + dB*dB; dist < minDist {
+			closest, minDist = name, dist
+		}
+	}
+	return closest
+}
+
 // Helper function to measure text width
 func measureString(face font.Face, text string) fixed.Int26_6 {
 	return font.MeasureString(face, text)
 }
 
-func RankPicture(user db.GetUserLevelDataRow, memberName string, avatarUrl string) (image.Image, error) {
+// Progress bar styles of the rank card.
+const (
+	RankBarSolid    = "solid"
+	RankBarGradient = "gradient"
+	RankBarStriped  = "striped"
+)
+
+// RankTemplates are the names of the built-in rank card templates.
+var RankTemplates = []string{"red", "green", "yellow", "pink"}
+
+// RankTheme controls how a rank card looks. The zero value is a random
+// built-in template with a solid progress bar in its colour.
+type RankTheme struct {
+	// Template is one of RankTemplates, or empty for a random one.
+	Template string
+	// BackgroundURL replaces the template with a custom image when set.
+	BackgroundURL string
+	// Accent is the progress bar colour, the template colour when nil.
+	Accent   *color.RGBA
+	BarStyle string
+}
+
+// RankPicture draws the rank card of a member in the given theme.
+func RankPicture(user db.GetUserLevelDataRow, memberName string, avatarUrl string, theme RankTheme) (image.Image, error) {
 	lvlData := GetUserLevelData(user.TotalXp.Int32)
 	percentage := float64(lvlData.CurrentXp) / float64(lvlData.XpForNextLvl)
 
@@ -82,10 +123,17 @@ func RankPicture(user db.GetUserLevelDataRow, memberName string, avatarUrl strin
 		return nil, err
 	}
 
-	// TODO: Add more colours / templates
-	primaryColours := []string{"red", "green", "yellow", "pink"}
-	primaryColourIdx := rand.Intn(len(primaryColours))
-	primaryColour := primaryColours[primaryColourIdx]
+	primaryColour := theme.Template
+	if _, ok := colors[primaryColour]; !ok {
+		primaryColour = RankTemplates[rand.Intn(len(RankTemplates))]
+		if theme.Accent != nil {
+			primaryColour = closestColour(*theme.Accent, RankTemplates)
+		}
+	}
+	barColour := colors[primaryColour]
+	if theme.Accent != nil {
+		barColour = *theme.Accent
+	}
 
 	progressBar := image.NewRGBA(
 		image.Rect(
@@ -98,60 +146,58 @@ func RankPicture(user db.GetUserLevelDataRow, memberName string, avatarUrl strin
 
 	for x := 0; x < progressBar.Bounds().Dx(); x++ {
 		for y := 0; y < progressBar.Bounds().Dy(); y++ {
-			progressBar.Set(x, y, colors[primaryColour])
+			progressBar.Set(x, y, barPixel(theme.BarStyle, barColour, x, y))
 		}
 	}
 
-	draw.Draw(base.(draw.Image),
-		image.Rect(261, 194, 261+progressBar.Bounds().Dx(), 194+progressBar.Bounds().Dy()),
-		progressBar,
-		image.Point{0, 0},
-		draw.Over)
-
-	templateFile, err := os.Open("assets/" + primaryColour + ".png")
-	if err != nil {
-		return nil, err
-	}
+	barRect := image.Rect(261, 194, 261+progressBar.Bounds().Dx(), 194+progressBar.Bounds().Dy())
 
-	template, err := png.Decode(templateFile)
-	if err != nil {
-		return nil, err
-	}
+	if theme.BackgroundURL != "" {
+		background, err := backgroundCache.Get(theme.BackgroundURL)
+		if err != nil {
+			return nil, err
+		}
+		background = resize.Resize(uint(base.Bounds().Dx()), uint(base.Bounds().Dy()), background, resize.Lanczos3)
+		draw.Draw(base.(draw.Image), base.Bounds(), background, image.Point{0, 0}, draw.Src)
+
+		// Darken the card behind the text and give the progress bar a track,
+		// like the built-in templates do
+		draw.Draw(base.(draw.Image), image.Rect(25, 35, 975, 265),
+			image.NewUniform(color.RGBA{0, 0, 0, 140}), image.Point{0, 0}, draw.Over)
+		draw.Draw(base.(draw.Image), image.Rect(261, 194, 261+RANK_PICTURE_WIDTH, 234),
+			image.NewUniform(color.RGBA{255, 255, 255, 255}), image.Point{0, 0}, draw.Over)
+		draw.Draw(base.(draw.Image), barRect,
+			progressBar, image.Point{0, 0}, draw.Over)
+	} else {
+		draw.Draw(base.(draw.Image),
+			barRect,
+			progressBar,
+			image.Point{0, 0},
+			draw.Over)
+
+		templateFile, err := os.Open("assets/" + primaryColour + ".png")
+		if err != nil {
+			return nil, err
+		}
 
-	draw.Draw(base.(draw.Image),
-		template.Bounds(),
-		template,
-		image.Point{0, 0},
-		draw.Over)
+		template, err := png.Decode(templateFile)
+		if err != nil {
+			return nil, err
+		}
 
-	pfp, err := http.Get(avatarUrl)
-	if err != nil {
-		return nil, err
+		draw.Draw(base.(draw.Image),
+			template.Bounds(),
+			template,
+			image.Point{0, 0},
+			draw.Over)
 	}
 
-	avatar, err := png.Decode(pfp.Body)
+	avatar, err := avatarCache.Get(avatarUrl)
 	if err != nil {
 		return nil, err
 	}
 
-	resizedAvatar := resize.Resize(173, 173, avatar, resize.Lanczos3)
-
-	circleAvatar := image.NewRGBA(image.Rect(0, 0, 173, 173))
-
-	// PERF: A very bad vay of masking
-	for x := 0; x < 173; x++ {
-		for y := 0; y < 173; y++ {
-			dx := float64(x - 173/2)
-			dy := float64(y - 173/2)
-			d := math.Sqrt(dx*dx + dy*dy)
-
-			if d <= float64(173/2) {
-				circleAvatar.Set(x, y, resizedAvatar.At(x, y))
-			} else {
-				circleAvatar.Set(x, y, color.RGBA{0, 0, 0, 0})
-			}
-		}
-	}
+	circleAvatar := circleImage(avatar, 173)
 
 	draw.Draw(base.(draw.Image),
 		image.Rect(43, 63, 43+circleAvatar.Bounds().Dx(), 63+circleAvatar.Bounds().Dy()),
@@ -159,13 +205,7 @@ func RankPicture(user db.GetUserLevelDataRow, memberName string, avatarUrl strin
 		image.Point{0, 0},
 		draw.Over)
 
-	fontFile, err := os.Open("assets/font.ttf")
-	if err != nil {
-		return nil, err
-	}
-	defer fontFile.Close()
-
-	fontBytes, err := io.ReadAll(fontFile)
+	fontBytes, err := readFont()
 	if err != nil {
 		return nil, err
 	}
@@ -222,3 +262,100 @@ func RankPicture(user db.GetUserLevelDataRow, memberName string, avatarUrl strin
 
 	return base, nil
 }
+
+// circleImage resizes img to a size by size square and masks it to a circle.
+func circleImage(img image.Image, size int) *image.RGBA {
+	resized := resize.Resize(uint(size), uint(size), img, resize.Lanczos3)
+
+	circle := image.NewRGBA(image.Rect(0, 0, size, size))
+
+	// PERF: A very bad vay of masking
+	for x := 0; x < size; x++ {
+		for y := 0; y < size; y++ {
+			dx := float64(x - size/2)
+			dy := float64(y - size/2)
+			d := math.Sqrt(dx*dx + dy*dy)
+
+			if d <= float64(size/2) {
+				circle.Set(x, y, resized.At(x, y))
+			} else {
+				circle.Set(x, y, color.RGBA{0, 0, 0, 0})
+			}
+		}
+	}
+	return circle
+}
+
+func readFont() ([]byte, error) {
+	fontFile, err := os.Open("assets/font.ttf")
+	if err != nil {
+		return nil, err
+	}
+	defer fontFile.Close()
+
+	return io.ReadAll(fontFile)
+}
+
+// barPixel returns the colour of the progress bar at x, y for a bar style.
+func barPixel(style string, c color.RGBA, x, y int) color.RGBA {
+	switch style {
+	case RankBarGradient:
+		// Fade from a darker shade to the colour along the bar
+		t := 0.5 + 0.5*float64(x)/RANK_PICTURE_WIDTH
+		return color.RGBA{uint8(float64(c.R) * t), uint8(float64(c.G) * t), uint8(float64(c.B) * t), c.A}
+	case RankBarStriped:
+		if (x+y)/12%2 == 1 {
+			return color.RGBA{c.R / 4 * 3, c.G / 4 * 3, c.B / 4 * 3, c.A}
+		}
+	}
+	return c
+}
+
+// imageClient downloads avatars and backgrounds, giving up on hosts that are
+// too slow to answer before the interaction runs out.
+var imageClient = &http.Client{Timeout: 10 * time.Second}
+
+// Limits on images fetched by FetchImage, so a link can't make the bot
+// download or decode something huge.
+const (
+	ImageMaxBytes = 8 << 20
+	// ImageMaxSide bounds the area of an image, not each side on its own
+	ImageMaxSide = 4096
+)
+
+// ErrImageTooLarge is returned by FetchImage for images over ImageMaxBytes or
+// with more pixels than an ImageMaxSide square.
+var ErrImageTooLarge = errors.New("image too large")
+
+// FetchImage downloads and decodes a PNG or JPEG image.
+func FetchImage(url string) (image.Image, error) {
+	resp, err := imageClient.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch image: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, ImageMaxBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > ImageMaxBytes {
+		return nil, ErrImageTooLarge
+	}
+
+	// Check the dimensions in the header before decoding allocates for them
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if int64(config.Width)*int64(config.Height) > ImageMaxSide*ImageMaxSide {
+		return nil, ErrImageTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	return img, err
+}
@@ -22,7 +22,12 @@ func FormatModlogEntry(log db.Modlog, index int) string {
 		timeStr = fmt.Sprintf("<t:%d:F>", log.Time.Time.Unix())
 	}
 
-	entry := fmt.Sprintf("**%d.** %s | Case #%d\n", index, log.LogType, log.ID)
+	logType := log.LogType
+	if log.Voided {
+		logType = fmt.Sprintf("~~%s~~ (voided)", log.LogType)
+	}
+
+	entry := fmt.Sprintf("**%d.** %s | Case #%d\n", index, logType, log.CaseNumber)
 	entry += fmt.Sprintf("• Moderator: <@%d>\n", log.ModeratorID)
 	entry += fmt.Sprintf("• Reason: %s\n", reason)
 	entry += fmt.Sprintf("• Time: %s", timeStr)